@@ -0,0 +1,136 @@
+package plex
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// GetAgents lists the metadata agents installed on the server that support mediaType,
+// e.g. MediaTypeMovie. An empty mediaType lists every agent.
+func (p *Plex) GetAgents(mediaType MediaType) (Agents, error) {
+	query := p.ServerURL() + "/system/agents"
+
+	if mediaType != "" {
+		id := mediaType.ID()
+
+		if id == 0 {
+			return Agents{}, fmt.Errorf("unknown media type %q", mediaType)
+		}
+
+		query += "?mediaType=" + strconv.Itoa(id)
+	}
+
+	return getContainer[AgentContainer](p, query)
+}
+
+// Has reports whether an agent with identifier, e.g. "tv.plex.agents.movie", is installed
+func (c AgentContainer) Has(identifier string) bool {
+	for _, agent := range c.Agent {
+		if agent.Identifier == identifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetScanners lists the library scanners installed on the server for a library type,
+// e.g. MediaTypeMovie, MediaTypeShow, MediaTypeArtist or MediaTypePhoto
+func (p *Plex) GetScanners(libraryType MediaType) (Scanners, error) {
+	id := libraryType.ID()
+
+	if id == 0 {
+		return Scanners{}, fmt.Errorf("unknown media type %q", libraryType)
+	}
+
+	query := fmt.Sprintf("%s/library/scanners?type=%d", p.ServerURL(), id)
+
+	return getContainer[ScannerContainer](p, query)
+}
+
+// Has reports whether a scanner named name, e.g. "Plex Movie", is installed
+func (c ScannerContainer) Has(name string) bool {
+	for _, scanner := range c.Scanner {
+		if scanner.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LibraryParamsError is returned when library parameters are missing or not supported by the server
+type LibraryParamsError struct {
+	// Field is the parameter at fault, e.g. "language"
+	Field string
+	// Value is the rejected value, empty when the field is missing
+	Value  string
+	Reason string
+}
+
+func (e *LibraryParamsError) Error() string {
+	if e.Value == "" {
+		return e.Field + " " + e.Reason
+	}
+
+	return fmt.Sprintf("%s %q %s", e.Field, e.Value, e.Reason)
+}
+
+// SupportsLanguage reports whether the agent can fetch metadata in the language with code
+func (a Agent) SupportsLanguage(code string) bool {
+	for _, language := range a.Language {
+		if language.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetAgentLanguages lists the languages an agent supports for a library type
+func (p *Plex) GetAgentLanguages(identifier string, libraryType MediaType) ([]AgentLanguage, error) {
+	agents, err := p.GetAgents(libraryType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, agent := range agents.MediaContainer.Agent {
+		if agent.Identifier == identifier {
+			return agent.Language, nil
+		}
+	}
+
+	return nil, &LibraryParamsError{Field: "agent", Value: identifier, Reason: "is not installed"}
+}
+
+// ValidateLibraryParams checks CreateLibraryParams against the agents installed on the
+// server. A *LibraryParamsError describes the first unsupported field.
+func (p *Plex) ValidateLibraryParams(params CreateLibraryParams) error {
+	libraryType, err := ParseMediaType(params.LibraryType)
+
+	if err != nil {
+		return &LibraryParamsError{Field: "libraryType", Value: params.LibraryType, Reason: "is not a media type"}
+	}
+
+	languages, err := p.GetAgentLanguages(params.Agent, libraryType)
+
+	if err != nil {
+		return err
+	}
+
+	language := params.Language
+
+	// CreateLibrary defaults to english
+	if language == "" {
+		language = "en"
+	}
+
+	for _, supported := range languages {
+		if supported.Code == language {
+			return nil
+		}
+	}
+
+	return &LibraryParamsError{Field: "language", Value: language, Reason: "is not supported by " + params.Agent}
+}
@@ -0,0 +1,131 @@
+package plex
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPlex_GetAgents(t *testing.T) {
+	var mediaType string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/system/agents" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		mediaType = r.URL.Query().Get("mediaType")
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":2,"Agent":[{"identifier":"tv.plex.agents.movie","name":"Plex Movie","primary":true},{"identifier":"com.plexapp.agents.none","name":"Personal Media"}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	agents, err := p.GetAgents(MediaTypeMovie)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mediaType != "1" {
+		t.Errorf("expected mediaType=1, got %q", mediaType)
+	}
+
+	if !agents.MediaContainer.Has("tv.plex.agents.movie") || agents.MediaContainer.Has("tv.plex.agents.series") {
+		t.Errorf("unexpected agents: %+v", agents.MediaContainer.Agent)
+	}
+
+	if _, err := p.GetAgents(MediaType("cartoon")); err == nil {
+		t.Error("expected error for unknown media type")
+	}
+}
+
+func TestPlex_GetScanners(t *testing.T) {
+	var libraryType string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library/scanners" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		libraryType = r.URL.Query().Get("type")
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":2,"Scanner":[{"name":"Plex TV Series"},{"name":"Plex Series Scanner"}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	scanners, err := p.GetScanners(MediaTypeShow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if libraryType != "2" {
+		t.Errorf("expected type=2, got %q", libraryType)
+	}
+
+	if !scanners.MediaContainer.Has("Plex Series Scanner") || scanners.MediaContainer.Has("Plex Movie") {
+		t.Errorf("unexpected scanners: %+v", scanners.MediaContainer.Scanner)
+	}
+
+	if _, err := p.GetScanners(""); err == nil {
+		t.Error("expected error for empty library type")
+	}
+}
+
+func TestPlex_ValidateLibraryParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Agent":[{"identifier":"tv.plex.agents.movie","Language":[{"code":"en-US","title":"English (US)"},{"code":"de-DE","title":"German"}]}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	params := CreateLibraryParams{Name: "Movies", Location: "/movies", LibraryType: "movie", Agent: "tv.plex.agents.movie", Scanner: "Plex Movie", Language: "de-DE"}
+
+	if err := p.ValidateLibraryParams(params); err != nil {
+		t.Errorf("expected params to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(params *CreateLibraryParams)
+		field string
+	}{
+		{"unsupported language", func(params *CreateLibraryParams) { params.Language = "fr-FR" }, "language"},
+		{"default language", func(params *CreateLibraryParams) { params.Language = "" }, "language"},
+		{"missing agent", func(params *CreateLibraryParams) { params.Agent = "com.plexapp.agents.imdb" }, "agent"},
+		{"unknown type", func(params *CreateLibraryParams) { params.LibraryType = "cartoon" }, "libraryType"},
+	}
+
+	for _, test := range tests {
+		invalid := params
+		test.edit(&invalid)
+
+		err := p.ValidateLibraryParams(invalid)
+
+		var paramsErr *LibraryParamsError
+		if !errors.As(err, &paramsErr) {
+			t.Errorf("%s: expected *LibraryParamsError, got %v", test.name, err)
+			continue
+		}
+
+		if paramsErr.Field != test.field {
+			t.Errorf("%s: expected field %s, got %s", test.name, test.field, paramsErr.Field)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package plex
+
+import "fmt"
+
+// BulkDeleteOptions guards BulkDelete
+type BulkDeleteOptions struct {
+	// DryRun only lists the matching items, nothing is deleted
+	DryRun bool
+	// Confirm is called for each matching item before it is deleted, items for which it
+	// returns false are skipped. It is required unless DryRun is set.
+	Confirm func(item Metadata) bool
+	// Type deletes items of a type other than the section's default, e.g. MediaTypeEpisode
+	Type     MediaType
+	PageSize int // defaults to 100
+}
+
+// BulkDeleteResult lists the items BulkDelete matched and what happened to them
+type BulkDeleteResult struct {
+	Matched []Metadata
+	Deleted []Metadata
+	Skipped []Metadata
+}
+
+// BulkDelete deletes the items of a section matching filter, with their files. Every matching
+// item is listed before anything is deleted. Run it with DryRun first to review the matches.
+// Deletion stops at the first failure, the result then holds the items deleted so far.
+func (p *Plex) BulkDelete(sectionKey string, filter *LibraryFilter, opts BulkDeleteOptions) (BulkDeleteResult, error) {
+	if sectionKey == "" {
+		return BulkDeleteResult{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	if !filter.narrows() {
+		return BulkDeleteResult{}, fmt.Errorf(ErrorCommon, "a filter is required, refusing to delete a whole section")
+	}
+
+	if !opts.DryRun && opts.Confirm == nil {
+		return BulkDeleteResult{}, fmt.Errorf(ErrorCommon, "a confirm callback is required unless running dry")
+	}
+
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultExportPageSize
+	}
+
+	query := NewLibraryFilter()
+
+	if opts.Type != "" {
+		query.Type(opts.Type)
+	}
+
+	query.params = append(query.params, filter.params...)
+
+	var result BulkDeleteResult
+
+	err := p.walkSection(sectionKey, query, opts.PageSize, func(items []Metadata, done, total int) error {
+		result.Matched = append(result.Matched, items...)
+
+		return nil
+	})
+
+	if err != nil || opts.DryRun {
+		return result, err
+	}
+
+	for _, item := range result.Matched {
+		if !opts.Confirm(item) {
+			result.Skipped = append(result.Skipped, item)
+			continue
+		}
+
+		if err := p.DeleteMediaByID(item.RatingKey); err != nil {
+			return result, fmt.Errorf("deleting %s (%s): %w", item.Title, item.RatingKey, err)
+		}
+
+		result.Deleted = append(result.Deleted, item)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPlex_BulkDelete(t *testing.T) {
+	var deleted []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			key := strings.TrimPrefix(r.URL.Path, "/library/metadata/")
+			if key == "3" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			deleted = append(deleted, key)
+			return
+		}
+
+		if r.URL.Query().Get("year<<") != "1990" {
+			t.Errorf("expected the filter to be applied, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"totalSize":3,"Metadata":[{"ratingKey":"1","title":"Thief"},{"ratingKey":"2","title":"Manhunter"},{"ratingKey":"3","title":"The Keep"}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	filter := NewLibraryFilter().Set("year<<", "1990")
+
+	result, err := p.BulkDelete("1", filter, BulkDeleteOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("BulkDelete() dry run error = %v", err)
+	}
+
+	if len(result.Matched) != 3 || len(deleted) != 0 {
+		t.Fatalf("dry run matched %d items and deleted %v", len(result.Matched), deleted)
+	}
+
+	result, err = p.BulkDelete("1", filter, BulkDeleteOptions{Confirm: func(item Metadata) bool { return item.Title != "Manhunter" }})
+	if err == nil {
+		t.Fatal("expected the failed delete to be reported")
+	}
+
+	if len(result.Deleted) != 1 || len(result.Skipped) != 1 || len(deleted) != 1 || deleted[0] != "1" {
+		t.Errorf("unexpected result %+v, deleted %v", result, deleted)
+	}
+
+	guards := []struct {
+		name   string
+		filter *LibraryFilter
+		opts   BulkDeleteOptions
+	}{
+		{"no filter", nil, BulkDeleteOptions{DryRun: true}},
+		{"empty filter", NewLibraryFilter(), BulkDeleteOptions{DryRun: true}},
+		{"sort only", NewLibraryFilter().SortAsc("title"), BulkDeleteOptions{DryRun: true}},
+		{"guids and type only", NewLibraryFilter().Type(MediaTypeEpisode).Set("includeGuids", "1"), BulkDeleteOptions{DryRun: true}},
+		{"empty group", NewLibraryFilter().Group(func(g *LibraryFilter) { g.SortDesc("addedAt") }), BulkDeleteOptions{DryRun: true}},
+		{"no confirm", filter, BulkDeleteOptions{}},
+	}
+
+	for _, guard := range guards {
+		if _, err := p.BulkDelete("1", guard.filter, guard.opts); err == nil {
+			t.Errorf("%s: expected an error", guard.name)
+		}
+	}
+}
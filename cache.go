@@ -0,0 +1,272 @@
+package plex
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCachedEndpoints are the paths cached by WithCache when no endpoints are given.
+// They back GetLibraries, GetLibraryContent, GetMetadata and GetMetadataChildren.
+var DefaultCachedEndpoints = []string{"/library/sections", "/library/metadata/"}
+
+// WithCache keeps successful GET responses in memory for ttl. Only requests whose path
+// starts with one of endpoints are cached, DefaultCachedEndpoints is used if none are given.
+// Any successful PUT, POST or DELETE made by the client clears the cache, use
+// InvalidateCache to drop entries after changes made elsewhere. While the client is
+// subscribed to notifications, the items and sections they report as changed are dropped.
+// The WithBeforeRequest and WithAfterResponse hooks run for cached responses as well.
+func WithCache(ttl time.Duration, endpoints ...string) Option {
+	return func(p *Plex) {
+		if ttl <= 0 {
+			p.cache = nil
+			return
+		}
+
+		if len(endpoints) == 0 {
+			endpoints = DefaultCachedEndpoints
+		}
+
+		p.cache = newResponseCache(ttl, endpoints)
+	}
+}
+
+// InvalidateCache removes cached responses whose path starts with one of prefixes,
+// e.g. "/library/metadata/123". Calling it without prefixes clears the whole cache.
+// While subscribed to notifications, the entries of items and sections the server
+// reports as changed are removed automatically.
+func (p *Plex) InvalidateCache(prefixes ...string) {
+	if p.cache == nil {
+		return
+	}
+
+	p.cache.invalidate(prefixes...)
+}
+
+// responseCache stores response bodies keyed by accept header and url
+type responseCache struct {
+	ttl       time.Duration
+	endpoints []string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	path       string
+	status     string
+	statusCode int
+	header     http.Header
+	body       []byte
+	expires    time.Time
+}
+
+func newResponseCache(ttl time.Duration, endpoints []string) *responseCache {
+	return &responseCache{
+		ttl:       ttl,
+		endpoints: endpoints,
+		entries:   map[string]cacheEntry{},
+		now:       time.Now,
+	}
+}
+
+func cacheKey(req *http.Request) string {
+	// the token is part of the key so users switched by a TokenProvider never share responses
+	return req.Header.Get("X-Plex-Token") + " " + req.Header.Get("Accept") + " " + req.URL.String()
+}
+
+// cacheable reports whether req is a GET request for one of the cached endpoints. Artist and
+// library stations are never cached, plex generates a new selection of tracks for every request.
+func (c *responseCache) cacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet || strings.Contains(req.URL.Path, "/station/") || strings.Contains(req.URL.Path, "/stations/") {
+		return false
+	}
+
+	for _, endpoint := range c.endpoints {
+		if strings.HasPrefix(req.URL.Path, endpoint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookup returns a copy of the cached response for req, if it has not expired
+func (c *responseCache) lookup(req *http.Request) (*http.Response, bool) {
+	key := cacheKey(req)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	if c.now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.response(req), true
+}
+
+// response builds a new response for req from the cached entry
+func (e cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        e.status,
+		StatusCode:    e.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// readCacheable reads the body of a successful json or xml response and replaces it
+// so the caller can still read it. ok is false for responses that should not be cached.
+func readCacheable(resp *http.Response) (body []byte, ok bool, err error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+
+	if !strings.Contains(contentType, "json") && !strings.Contains(contentType, "xml") {
+		return nil, false, nil
+	}
+
+	body, err = io.ReadAll(resp.Body)
+	safeClose(resp.Body)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, true, nil
+}
+
+// store caches successful json and xml responses. The body of resp is replaced so the
+// caller can still read it.
+func (c *responseCache) store(req *http.Request, resp *http.Response) error {
+	body, ok, err := readCacheable(resp)
+
+	if !ok {
+		return err
+	}
+
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// drop expired entries so responses that are never requested again don't pile up
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.entries[cacheKey(req)] = cacheEntry{
+		path:       req.URL.Path,
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       body,
+		expires:    now.Add(c.ttl),
+	}
+
+	return nil
+}
+
+func (c *responseCache) invalidate(prefixes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(prefixes) == 0 {
+		c.entries = map[string]cacheEntry{}
+		return
+	}
+
+	for key, entry := range c.entries {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(entry.path, prefix) {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// invalidateNotification removes the responses made stale by the library changes
+// reported in n: items whose processing finished or that were deleted, with their
+// parents and sections, and all library responses once a library activity ends
+func (c *responseCache) invalidateNotification(n NotificationContainer) {
+	var paths []string
+
+	for _, entry := range n.TimelineEntry {
+		if entry.State != TimelineStateFinished && entry.State != TimelineStateDeleted {
+			continue
+		}
+
+		for _, id := range []int64{entry.ItemID, entry.ParentItemID, entry.RootItemID} {
+			if id > 0 {
+				paths = append(paths, "/library/metadata/"+strconv.FormatInt(id, 10))
+			}
+		}
+
+		if entry.SectionID > 0 {
+			paths = append(paths, "/library/sections/"+strconv.FormatInt(entry.SectionID, 10))
+		}
+	}
+
+	for _, activity := range n.ActivityNotification {
+		if activity.Event != "ended" || !strings.HasPrefix(activity.Activity.Type, "library.") {
+			continue
+		}
+
+		if section := activity.Activity.Context.LibrarySectionID; section > 0 {
+			paths = append(paths, "/library/sections/"+strconv.FormatInt(section.Int64(), 10))
+		}
+
+		// a scan or refresh can change any item, the changed items are not listed
+		paths = append(paths, "/library/metadata/")
+	}
+
+	if len(paths) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		for _, path := range paths {
+			if within(entry.path, path) {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// within reports whether path is dir or below it, e.g. "/library/metadata/1/children"
+// is within "/library/metadata/1" but "/library/metadata/12" is not
+func within(path, dir string) bool {
+	if !strings.HasPrefix(path, dir) {
+		return false
+	}
+
+	return len(path) == len(dir) || strings.HasSuffix(dir, "/") || path[len(dir)] == '/'
+}
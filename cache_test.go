@@ -0,0 +1,225 @@
+package plex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCacheTestServer(t *testing.T, ttl time.Duration) (*Plex, *int32) {
+	t.Helper()
+
+	var hits int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+
+		w.Header().Set("Content-Type", applicationJson)
+
+		switch r.URL.Path {
+		case "/library/sections":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Directory":[{"key":"1","title":"Movies"}]}}`))
+		case "/library/metadata/1":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Metadata":[{"ratingKey":"1","title":"Movie"}]}}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := New(server.URL, "token", WithCache(ttl))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	return p, &hits
+}
+
+func TestWithCache(t *testing.T) {
+	p, hits := newCacheTestServer(t, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		libraries, err := p.GetLibraries()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(libraries.MediaContainer.Directory) != 1 || libraries.MediaContainer.Directory[0].Title != "Movies" {
+			t.Fatalf("unexpected libraries: %+v", libraries.MediaContainer.Directory)
+		}
+	}
+
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("expected 1 request to the server, got %d", got)
+	}
+
+	if _, err := p.GetMetadata("1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := p.GetMetadata("1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected 2 requests to the server, got %d", got)
+	}
+
+	p.InvalidateCache("/library/metadata/1")
+
+	if _, err := p.GetMetadata("1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := atomic.LoadInt32(hits); got != 3 {
+		t.Errorf("expected only the invalidated entry to be requested again, got %d requests", got)
+	}
+}
+
+// Test the request hooks see the requests answered from the cache
+func TestWithCacheHooks(t *testing.T) {
+	p, hits := newCacheTestServer(t, time.Minute)
+
+	var before, after int
+
+	p.BeforeRequest = append(p.BeforeRequest, func(req *http.Request) { before++ })
+	p.AfterResponse = append(p.AfterResponse, func(resp *http.Response, err error) {
+		if err == nil && resp.StatusCode == http.StatusOK {
+			after++
+		}
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.GetLibraries(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if before != 3 || after != 3 || atomic.LoadInt32(hits) != 1 {
+		t.Errorf("expected the hooks to run for 3 requests and 1 server hit, got %d, %d and %d", before, after, atomic.LoadInt32(hits))
+	}
+}
+
+// Test stations are not cached, as plex returns new tracks for each request
+func TestCacheableStations(t *testing.T) {
+	p, _ := newCacheTestServer(t, time.Minute)
+
+	for path, want := range map[string]bool{
+		"/library/metadata/10":             true,
+		"/library/metadata/10/station/abc": false,
+		"/library/sections/3/station/def":  false,
+		"/library/sections/3/stations/1":   false,
+	} {
+		if got := p.cache.cacheable(httptest.NewRequest(http.MethodGet, path, nil)); got != want {
+			t.Errorf("cacheable(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestWithCacheExpiry(t *testing.T) {
+	p, hits := newCacheTestServer(t, time.Minute)
+
+	now := time.Now()
+	p.cache.now = func() time.Time { return now }
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected expired entry to be requested again, got %d requests", got)
+	}
+}
+
+func TestWithCacheClearedByWrites(t *testing.T) {
+	p, hits := newCacheTestServer(t, time.Minute)
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := p.put(p.URL+"/library/sections/1", nil, p.Headers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	safeClose(resp.Body)
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := atomic.LoadInt32(hits); got != 3 {
+		t.Errorf("expected the write to clear the cache, got %d requests", got)
+	}
+}
+
+func TestCacheInvalidateNotification(t *testing.T) {
+	notifications := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{
+			name:    "processing item",
+			payload: `{"type":"timeline","TimelineEntry":[{"itemID":"1","sectionID":"1","state":"4"}]}`,
+			want:    []string{"/library/metadata/1", "/library/metadata/1/children", "/library/metadata/12", "/library/sections", "/library/sections/1/all", "/library/sections/2/all"},
+		},
+		{
+			name:    "finished item",
+			payload: `{"type":"timeline","TimelineEntry":[{"itemID":"3","parentItemID":"1","sectionID":"1","state":"5"}]}`,
+			want:    []string{"/library/metadata/12", "/library/sections", "/library/sections/2/all"},
+		},
+		{
+			name:    "ended scan",
+			payload: `{"type":"activity","ActivityNotification":[{"event":"ended","Activity":{"type":"library.update.section","userID":"1","Context":{"librarySectionID":"2"}}}]}`,
+			want:    []string{"/library/sections", "/library/sections/1/all"},
+		},
+		{
+			name:    "ended transcode",
+			payload: `{"type":"activity","ActivityNotification":[{"event":"ended","Activity":{"type":"media.generate.bif","userID":"1"}}]}`,
+			want:    []string{"/library/metadata/1", "/library/metadata/1/children", "/library/metadata/12", "/library/sections", "/library/sections/1/all", "/library/sections/2/all"},
+		},
+	}
+
+	for _, tt := range notifications {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newResponseCache(time.Minute, DefaultCachedEndpoints)
+
+			for _, path := range []string{"/library/metadata/1", "/library/metadata/1/children", "/library/metadata/12", "/library/sections", "/library/sections/1/all", "/library/sections/2/all"} {
+				c.entries[path] = cacheEntry{path: path}
+			}
+
+			var notif WebsocketNotification
+			if err := json.Unmarshal([]byte(`{"NotificationContainer":`+tt.payload+`}`), &notif); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			c.invalidateNotification(notif.NotificationContainer)
+
+			var got []string
+			for path := range c.entries {
+				got = append(got, path)
+			}
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("remaining entries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
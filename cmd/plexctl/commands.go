@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -853,7 +854,7 @@ func getAccountInfo(c *cli.Context) error {
 		return cli.NewExitError(err, 1)
 	}
 
-	// fmt.Println(account.Subscription.Features, account.Roles.Roles)
+	// fmt.Println(account.Subscription.Features, account.Roles)
 	// fmt.Println(account.Entitlements)
 
 	fmt.Printf("%+v\n", account)
@@ -1028,3 +1029,61 @@ func deleteMedia(c *cli.Context) error {
 
 	return nil
 }
+
+func killSession(c *cli.Context) error {
+	db, err := startDB()
+
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	defer db.Close()
+
+	plexConn, err := initPlex(db, true, true)
+
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sessionID := c.Args().First()
+
+	if sessionID == "" {
+		return cli.NewExitError("Missing required session id, use command 'sessions' to find it", 1)
+	}
+
+	if err := plexConn.TerminateSession(sessionID, c.String("reason")); err != nil {
+		return cli.NewExitError(fmt.Sprintf("failed to terminate session: %v", err), 1)
+	}
+
+	fmt.Println("success!")
+
+	return nil
+}
+
+func listenWebhooks(c *cli.Context) error {
+	addr := c.String("addr")
+
+	wh := plex.NewWebhook()
+
+	wh.OnAny(func(w plex.Webhook) {
+		title := w.Metadata.Title
+
+		if w.Metadata.GrandparentTitle != "" {
+			title = w.Metadata.GrandparentTitle + " - " + title
+		}
+
+		fmt.Printf("%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), w.Event, w.Account.Title, title)
+	})
+
+	wh.OnError(func(r *http.Request, err error) {
+		fmt.Printf("failed to handle webhook from %s: %v\n", r.RemoteAddr, err)
+	})
+
+	fmt.Printf("listening for webhooks on %s, add http://<this host>%s as a webhook with the 'webhooks --add' command\n", addr, addr)
+
+	if err := http.ListenAndServe(addr, wh.HTTPHandler()); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	return nil
+}
@@ -36,7 +36,7 @@ func unserializeServer(serializedServer []byte) (server, error) {
 func main() {
 	app := cli.NewApp()
 
-	app.Name = "plex-cli"
+	app.Name = "plexctl"
 	app.Usage = "Interact with your plex server and plex.tv from the command line"
 	app.Version = "0.0.1"
 
@@ -94,6 +94,17 @@ func main() {
 			Usage:  "display info on users currently consuming media",
 			Action: getSessions,
 		},
+		{
+			Name:   "kill-session",
+			Usage:  "stop a user's playback session by `id`, see 'sessions' for ids",
+			Action: killSession,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "reason",
+					Usage: "message shown to the user",
+				},
+			},
+		},
 		{
 			Name:   "pick-server",
 			Usage:  "choose a server to interact with",
@@ -114,6 +125,18 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "listen",
+			Usage:  "start a webhook listener and print incoming events",
+			Action: listenWebhooks,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "addr",
+					Value: ":8080",
+					Usage: "address to listen on",
+				},
+			},
+		},
 		{
 			Name:   "search",
 			Usage:  "search for media information on your server",
@@ -0,0 +1,192 @@
+// Package credentials persists the client identifier and token of a plex client, so apps
+// only ask the user to sign in once.
+//
+//	store := credentials.NewFileStore(path)
+//	creds, err := credentials.LoadOrLogin(store, credentials.PINLogin(ctx, func(code string) {
+//		fmt.Println("link this app at https://plex.tv/link with code", code)
+//	}))
+//
+//	client, err := plex.New(url, creds.Token, creds.Options()...)
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/timothystewart6/go-plex-client"
+)
+
+// ErrNotFound is returned by a Store that has no credentials saved yet
+var ErrNotFound = errors.New("credentials not found")
+
+// Credentials identify an app to plex
+type Credentials struct {
+	ClientIdentifier string `json:"clientIdentifier"`
+	Token            string `json:"token"`
+}
+
+// Options returns the options to create a client with these credentials. The token is
+// passed to plex.New separately.
+func (c Credentials) Options() []plex.Option {
+	return []plex.Option{plex.WithClientIdentifier(c.ClientIdentifier)}
+}
+
+// Store loads and saves credentials
+type Store interface {
+	// Load returns ErrNotFound when no credentials were saved
+	Load() (Credentials, error)
+	Save(c Credentials) error
+}
+
+// LoginFunc signs in with clientIdentifier and returns the token
+type LoginFunc func(clientIdentifier string) (token string, err error)
+
+// LoadOrLogin loads the saved credentials, or signs in with login and saves the result
+// when there are none or they lack a token. A client identifier is generated on first use.
+func LoadOrLogin(store Store, login LoginFunc) (Credentials, error) {
+	creds, err := store.Load()
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Credentials{}, err
+	}
+
+	if creds.Token != "" && creds.ClientIdentifier != "" {
+		return creds, nil
+	}
+
+	if creds.ClientIdentifier == "" {
+		creds.ClientIdentifier = uuid.NewString()
+	}
+
+	if creds.Token, err = login(creds.ClientIdentifier); err != nil {
+		return Credentials{}, err
+	}
+
+	if err := store.Save(creds); err != nil {
+		return Credentials{}, err
+	}
+
+	return creds, nil
+}
+
+// FileStore saves credentials as json to a file only readable by the current user
+type FileStore struct {
+	Path string
+}
+
+// NewFileStore returns a store saving to path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// DefaultPath returns the path of the credentials file of app in the user's config directory,
+// e.g. ~/.config/<app>/credentials.json on linux
+func DefaultPath(app string) (string, error) {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, app, "credentials.json"), nil
+}
+
+// Load implements Store
+func (s *FileStore) Load() (Credentials, error) {
+	var creds Credentials
+
+	b, err := os.ReadFile(s.Path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return creds, ErrNotFound
+	} else if err != nil {
+		return creds, err
+	}
+
+	if err := json.Unmarshal(b, &creds); err != nil {
+		return creds, fmt.Errorf("invalid credentials file %s: %w", s.Path, err)
+	}
+
+	return creds, nil
+}
+
+// Save implements Store. The file is replaced atomically.
+func (s *FileStore) Save(c Credentials) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".credentials-*")
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// Keyring is a secret store such as the OS keychain, e.g. github.com/zalando/go-keyring
+// satisfies it. Get returns an error when the secret does not exist.
+type Keyring interface {
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+}
+
+// KeyringStore saves credentials in a Keyring under Service
+type KeyringStore struct {
+	Keyring Keyring
+	Service string
+}
+
+// keyringUser is the keyring entry holding the credentials
+const keyringUser = "credentials"
+
+// Load implements Store. Any error from the keyring is reported as ErrNotFound, as
+// keyrings don't agree on how a missing secret is reported.
+func (s KeyringStore) Load() (Credentials, error) {
+	var creds Credentials
+
+	secret, err := s.Keyring.Get(s.Service, keyringUser)
+
+	if err != nil || secret == "" {
+		return creds, ErrNotFound
+	}
+
+	if err := json.Unmarshal([]byte(secret), &creds); err != nil {
+		return creds, fmt.Errorf("invalid credentials in keyring %s: %w", s.Service, err)
+	}
+
+	return creds, nil
+}
+
+// Save implements Store
+func (s KeyringStore) Save(c Credentials) error {
+	b, err := json.Marshal(c)
+
+	if err != nil {
+		return err
+	}
+
+	return s.Keyring.Set(s.Service, keyringUser, string(b))
+}
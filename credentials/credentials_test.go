@@ -0,0 +1,143 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/timothystewart6/go-plex-client"
+)
+
+func TestLoadOrLogin(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "app", "credentials.json"))
+
+	logins := 0
+	login := func(clientIdentifier string) (string, error) {
+		logins++
+
+		if clientIdentifier == "" {
+			t.Errorf("expected a client identifier")
+		}
+
+		return "token-" + clientIdentifier, nil
+	}
+
+	first, err := LoadOrLogin(store, login)
+	if err != nil {
+		t.Fatalf("LoadOrLogin() error = %v", err)
+	}
+
+	second, err := LoadOrLogin(store, login)
+	if err != nil {
+		t.Fatalf("LoadOrLogin() error = %v", err)
+	}
+
+	if logins != 1 || first != second || first.Token != "token-"+first.ClientIdentifier {
+		t.Errorf("expected a single login, got %d logins, %+v then %+v", logins, first, second)
+	}
+
+	info, err := os.Stat(store.Path)
+	if err != nil {
+		t.Fatalf("expected the credentials to be saved: %v", err)
+	}
+
+	if info.Mode().Perm()&0o077 != 0 {
+		t.Errorf("expected the credentials file to be private, got %v", info.Mode())
+	}
+
+	failing := func(string) (string, error) { return "", errors.New("declined") }
+
+	if _, err := LoadOrLogin(NewFileStore(filepath.Join(t.TempDir(), "none.json")), failing); err == nil {
+		t.Errorf("expected the login error")
+	}
+}
+
+// memoryKeyring is a Keyring kept in memory
+type memoryKeyring map[string]string
+
+func (k memoryKeyring) Get(service, user string) (string, error) {
+	secret, ok := k[service+"/"+user]
+
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+
+	return secret, nil
+}
+
+func (k memoryKeyring) Set(service, user, secret string) error {
+	k[service+"/"+user] = secret
+	return nil
+}
+
+func TestKeyringStore(t *testing.T) {
+	store := KeyringStore{Keyring: memoryKeyring{}, Service: "plexctl"}
+
+	if _, err := store.Load(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	saved := Credentials{ClientIdentifier: "abc", Token: "secret"}
+
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if loaded, err := store.Load(); err != nil || loaded != saved {
+		t.Errorf("Load() = %+v, %v; want %+v", loaded, err, saved)
+	}
+}
+
+func TestPINLogin(t *testing.T) {
+	pinPollInterval = time.Millisecond
+
+	checks := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Plex-Client-Identifier"); got != "abc" {
+			t.Errorf("expected the client identifier abc, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":42,"code":"WXYZ"}`))
+			return
+		}
+
+		if checks++; checks < 3 {
+			_, _ = w.Write([]byte(`{"id":42,"code":"WXYZ","authToken":null}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":42,"code":"WXYZ","authToken":"linked-token"}`))
+	}))
+	defer server.Close()
+
+	var code string
+
+	login := PINLogin(context.Background(), func(c string) { code = c }, plex.WithPlexTVURL(server.URL))
+
+	token, err := login("abc")
+	if err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+
+	if token != "linked-token" || code != "WXYZ" {
+		t.Errorf("unexpected token %q and code %q", token, code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	checks = -100
+
+	if _, err := PINLogin(ctx, func(string) {}, plex.WithPlexTVURL(server.URL))("abc"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package credentials
+
+import (
+	"context"
+	"time"
+
+	"github.com/timothystewart6/go-plex-client"
+)
+
+// pinPollInterval is how often PINLogin checks whether the pin was linked
+var pinPollInterval = 2 * time.Second
+
+// PINLogin returns a LoginFunc that requests a pin, shows its code with prompt and waits
+// until the user links it at https://plex.tv/link or ctx is done. opts configure the client
+// of the pin requests, e.g. plex.WithPlexTVURL or plex.WithProxy.
+func PINLogin(ctx context.Context, prompt func(code string), opts ...plex.Option) LoginFunc {
+	return func(clientIdentifier string) (string, error) {
+		// the url is unused, the client only provides the headers of the pin request
+		client, err := plex.New("https://plex.tv", "", append(opts, plex.WithClientIdentifier(clientIdentifier))...)
+
+		if err != nil {
+			return "", err
+		}
+
+		pin, err := plex.RequestPIN(client.Headers, opts...)
+
+		if err != nil {
+			return "", err
+		}
+
+		prompt(pin.Code)
+
+		ticker := time.NewTicker(pinPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-ticker.C:
+			}
+
+			linked, err := plex.CheckPIN(pin.ID, clientIdentifier, opts...)
+
+			if err == nil {
+				return linked.AuthToken, nil
+			}
+
+			if err.Error() != plex.ErrorPINNotAuthorized {
+				return "", err
+			}
+		}
+	}
+}
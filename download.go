@@ -0,0 +1,292 @@
+package plex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DownloadPolicy decides what Download does when a destination file already exists
+type DownloadPolicy int
+
+const (
+	// DownloadOverwrite replaces the existing file
+	DownloadOverwrite DownloadPolicy = iota
+	// DownloadSkip keeps the existing file and does not download the part
+	DownloadSkip
+	// DownloadRename downloads to a free name, e.g. "movie (1).mkv"
+	DownloadRename
+)
+
+// DownloadNaming decides how Download names the files it writes
+type DownloadNaming int
+
+const (
+	// DownloadOriginalFilename keeps the name of the file on the server, as the plex web
+	// downloader does, preserving the naming scheme of tools such as Sonarr or Radarr
+	DownloadOriginalFilename DownloadNaming = iota
+	// DownloadTitleFilename names files from their metadata, e.g. "Show - S01E02 - Title.mkv",
+	// "01 - Track.flac" or "Movie (1995).mkv"
+	DownloadTitleFilename
+)
+
+// DownloadOptions configures DownloadWithOptions
+type DownloadOptions struct {
+	// CreateFolders downloads to <show>/<season> for episodes and tracks, <title> otherwise
+	CreateFolders bool
+	// IfExists applies to every file that already exists at its destination
+	IfExists DownloadPolicy
+	// Naming defaults to the original filename
+	Naming DownloadNaming
+	// Subtitles also downloads the external subtitles of each part next to it, named after
+	// the part with their language, e.g. "movie.eng.srt" or "movie.eng.forced.srt".
+	// Embedded subtitles are part of the media file already.
+	Subtitles bool
+}
+
+// DownloadWithOptions downloads the media parts of meta to path
+func (p *Plex) DownloadWithOptions(meta Metadata, path string, opts DownloadOptions) error {
+	if len(meta.Media) == 0 {
+		return fmt.Errorf("no media associated with metadata, skipping")
+	}
+
+	path = filepath.Join(path)
+
+	if opts.CreateFolders {
+		if meta.ParentTitle != "" && meta.GrandparentTitle != "" { // for tv shows and music
+			path = filepath.Join(path, meta.GrandparentTitle, meta.ParentTitle)
+		} else { // for movies
+			path = filepath.Join(path, meta.Title)
+		}
+
+		if err := os.MkdirAll(path, 0700); err != nil {
+			return err
+		}
+	}
+
+	parts := 0
+
+	for _, media := range meta.Media {
+		parts += len(media.Part)
+	}
+
+	n := 0
+
+	for _, media := range meta.Media {
+		for _, part := range media.Part {
+			n++
+
+			file := originalFilename(part.File)
+
+			if opts.Naming == DownloadTitleFilename {
+				file = titleFilename(meta, part, n, parts)
+			}
+
+			fp, ok := downloadDestination(filepath.Join(path, file), opts.IfExists)
+
+			if ok {
+				query := fmt.Sprintf("%s%s?download=1", p.ServerURL(), part.Key)
+
+				if err := p.downloadFile(query, fp); err != nil {
+					return err
+				}
+			} else {
+				// subtitles of a skipped part are still named after the existing file
+				fp = filepath.Join(path, file)
+			}
+
+			if opts.Subtitles {
+				if err := p.downloadSubtitles(part, fp, opts.IfExists); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// downloadSubtitles downloads the external subtitles of part next to mediaPath
+func (p *Plex) downloadSubtitles(part Part, mediaPath string, policy DownloadPolicy) error {
+	base := strings.TrimSuffix(mediaPath, filepath.Ext(mediaPath))
+	used := map[string]bool{}
+
+	for _, stream := range part.Stream {
+		if stream.StreamType != StreamTypeSubtitle || stream.Key == "" {
+			continue
+		}
+
+		name := subtitleFilename(base, stream)
+
+		// two subtitles with the same language and flags get the stream id appended
+		if used[name] {
+			name = subtitleFilename(fmt.Sprintf("%s.%d", base, stream.ID), stream)
+		}
+
+		used[name] = true
+
+		fp, ok := downloadDestination(name, policy)
+
+		if !ok {
+			continue
+		}
+
+		if err := p.downloadFile(p.ServerURL()+stream.Key, fp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// subtitleFilename names a subtitle file after base, e.g. "base.eng.sdh.srt"
+func subtitleFilename(base string, stream Stream) string {
+	name := base
+
+	if stream.LanguageCode != "" {
+		name += "." + stream.LanguageCode
+	}
+
+	if stream.Forced {
+		name += ".forced"
+	}
+
+	if stream.HearingImpaired {
+		name += ".sdh"
+	}
+
+	ext := stream.Codec
+
+	if ext == "" {
+		ext = "srt"
+	}
+
+	return name + "." + ext
+}
+
+// originalFilename returns the base name of a file on the server, which may run windows
+func originalFilename(serverPath string) string {
+	return serverPath[strings.LastIndexAny(serverPath, `/\`)+1:]
+}
+
+// titleFilename names part n of the parts of meta after its metadata
+func titleFilename(meta Metadata, part Part, n, parts int) string {
+	var name string
+
+	switch {
+	case meta.Type == "episode":
+		name = fmt.Sprintf("%s - S%02dE%02d - %s", meta.GrandparentTitle, meta.ParentIndex, meta.Index, meta.Title)
+	case meta.Type == "track":
+		name = fmt.Sprintf("%02d - %s", meta.Index, meta.Title)
+	case meta.Year > 0:
+		name = fmt.Sprintf("%s (%d)", meta.Title, meta.Year)
+	default:
+		name = meta.Title
+	}
+
+	if parts > 1 {
+		name += fmt.Sprintf(" - pt%d", n)
+	}
+
+	ext := filepath.Ext(originalFilename(part.File))
+
+	if ext == "" && part.Container != "" {
+		ext = "." + part.Container
+	}
+
+	return sanitizeFilename(name) + ext
+}
+
+// sanitizeFilename replaces the characters that are not allowed in file names on common systems
+func sanitizeFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < 32 {
+			return '_'
+		}
+
+		return r
+	}, strings.TrimSpace(name))
+}
+
+// downloadDestination applies policy to fp, ok is false when the part should be skipped
+func downloadDestination(fp string, policy DownloadPolicy) (string, bool) {
+	if _, err := os.Stat(fp); err != nil {
+		return fp, true
+	}
+
+	switch policy {
+	case DownloadSkip:
+		return "", false
+	case DownloadRename:
+		ext := filepath.Ext(fp)
+		base := strings.TrimSuffix(fp, ext)
+
+		for i := 1; ; i++ {
+			candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+
+			if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
+				return candidate, true
+			}
+		}
+	default:
+		return fp, true
+	}
+}
+
+// downloadFile writes the response to query to fp. The response is written to a temporary
+// file next to fp that replaces it once complete, so an interrupted download never leaves
+// a truncated file that DownloadSkip would take as done.
+func (p *Plex) downloadFile(query, fp string) error {
+	resp, err := p.grab(query, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(fp), "."+filepath.Base(fp)+".*.part")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		safeClose(out)
+		_ = os.Remove(out.Name())
+
+		return err
+	}
+
+	// CreateTemp only lets the owner read the file, give it the permissions of os.Create
+	if err := out.Chmod(0o644); err != nil {
+		safeClose(out)
+		_ = os.Remove(out.Name())
+
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		_ = os.Remove(out.Name())
+
+		return err
+	}
+
+	if err := os.Rename(out.Name(), fp); err != nil {
+		_ = os.Remove(out.Name())
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlex_DownloadWithOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("new content"))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	meta := Metadata{
+		Title: "Heat",
+		Media: []Media{{Part: []Part{
+			{Key: "/library/parts/1/file.mkv", File: "/movies/Heat/Heat (1995) - part1.mkv"},
+			{Key: "/library/parts/2/file.mkv", File: "/movies/Heat/Heat (1995) - part2.mkv"},
+		}}},
+	}
+
+	read := func(name string) string {
+		b, _ := os.ReadFile(name)
+		return string(b)
+	}
+
+	tests := []struct {
+		policy   DownloadPolicy
+		expected map[string]string
+	}{
+		{DownloadOverwrite, map[string]string{
+			"Heat (1995) - part1.mkv": "new content",
+			"Heat (1995) - part2.mkv": "new content",
+		}},
+		// skipping an existing part still downloads the others
+		{DownloadSkip, map[string]string{
+			"Heat (1995) - part1.mkv": "old content",
+			"Heat (1995) - part2.mkv": "new content",
+		}},
+		{DownloadRename, map[string]string{
+			"Heat (1995) - part1.mkv":     "old content",
+			"Heat (1995) - part1 (1).mkv": "old content",
+			"Heat (1995) - part1 (2).mkv": "new content",
+			"Heat (1995) - part2.mkv":     "new content",
+		}},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+
+		_ = os.WriteFile(filepath.Join(dir, "Heat (1995) - part1.mkv"), []byte("old content"), 0600)
+
+		if test.policy == DownloadRename {
+			_ = os.WriteFile(filepath.Join(dir, "Heat (1995) - part1 (1).mkv"), []byte("old content"), 0600)
+		}
+
+		if err := p.DownloadWithOptions(meta, dir, DownloadOptions{IfExists: test.policy}); err != nil {
+			t.Fatalf("DownloadWithOptions(%v) error = %v", test.policy, err)
+		}
+
+		entries, _ := os.ReadDir(dir)
+
+		if len(entries) != len(test.expected) {
+			t.Errorf("policy %v: expected %d files, got %d", test.policy, len(test.expected), len(entries))
+		}
+
+		for name, content := range test.expected {
+			if got := read(filepath.Join(dir, name)); got != content {
+				t.Errorf("policy %v: %s = %q, want %q", test.policy, name, got, content)
+			}
+		}
+	}
+}
+
+func TestPlex_DownloadInterrupted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// promise more than is sent so the client sees the connection drop
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte("partial"))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	meta := Metadata{Media: []Media{{Part: []Part{{Key: "/library/parts/1/file.mkv", File: "/movies/Heat (1995).mkv"}}}}}
+	dir := t.TempDir()
+
+	if err := p.DownloadWithOptions(meta, dir, DownloadOptions{IfExists: DownloadSkip}); err == nil {
+		t.Fatal("expected an error for an interrupted download")
+	}
+
+	// nothing is left behind for DownloadSkip to mistake for a complete file
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no files after an interrupted download, got %v", entries)
+	}
+}
+
+func TestPlex_DownloadSubtitles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	meta := Metadata{
+		Title: "Heat",
+		Media: []Media{{Part: []Part{{
+			Key:  "/library/parts/1/file.mkv",
+			File: "/movies/Heat.mkv",
+			Stream: []Stream{
+				{StreamType: StreamTypeVideo, Codec: "h264"},
+				{StreamType: StreamTypeSubtitle, Codec: "srt", LanguageCode: "eng"}, // embedded
+				{StreamType: StreamTypeSubtitle, ID: 10, Key: "/library/streams/10", Codec: "srt", LanguageCode: "eng"},
+				{StreamType: StreamTypeSubtitle, ID: 11, Key: "/library/streams/11", Codec: "srt", LanguageCode: "eng"},
+				{StreamType: StreamTypeSubtitle, ID: 12, Key: "/library/streams/12", Codec: "ass", LanguageCode: "fra", Forced: true},
+			},
+		}}}},
+	}
+
+	dir := t.TempDir()
+
+	if err := p.DownloadWithOptions(meta, dir, DownloadOptions{Subtitles: true}); err != nil {
+		t.Fatalf("DownloadWithOptions() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"Heat.mkv":            "/library/parts/1/file.mkv",
+		"Heat.eng.srt":        "/library/streams/10",
+		"Heat.11.eng.srt":     "/library/streams/11",
+		"Heat.fra.forced.ass": "/library/streams/12",
+	}
+
+	entries, _ := os.ReadDir(dir)
+
+	if len(entries) != len(expected) {
+		t.Errorf("expected %d files, got %d", len(expected), len(entries))
+	}
+
+	for name, content := range expected {
+		if b, _ := os.ReadFile(filepath.Join(dir, name)); string(b) != content {
+			t.Errorf("%s = %q, want %q", name, b, content)
+		}
+	}
+}
+
+func TestDownloadFilenames(t *testing.T) {
+	tests := []struct {
+		meta     Metadata
+		part     Part
+		n, parts int
+		original string
+		title    string
+	}{
+		{
+			Metadata{Type: "episode", Title: "Pilot: Part 1", GrandparentTitle: "Leverage", ParentIndex: 1, Index: 2},
+			Part{File: "/tv/Leverage/Season 01/Leverage.S01E02.WEBDL-1080p.mkv"}, 1, 1,
+			"Leverage.S01E02.WEBDL-1080p.mkv", "Leverage - S01E02 - Pilot_ Part 1.mkv",
+		},
+		{
+			Metadata{Type: "track", Title: "Intro", Index: 1},
+			Part{File: `D:\Music\Artist\Album\01 Intro.flac`}, 1, 1,
+			"01 Intro.flac", "01 - Intro.flac",
+		},
+		{
+			Metadata{Type: "movie", Title: "Heat", Year: 1995},
+			Part{File: "/movies/Heat/cd2", Container: "avi"}, 2, 2,
+			"cd2", "Heat (1995) - pt2.avi",
+		},
+	}
+
+	for _, test := range tests {
+		if got := originalFilename(test.part.File); got != test.original {
+			t.Errorf("originalFilename(%q) = %q, want %q", test.part.File, got, test.original)
+		}
+
+		if got := titleFilename(test.meta, test.part, test.n, test.parts); got != test.title {
+			t.Errorf("titleFilename(%+v) = %q, want %q", test.meta, got, test.title)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package plex
+
+import "time"
+
+// milliseconds converts a plex duration or offset, which are always in milliseconds, to a time.Duration
+func milliseconds(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+// RunTime returns Duration, the length of the media, as a time.Duration
+func (m Metadata) RunTime() time.Duration { return milliseconds(int64(m.Duration)) }
+
+// ViewOffsetDuration returns ViewOffset, the playback position, as a time.Duration
+func (m Metadata) ViewOffsetDuration() time.Duration { return milliseconds(int64(m.ViewOffset)) }
+
+// RunTime returns Duration as a time.Duration
+func (m Media) RunTime() time.Duration { return milliseconds(int64(m.Duration)) }
+
+// RunTime returns Duration as a time.Duration
+func (p Part) RunTime() time.Duration { return milliseconds(int64(p.Duration)) }
+
+// RunTime returns Duration as a time.Duration
+func (t TranscodeSession) RunTime() time.Duration { return milliseconds(t.Duration) }
+
+// ViewOffsetDuration returns ViewOffset, the playback position, as a time.Duration
+func (n PlaySessionStateNotification) ViewOffsetDuration() time.Duration {
+	return milliseconds(n.ViewOffset)
+}
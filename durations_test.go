@@ -0,0 +1,23 @@
+package plex
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMetadataDurations(t *testing.T) {
+	var m Metadata
+
+	if err := json.Unmarshal([]byte(`{"duration":5400000,"viewOffset":90500}`), &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := m.RunTime(); got != 90*time.Minute {
+		t.Errorf("RunTime() = %v; want %v", got, 90*time.Minute)
+	}
+
+	if got := m.ViewOffsetDuration(); got != 90*time.Second+500*time.Millisecond {
+		t.Errorf("ViewOffsetDuration() = %v; want 1m30.5s", got)
+	}
+}
@@ -1,5 +1,11 @@
 package plex
 
+import (
+	"errors"
+	"net/url"
+	"strconv"
+)
+
 // ErrorInvalidToken a constant to help check invalid token errors
 const (
 	ErrorInvalidToken       = "invalid token"
@@ -15,4 +21,45 @@ const (
 	ErrorLinkAccount        = "failed to link account: %s"
 	ErrorFailedToSetWebhook = "failed to set webhook"
 	ErrorWebhook            = "webhook error: %s"
+	ErrorNoUnwatched        = "no unwatched episodes"
 )
+
+// RequestError is returned when a request fails or the server replies with an error status.
+// It carries enough of the exchange to debug a misbehaving server, the token is redacted.
+type RequestError struct {
+	Method string
+	// URL is the requested url with the X-Plex-Token parameter redacted
+	URL string
+	// StatusCode is 0 when no response was received
+	StatusCode int
+	Status     string
+	// Body is the beginning of the response body, at most maxErrorBodyExcerpt bytes
+	Body string
+	Err  error
+}
+
+// maxErrorBodyExcerpt is how much of a response body a RequestError keeps
+const maxErrorBodyExcerpt = 512
+
+func (e *RequestError) Error() string {
+	msg := e.Method + " " + e.URL
+
+	if e.StatusCode == 0 {
+		var urlErr *url.Error
+
+		// url errors repeat the method and the url, with the token
+		if errors.As(e.Err, &urlErr) {
+			return msg + ": " + urlErr.Err.Error()
+		}
+	}
+
+	msg += ": " + e.Err.Error()
+
+	if e.Body != "" {
+		msg += ": " + strconv.Quote(e.Body)
+	}
+
+	return msg
+}
+
+func (e *RequestError) Unwrap() error { return e.Err }
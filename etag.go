@@ -0,0 +1,95 @@
+package plex
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxETagEntries bounds the number of responses kept for conditional requests
+const maxETagEntries = 512
+
+// WithConditionalRequests remembers the ETag of json and xml GET responses and sends it
+// back in If-None-Match. When the server answers 304 Not Modified the remembered body is
+// returned as a regular 200 response, so polling clients only download changed data.
+func WithConditionalRequests() Option {
+	return func(p *Plex) {
+		p.etags = &etagCache{entries: map[string]cacheEntry{}}
+	}
+}
+
+// etagCache stores the last response carrying an ETag per accept header and url
+type etagCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// prepare adds If-None-Match to req when a response for it was stored before
+func (c *etagCache) prepare(req *http.Request) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[cacheKey(req)]
+	c.mu.Unlock()
+
+	if ok {
+		req.Header.Set("If-None-Match", entry.header.Get("ETag"))
+	}
+}
+
+// update stores responses carrying an ETag and swaps 304 responses for the stored one
+func (c *etagCache) update(req *http.Request, resp *http.Response) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return resp, nil
+	}
+
+	key := cacheKey(req)
+
+	if resp.StatusCode == http.StatusNotModified {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+
+		if !ok {
+			return resp, nil
+		}
+
+		safeClose(resp.Body)
+
+		return entry.response(req), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+
+	if etag == "" {
+		return resp, nil
+	}
+
+	body, ok, err := readCacheable(resp)
+
+	if !ok {
+		return resp, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= maxETagEntries {
+		// evict an arbitrary entry, the next request for it is simply unconditional
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+
+	c.entries[key] = cacheEntry{
+		path:       req.URL.Path,
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       body,
+	}
+
+	return resp, nil
+}
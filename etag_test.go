@@ -0,0 +1,44 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithConditionalRequests(t *testing.T) {
+	var notModified int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Directory":[{"key":"1","title":"Movies"}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token", WithConditionalRequests())
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		libraries, err := p.GetLibraries()
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+
+		if len(libraries.MediaContainer.Directory) != 1 || libraries.MediaContainer.Directory[0].Title != "Movies" {
+			t.Fatalf("request %d: unexpected libraries: %+v", i, libraries.MediaContainer.Directory)
+		}
+	}
+
+	if notModified != 2 {
+		t.Errorf("expected 2 not modified responses, got %d", notModified)
+	}
+}
@@ -0,0 +1,378 @@
+package plex
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat is the output format of ExportLibrary
+type ExportFormat string
+
+// Supported export formats
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportJSON ExportFormat = "json"
+)
+
+// ExportColumn is a field of an exported item
+type ExportColumn string
+
+// Columns ExportLibrary can emit
+const (
+	ColumnSection    ExportColumn = "section"
+	ColumnRatingKey  ExportColumn = "ratingKey"
+	ColumnType       ExportColumn = "type"
+	ColumnTitle      ExportColumn = "title"
+	ColumnShow       ExportColumn = "show" // grandparent title, the show of an episode or the artist of a track
+	ColumnSeason     ExportColumn = "season"
+	ColumnEpisode    ExportColumn = "episode"
+	ColumnYear       ExportColumn = "year"
+	ColumnGUIDs      ExportColumn = "guids"
+	ColumnFilePath   ExportColumn = "file"
+	ColumnResolution ExportColumn = "resolution"
+	ColumnWatched    ExportColumn = "watched"
+	ColumnViewCount  ExportColumn = "viewCount"
+	ColumnAddedAt    ExportColumn = "addedAt"
+)
+
+// DefaultExportColumns are exported when ExportOptions.Columns is empty
+var DefaultExportColumns = []ExportColumn{ColumnSection, ColumnTitle, ColumnYear, ColumnGUIDs, ColumnFilePath, ColumnResolution, ColumnWatched}
+
+// defaultExportPageSize is the number of items requested per page
+const defaultExportPageSize = 100
+
+// ExportOptions configures ExportLibrary
+type ExportOptions struct {
+	Format  ExportFormat   // defaults to ExportCSV
+	Columns []ExportColumn // defaults to DefaultExportColumns
+	// Type exports items of a type other than the section's default, e.g. MediaTypeEpisode
+	// to export every episode of a show section
+	Type     MediaType
+	PageSize int // defaults to 100
+	// Progress is called after each page with the number of items of the section exported so far
+	Progress func(section Directory, exported, total int)
+}
+
+// ExportLibrary writes the items of the sections with sectionKeys, or of every section when
+// sectionKeys is empty, to w. Columns with several values, such as guids or files of items
+// with several versions, are joined with ";" in csv output and are arrays in json output.
+func (p *Plex) ExportLibrary(w io.Writer, sectionKeys []string, opts ExportOptions) error {
+	if opts.Format == "" {
+		opts.Format = ExportCSV
+	}
+
+	if opts.Format != ExportCSV && opts.Format != ExportJSON {
+		return fmt.Errorf(ErrorCommon, "unknown export format "+string(opts.Format))
+	}
+
+	if len(opts.Columns) == 0 {
+		opts.Columns = DefaultExportColumns
+	}
+
+	for _, column := range opts.Columns {
+		if _, ok := exportColumnValues[column]; !ok {
+			return fmt.Errorf(ErrorCommon, "unknown export column "+string(column))
+		}
+	}
+
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultExportPageSize
+	}
+
+	sections, err := p.exportSections(sectionKeys)
+
+	if err != nil {
+		return err
+	}
+
+	var out exportWriter
+
+	if opts.Format == ExportJSON {
+		out = &jsonExportWriter{w: w, columns: opts.Columns}
+	} else {
+		out = &csvExportWriter{w: csv.NewWriter(w), columns: opts.Columns}
+	}
+
+	if err := out.begin(); err != nil {
+		return err
+	}
+
+	for _, section := range sections {
+		if err := p.exportSection(out, section, opts); err != nil {
+			return err
+		}
+	}
+
+	return out.end()
+}
+
+// exportSections returns the sections with keys, or all sections when keys is empty
+func (p *Plex) exportSections(keys []string) ([]Directory, error) {
+	libraries, err := p.GetLibraries()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return libraries.MediaContainer.Directory, nil
+	}
+
+	sections := make([]Directory, 0, len(keys))
+
+	for _, key := range keys {
+		found := false
+
+		for _, section := range libraries.MediaContainer.Directory {
+			if section.Key == key {
+				sections = append(sections, section)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf(ErrorCommon, "library section "+key+" not found")
+		}
+	}
+
+	return sections, nil
+}
+
+// exportSection writes every item of section, a page at a time
+func (p *Plex) exportSection(out exportWriter, section Directory, opts ExportOptions) error {
+	filter := NewLibraryFilter().Set("includeGuids", "1")
+
+	if opts.Type != "" {
+		filter.Type(opts.Type)
+	}
+
+	return p.walkSection(section.Key, filter, opts.PageSize, func(items []Metadata, done, total int) error {
+		for _, item := range items {
+			if err := out.write(section, item); err != nil {
+				return err
+			}
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(section, done, total)
+		}
+
+		return nil
+	})
+}
+
+// walkSection calls fn with each page of the items of a section matching filter, along with
+// the number of items listed so far and the total number of matching items
+func (p *Plex) walkSection(sectionKey string, filter *LibraryFilter, pageSize int, fn func(items []Metadata, done, total int) error) error {
+	done := 0
+
+	for {
+		query := fmt.Sprintf("%s/library/sections/%s/all?X-Plex-Container-Start=%d&X-Plex-Container-Size=%d", p.ServerURL(), sectionKey, done, pageSize)
+
+		if params := filter.Encode(); params != "" {
+			query += "&" + params
+		}
+
+		page, err := getContainer[MediaContainer](p, query)
+
+		if err != nil {
+			return err
+		}
+
+		done += len(page.MediaContainer.Metadata)
+
+		total := page.MediaContainer.TotalSize
+
+		if total == 0 {
+			total = done
+		}
+
+		if err := fn(page.MediaContainer.Metadata, done, total); err != nil {
+			return err
+		}
+
+		if len(page.MediaContainer.Metadata) == 0 || done >= total {
+			return nil
+		}
+	}
+}
+
+// exportColumnValues extracts the values of each column from an item
+var exportColumnValues = map[ExportColumn]func(section Directory, m Metadata) []string{
+	ColumnSection:   func(section Directory, m Metadata) []string { return []string{section.Title} },
+	ColumnRatingKey: func(section Directory, m Metadata) []string { return []string{m.RatingKey} },
+	ColumnType:      func(section Directory, m Metadata) []string { return []string{m.Type} },
+	ColumnTitle:     func(section Directory, m Metadata) []string { return []string{m.Title} },
+	ColumnShow:      func(section Directory, m Metadata) []string { return []string{m.GrandparentTitle} },
+	ColumnSeason: func(section Directory, m Metadata) []string {
+		return []string{exportIndex(m.ParentIndex, m.Type == "episode")}
+	},
+	ColumnEpisode: func(section Directory, m Metadata) []string {
+		return []string{exportIndex(m.Index, m.Type == "episode")}
+	},
+	ColumnYear: func(section Directory, m Metadata) []string {
+		if m.Year == 0 {
+			return []string{""}
+		}
+
+		return []string{strconv.Itoa(m.Year)}
+	},
+	ColumnGUIDs: func(section Directory, m Metadata) []string {
+		var guids []string
+
+		if m.GUID != "" {
+			guids = append(guids, m.GUID)
+		}
+
+		for _, guid := range m.AltGUIDs {
+			guids = append(guids, guid.ID)
+		}
+
+		return guids
+	},
+	ColumnFilePath: func(section Directory, m Metadata) []string {
+		var files []string
+
+		for _, media := range m.Media {
+			for _, part := range media.Part {
+				files = append(files, part.File)
+			}
+		}
+
+		return files
+	},
+	ColumnResolution: func(section Directory, m Metadata) []string {
+		var resolutions []string
+
+		for _, media := range m.Media {
+			resolutions = append(resolutions, media.VideoResolution)
+		}
+
+		return resolutions
+	},
+	ColumnWatched: func(section Directory, m Metadata) []string { return []string{strconv.FormatBool(m.ViewCount > 0)} },
+	ColumnViewCount: func(section Directory, m Metadata) []string {
+		return []string{strconv.FormatInt(int64(m.ViewCount), 10)}
+	},
+	ColumnAddedAt: func(section Directory, m Metadata) []string {
+		if t := m.AddedAtTime(); !t.IsZero() {
+			return []string{t.UTC().Format("2006-01-02T15:04:05Z")}
+		}
+
+		return []string{""}
+	},
+}
+
+// exportIndex formats a season or episode number, empty for items that have none
+func exportIndex(index int64, ok bool) string {
+	if !ok {
+		return ""
+	}
+
+	return strconv.FormatInt(index, 10)
+}
+
+// multiValueColumns are written as arrays in json output
+var multiValueColumns = map[ExportColumn]bool{
+	ColumnGUIDs:      true,
+	ColumnFilePath:   true,
+	ColumnResolution: true,
+}
+
+// exportWriter writes exported items in an output format
+type exportWriter interface {
+	begin() error
+	write(section Directory, item Metadata) error
+	end() error
+}
+
+type csvExportWriter struct {
+	w       *csv.Writer
+	columns []ExportColumn
+}
+
+func (c *csvExportWriter) begin() error {
+	header := make([]string, len(c.columns))
+
+	for i, column := range c.columns {
+		header[i] = string(column)
+	}
+
+	return c.w.Write(header)
+}
+
+func (c *csvExportWriter) write(section Directory, item Metadata) error {
+	record := make([]string, len(c.columns))
+
+	for i, column := range c.columns {
+		record[i] = strings.Join(exportColumnValues[column](section, item), ";")
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) end() error {
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+// jsonExportWriter streams a json array of objects, so large libraries are not kept in memory
+type jsonExportWriter struct {
+	w       io.Writer
+	columns []ExportColumn
+	count   int
+}
+
+func (j *jsonExportWriter) begin() error {
+	_, err := io.WriteString(j.w, "[")
+
+	return err
+}
+
+func (j *jsonExportWriter) write(section Directory, item Metadata) error {
+	record := make(map[string]interface{}, len(j.columns))
+
+	for _, column := range j.columns {
+		values := exportColumnValues[column](section, item)
+
+		if multiValueColumns[column] {
+			if values == nil {
+				values = []string{}
+			}
+
+			record[string(column)] = values
+		} else {
+			record[string(column)] = values[0]
+		}
+	}
+
+	encoded, err := json.Marshal(record)
+
+	if err != nil {
+		return err
+	}
+
+	if j.count > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+
+	j.count++
+
+	_, err = j.w.Write(encoded)
+
+	return err
+}
+
+func (j *jsonExportWriter) end() error {
+	_, err := io.WriteString(j.w, "]\n")
+
+	return err
+}
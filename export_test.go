@@ -0,0 +1,139 @@
+package plex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newExportTestServer(t *testing.T) *Plex {
+	t.Helper()
+
+	items := []string{
+		`{"ratingKey":"1","type":"movie","title":"Heat","year":1995,"guid":"plex://movie/1","Guid":[{"id":"imdb://tt0113277"}],"viewCount":2,"Media":[{"videoResolution":"1080","Part":[{"file":"/movies/Heat.mkv"}]}]}`,
+		`{"ratingKey":"2","type":"movie","title":"Ronin, the movie","year":1998,"Media":[{"videoResolution":"720","Part":[{"file":"/movies/Ronin.mkv"}]},{"videoResolution":"4k","Part":[{"file":"/movies/Ronin 4k.mkv"}]}]}`,
+		`{"ratingKey":"3","type":"movie","title":"Thief"}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+
+		switch r.URL.Path {
+		case "/library/sections":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Directory":[{"key":"1","title":"Movies","type":"movie"},{"key":"2","title":"Shows","type":"show"}]}}`))
+		case "/library/sections/1/all":
+			if r.URL.Query().Get("includeGuids") != "1" {
+				t.Errorf("expected includeGuids=1, got %s", r.URL.RawQuery)
+			}
+
+			start, _ := strconv.Atoi(r.URL.Query().Get("X-Plex-Container-Start"))
+			size, _ := strconv.Atoi(r.URL.Query().Get("X-Plex-Container-Size"))
+			end := start + size
+
+			if end > len(items) {
+				end = len(items)
+			}
+
+			page := "[]"
+
+			if start < end {
+				page = "[" + strings.Join(items[start:end], ",") + "]"
+			}
+
+			_, _ = fmt.Fprintf(w, `{"MediaContainer":{"offset":%d,"size":%d,"totalSize":%d,"Metadata":%s}}`, start, end-start, len(items), page)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	return p
+}
+
+func TestPlex_ExportLibraryCSV(t *testing.T) {
+	p := newExportTestServer(t)
+
+	var out bytes.Buffer
+	var progress []int
+
+	err := p.ExportLibrary(&out, []string{"1"}, ExportOptions{
+		PageSize: 2,
+		Progress: func(section Directory, exported, total int) {
+			if total != 3 {
+				t.Errorf("expected a total of 3, got %d", total)
+			}
+
+			progress = append(progress, exported)
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExportLibrary() error = %v", err)
+	}
+
+	expected := "section,title,year,guids,file,resolution,watched\n" +
+		"Movies,Heat,1995,plex://movie/1;imdb://tt0113277,/movies/Heat.mkv,1080,true\n" +
+		"Movies,\"Ronin, the movie\",1998,,/movies/Ronin.mkv;/movies/Ronin 4k.mkv,720;4k,false\n" +
+		"Movies,Thief,,,,,false\n"
+
+	if out.String() != expected {
+		t.Errorf("unexpected csv:\n%s\nwant:\n%s", out.String(), expected)
+	}
+
+	if len(progress) != 2 || progress[0] != 2 || progress[1] != 3 {
+		t.Errorf("unexpected progress %v", progress)
+	}
+}
+
+func TestPlex_ExportLibraryJSON(t *testing.T) {
+	p := newExportTestServer(t)
+
+	var out bytes.Buffer
+
+	err := p.ExportLibrary(&out, []string{"1"}, ExportOptions{Format: ExportJSON, Columns: []ExportColumn{ColumnRatingKey, ColumnGUIDs}})
+	if err != nil {
+		t.Fatalf("ExportLibrary() error = %v", err)
+	}
+
+	var records []struct {
+		RatingKey string   `json:"ratingKey"`
+		GUIDs     []string `json:"guids"`
+	}
+
+	if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+		t.Fatalf("invalid json %q: %v", out.String(), err)
+	}
+
+	if len(records) != 3 || records[0].RatingKey != "1" || len(records[0].GUIDs) != 2 || records[2].GUIDs == nil {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
+
+func TestPlex_ExportLibraryErrors(t *testing.T) {
+	p := newExportTestServer(t)
+
+	tests := []struct {
+		name     string
+		sections []string
+		opts     ExportOptions
+	}{
+		{"unknown format", nil, ExportOptions{Format: "xml"}},
+		{"unknown column", nil, ExportOptions{Columns: []ExportColumn{"bitrate"}}},
+		{"unknown section", []string{"9"}, ExportOptions{}},
+	}
+
+	for _, test := range tests {
+		if err := p.ExportLibrary(&bytes.Buffer{}, test.sections, test.opts); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
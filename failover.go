@@ -0,0 +1,185 @@
+package plex
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// FailoverEvent reports that a client switched to another connection of its server
+type FailoverEvent struct {
+	From string
+	To   string
+	// Err is the error of the request made to From
+	Err error
+}
+
+// failover holds the known connections of the server of a client
+type failover struct {
+	// mu serializes switches, active is read without it
+	mu          sync.Mutex
+	active      atomic.Pointer[failoverTarget]
+	connections []Connection
+	onFailover  func(FailoverEvent)
+}
+
+// failoverTarget is the connection a client switched to from its URL
+type failoverTarget struct {
+	base   string
+	active string
+}
+
+// WithFailover retries requests that fail to reach the server against its other
+// connections, local ones first, then remote ones, then relays, e.g. the connections of
+// a device returned by GetServers. The first connection that answers becomes the
+// ServerURL of the client and onFailover, when not nil, is called. Requests whose body
+// can't be replayed are not retried.
+func WithFailover(connections []Connection, onFailover func(FailoverEvent)) Option {
+	return func(p *Plex) {
+		ordered := make([]Connection, 0, len(connections))
+
+		for _, c := range connections {
+			if c.URI != "" {
+				ordered = append(ordered, c)
+			}
+		}
+
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return connectionRank(ordered[i]) < connectionRank(ordered[j])
+		})
+
+		p.failover = &failover{connections: ordered, onFailover: onFailover}
+	}
+}
+
+// ServerURL returns the url requests to the server are sent to: URL, or the connection
+// the client failed over to while URL is unchanged
+func (p *Plex) ServerURL() string {
+	if p.failover != nil {
+		if target := p.failover.active.Load(); target != nil && target.base == p.URL {
+			return target.active
+		}
+	}
+
+	return p.URL
+}
+
+// connectionRank orders local connections before remote ones and relays last
+func connectionRank(c Connection) int {
+	switch {
+	case c.Relay:
+		return 2
+	case c.Local == 1:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// retry sends req to the other connections of the server after it failed with reqErr.
+// A request made to a connection the client already left is first retried against the
+// current one. ok is false when req was not made to the server, can't be replayed or
+// when every connection failed.
+func (f *failover) retry(p *Plex, client *http.Client, req *http.Request, reqErr error) (resp *http.Response, ok bool) {
+	if req.Context().Err() != nil || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return nil, false
+	}
+
+	from := p.ServerURL()
+	current, err := url.Parse(from)
+
+	if err != nil || !f.serves(p, req.URL.Host) {
+		return nil, false
+	}
+
+	candidates := make([]string, 0, len(f.connections)+1)
+	tried := map[string]bool{req.URL.Host: true}
+
+	if current.Host != req.URL.Host {
+		candidates = append(candidates, from)
+	}
+
+	tried[current.Host] = true
+
+	for _, c := range f.connections {
+		if target, err := url.Parse(c.URI); err == nil && !tried[target.Host] {
+			tried[target.Host] = true
+			candidates = append(candidates, c.URI)
+		}
+	}
+
+	for _, candidate := range candidates {
+		target, err := url.Parse(candidate)
+
+		if err != nil {
+			continue
+		}
+
+		retried := req.Clone(req.Context())
+		retried.URL.Scheme = target.Scheme
+		retried.URL.Host = target.Host
+		retried.Host = ""
+
+		if req.GetBody != nil {
+			if retried.Body, err = req.GetBody(); err != nil {
+				return nil, false
+			}
+		}
+
+		resp, err := client.Do(retried)
+
+		if err != nil {
+			continue
+		}
+
+		if candidate != from {
+			f.switchTo(p, from, candidate, reqErr)
+		}
+
+		return resp, true
+	}
+
+	return nil, false
+}
+
+// serves reports whether host is one of the connections of the server
+func (f *failover) serves(p *Plex, host string) bool {
+	for _, u := range append([]string{p.URL, p.ServerURL()}, connectionURIs(f.connections)...) {
+		if parsed, err := url.Parse(u); err == nil && parsed.Host == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+// switchTo makes the connection to active unless another request already switched away
+// from the connection from
+func (f *failover) switchTo(p *Plex, from, to string, reqErr error) {
+	f.mu.Lock()
+
+	if p.ServerURL() != from {
+		f.mu.Unlock()
+		return
+	}
+
+	f.active.Store(&failoverTarget{base: p.URL, active: to})
+	f.mu.Unlock()
+
+	if f.onFailover != nil {
+		f.onFailover(FailoverEvent{From: from, To: to, Err: reqErr})
+	}
+}
+
+// connectionURIs returns the uris of connections
+func connectionURIs(connections []Connection) []string {
+	uris := make([]string, len(connections))
+
+	for i, c := range connections {
+		uris[i] = c.URI
+	}
+
+	return uris
+}
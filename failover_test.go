@@ -0,0 +1,124 @@
+package plex
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPlex_Failover(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	var bodies []string
+
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Directory":[{"key":"1","title":"Movies"}]}}`))
+	}))
+	defer remote.Close()
+
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to the relay %s", r.URL)
+	}))
+	defer relay.Close()
+
+	var events []FailoverEvent
+
+	connections := []Connection{
+		{URI: relay.URL, Relay: true},
+		{URI: remote.URL},
+		{URI: dead.URL, Local: 1},
+	}
+
+	p, err := New(dead.URL, "token", WithFailover(connections, func(e FailoverEvent) {
+		events = append(events, e)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	libraries, err := p.GetLibraries()
+	if err != nil {
+		t.Fatalf("GetLibraries() error = %v", err)
+	}
+
+	if len(libraries.MediaContainer.Directory) != 1 {
+		t.Errorf("unexpected libraries %+v", libraries)
+	}
+
+	if p.ServerURL() != remote.URL || p.URL != dead.URL {
+		t.Errorf("expected the client to switch to %s, got %s", remote.URL, p.ServerURL())
+	}
+
+	if len(events) != 1 || events[0].From != dead.URL || events[0].To != remote.URL || events[0].Err == nil {
+		t.Errorf("unexpected failover events %+v", events)
+	}
+
+	// bodies are replayed against the next connection, here a request built before the switch
+	if _, err := p.post(dead.URL+"/playlists", []byte("replayed"), p.Headers); err != nil {
+		t.Fatalf("post() error = %v", err)
+	}
+
+	if len(bodies) != 2 || bodies[1] != "replayed" || len(events) != 1 {
+		t.Errorf("unexpected bodies %q", bodies)
+	}
+
+	// without failover the connection error is returned
+	p, err = New(dead.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := p.GetLibraries(); err == nil {
+		t.Error("expected an error without failover")
+	}
+}
+
+func TestPlex_FailoverConcurrent(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	defer remote.Close()
+
+	var failovers atomic.Int32
+
+	p, err := New(dead.URL, "token", WithFailover([]Connection{{URI: dead.URL, Local: 1}, {URI: remote.URL}}, func(e FailoverEvent) {
+		failovers.Add(1)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := p.GetLibraries(); err != nil {
+				t.Errorf("GetLibraries() error = %v", err)
+			}
+
+			_, _ = p.ThumbnailURL("1", "1700000000")
+		}()
+	}
+
+	wg.Wait()
+
+	if failovers.Load() != 1 || p.ServerURL() != remote.URL {
+		t.Errorf("expected a single failover to %s, got %d to %s", remote.URL, failovers.Load(), p.ServerURL())
+	}
+}
@@ -0,0 +1,38 @@
+package plex
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// LockFields locks metadata fields of an item so refreshing its metadata keeps their
+// current values, e.g. LockFields("1", "42", MediaTypeMovie, "title", "summary", "thumb").
+// Tag fields such as TagGenre can be locked too.
+func (p *Plex) LockFields(sectionID, ratingKey string, mediaType MediaType, fields ...string) error {
+	return p.setFieldLocks(sectionID, ratingKey, mediaType, fields, true)
+}
+
+// UnlockFields unlocks metadata fields of an item so the next refresh updates them again
+func (p *Plex) UnlockFields(sectionID, ratingKey string, mediaType MediaType, fields ...string) error {
+	return p.setFieldLocks(sectionID, ratingKey, mediaType, fields, false)
+}
+
+// setFieldLocks sets the locked state of fields of an item
+func (p *Plex) setFieldLocks(sectionID, ratingKey string, mediaType MediaType, fields []string, locked bool) error {
+	if len(fields) == 0 {
+		return fmt.Errorf(ErrorCommon, "at least one field is required")
+	}
+
+	vals := url.Values{}
+
+	if id := mediaType.ID(); id != 0 {
+		vals.Set("type", strconv.Itoa(id))
+	}
+
+	for _, field := range fields {
+		vals.Set(field+".locked", boolToOneOrZero(locked))
+	}
+
+	return p.editItem(sectionID, ratingKey, vals)
+}
@@ -0,0 +1,52 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPlex_LockFields(t *testing.T) {
+	var got url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/library/sections/2/all" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		got = r.URL.Query()
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if err := p.LockFields("2", "7", MediaTypeShow, "title", string(TagGenre)); err != nil {
+		t.Fatalf("LockFields() error = %v", err)
+	}
+
+	want := url.Values{"id": {"7"}, "type": {"2"}, "title.locked": {"1"}, "genre.locked": {"1"}}
+
+	if got.Encode() != want.Encode() {
+		t.Errorf("LockFields() query = %v, want %v", got, want)
+	}
+
+	if err := p.UnlockFields("2", "7", MediaTypeShow, "summary"); err != nil {
+		t.Fatalf("UnlockFields() error = %v", err)
+	}
+
+	if got.Get("summary.locked") != "0" {
+		t.Errorf("UnlockFields() query = %v", got)
+	}
+
+	if err := p.LockFields("", "7", MediaTypeShow, "title"); err == nil {
+		t.Errorf("expected an error without a section")
+	}
+
+	if err := p.LockFields("2", "7", MediaTypeShow); err == nil {
+		t.Errorf("expected an error without fields")
+	}
+}
@@ -0,0 +1,159 @@
+package plex
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// LibraryFilter builds the query string used by GetLibraryContent. Methods return the
+// filter so calls can be chained:
+//
+//	filter := plex.NewLibraryFilter().Type(plex.MediaTypeMovie).Genre("Action").Unwatched().SortDesc("addedAt")
+//	results, err := p.GetLibraryContentFiltered("1", filter)
+type LibraryFilter struct {
+	params []filterParam
+}
+
+// filterParam is a single key=value pair. Plex is sensitive to parameter order for some
+// filters, so params are kept in the order they were added.
+type filterParam struct {
+	key   string
+	value string
+}
+
+// NewLibraryFilter returns an empty filter
+func NewLibraryFilter() *LibraryFilter {
+	return &LibraryFilter{}
+}
+
+// Set adds an arbitrary filter, e.g. Set("studio", "A24"). Operators are part of the key,
+// e.g. Set("rating>>", "7") for a rating greater than 7.
+func (f *LibraryFilter) Set(key, value string) *LibraryFilter {
+	f.params = append(f.params, filterParam{key: key, value: value})
+
+	return f
+}
+
+// Type limits results to a media type, e.g. MediaTypeEpisode to list episodes of a show library
+func (f *LibraryFilter) Type(mediaType MediaType) *LibraryFilter {
+	return f.Set("type", strconv.Itoa(mediaType.ID()))
+}
+
+// Genre limits results to items having any of genres
+func (f *LibraryFilter) Genre(genres ...string) *LibraryFilter {
+	return f.Set("genre", strings.Join(genres, ","))
+}
+
+// Year limits results to items released in year
+func (f *LibraryFilter) Year(year int) *LibraryFilter {
+	return f.Set("year", strconv.Itoa(year))
+}
+
+// YearRange limits results to items released between from and to, both inclusive
+func (f *LibraryFilter) YearRange(from, to int) *LibraryFilter {
+	return f.Set("year>>", strconv.Itoa(from-1)).Set("year<<", strconv.Itoa(to+1))
+}
+
+// Unwatched limits results to items that have not been watched
+func (f *LibraryFilter) Unwatched() *LibraryFilter {
+	return f.Set("unwatched", "1")
+}
+
+// Resolution limits results to a video resolution, e.g. "4k", "1080", "720", "480" or "sd"
+func (f *LibraryFilter) Resolution(resolution string) *LibraryFilter {
+	return f.Set("resolution", resolution)
+}
+
+// SortAsc sorts results by field in ascending order, e.g. "titleSort" or "addedAt"
+func (f *LibraryFilter) SortAsc(field string) *LibraryFilter {
+	return f.Set("sort", field)
+}
+
+// SortDesc sorts results by field in descending order
+func (f *LibraryFilter) SortDesc(field string) *LibraryFilter {
+	return f.Set("sort", field+":desc")
+}
+
+// Or joins the next condition to the previous one with OR instead of the default AND.
+// Plex evaluates these advanced filters for the section's default type, pair them with Type
+// for other types.
+//
+//	// action movies from the 80s, or any documentary
+//	NewLibraryFilter().Group(func(g *LibraryFilter) {
+//		g.Genre("Action").YearRange(1980, 1989)
+//	}).Or().Genre("Documentary")
+func (f *LibraryFilter) Or() *LibraryFilter {
+	return f.Set("or", "1")
+}
+
+// Group adds the conditions added by fn as a parenthesized group. Groups can be nested.
+func (f *LibraryFilter) Group(fn func(g *LibraryFilter)) *LibraryFilter {
+	f.Set("push", "1")
+	fn(f)
+
+	return f.Set("pop", "1")
+}
+
+// AnyOf adds a group matching items that match any of filters, each filter being
+// a group of its own.
+func (f *LibraryFilter) AnyOf(filters ...*LibraryFilter) *LibraryFilter {
+	return f.Group(func(g *LibraryFilter) {
+		for i, filter := range filters {
+			if i > 0 {
+				g.Or()
+			}
+
+			g.Group(func(inner *LibraryFilter) {
+				inner.params = append(inner.params, filter.params...)
+			})
+		}
+	})
+}
+
+// narrows reports whether f has a condition that excludes items, as opposed to only
+// sorting, paging, grouping or selecting a type or extra fields
+func (f *LibraryFilter) narrows() bool {
+	if f == nil {
+		return false
+	}
+
+	for _, param := range f.params {
+		key := strings.ToLower(param.key)
+
+		switch {
+		case key == "sort", key == "type", key == "or", key == "push", key == "pop", key == "limit":
+		case strings.HasPrefix(key, "include"), strings.HasPrefix(key, "x-plex-container-"):
+		default:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Encode returns the escaped query string without the leading "?"
+func (f *LibraryFilter) Encode() string {
+	if f == nil {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(f.params))
+
+	for _, param := range f.params {
+		pairs = append(pairs, url.QueryEscape(param.key)+"="+url.QueryEscape(param.value))
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// String returns the query string including the leading "?", or an empty string for an empty filter
+func (f *LibraryFilter) String() string {
+	encoded := f.Encode()
+
+	if encoded == "" {
+		return ""
+	}
+
+	return "?" + encoded
+}
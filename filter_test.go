@@ -0,0 +1,66 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLibraryFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   *LibraryFilter
+		expected string
+	}{
+		{"empty", NewLibraryFilter(), ""},
+		{"nil", nil, ""},
+		{"type", NewLibraryFilter().Type(MediaTypeEpisode), "?type=4"},
+		{
+			"chained",
+			NewLibraryFilter().Type(MediaTypeMovie).Genre("Action", "Sci-Fi").Unwatched().SortDesc("addedAt"),
+			"?type=1&genre=Action%2CSci-Fi&unwatched=1&sort=addedAt%3Adesc",
+		},
+		{"year range", NewLibraryFilter().YearRange(1990, 1999), "?year%3E%3E=1989&year%3C%3C=2000"},
+		{"escaping", NewLibraryFilter().Set("studio", "Marvel & Co").Resolution("4k").SortAsc("titleSort"), "?studio=Marvel+%26+Co&resolution=4k&sort=titleSort"},
+		{
+			"group",
+			NewLibraryFilter().Group(func(g *LibraryFilter) { g.Genre("Action").Year(1985) }).Or().Genre("Documentary"),
+			"?push=1&genre=Action&year=1985&pop=1&or=1&genre=Documentary",
+		},
+		{
+			"any of",
+			NewLibraryFilter().Unwatched().AnyOf(NewLibraryFilter().Genre("Comedy"), NewLibraryFilter().Resolution("4k").Year(2020)),
+			"?unwatched=1&push=1&push=1&genre=Comedy&pop=1&or=1&push=1&resolution=4k&year=2020&pop=1&pop=1",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.filter.String(); got != test.expected {
+			t.Errorf("%s: String() = %s, want %s", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestPlex_GetLibraryContentFiltered(t *testing.T) {
+	var query string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("genre") + "|" + r.URL.Query().Get("year>>")
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := p.GetLibraryContentFiltered("1", NewLibraryFilter().Genre("Drama").YearRange(2000, 2010)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if query != "Drama|1999" {
+		t.Errorf("expected server to decode the filter, got %q", query)
+	}
+}
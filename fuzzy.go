@@ -0,0 +1,206 @@
+package plex
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// FuzzyMatch is a search result ranked by FuzzyRank, Score is between 0 and 1
+type FuzzyMatch struct {
+	Item  Metadata
+	Score float64
+}
+
+// FuzzySearch searches for query and ranks the results by how well their titles match it,
+// ignoring case, accents and punctuation. It suits queries coming from speech recognition
+// or users who don't know the exact title, e.g. "amelie" finds "Le Fabuleux Destin d'Amélie Poulain".
+// opts.Limit applies to the ranked matches like in SearchPlexWithOptions, a negative value
+// keeps them all.
+func (p *Plex) FuzzySearch(query string, opts SearchOptions) ([]FuzzyMatch, error) {
+	tokens := fuzzyTokens(query)
+
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	results, err := p.search(strings.Join(tokens, " "), opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := results.MediaContainer.Metadata
+
+	// plex matches every word, retry with the longest one for queries with a misheard word
+	if len(items) == 0 && len(tokens) > 1 {
+		longest := tokens[0]
+
+		for _, token := range tokens[1:] {
+			if len(token) > len(longest) {
+				longest = token
+			}
+		}
+
+		if results, err = p.search(longest, opts); err != nil {
+			return nil, err
+		}
+
+		items = results.MediaContainer.Metadata
+	}
+
+	matches := FuzzyRank(query, items)
+
+	if limit := opts.limit(); limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// FuzzyRank scores items against query and returns the matching ones, best first. Episodes
+// and tracks are also matched with their show or artist prepended to their title.
+func FuzzyRank(query string, items []Metadata) []FuzzyMatch {
+	queryTokens := fuzzyTokens(query)
+
+	if len(queryTokens) == 0 {
+		return nil
+	}
+
+	var matches []FuzzyMatch
+
+	for _, item := range items {
+		score := fuzzyScore(queryTokens, fuzzyTokens(item.Title))
+
+		if item.GrandparentTitle != "" {
+			if s := fuzzyScore(queryTokens, fuzzyTokens(item.GrandparentTitle+" "+item.Title)); s > score {
+				score = s
+			}
+		}
+
+		if score > 0 {
+			matches = append(matches, FuzzyMatch{Item: item, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
+
+// fuzzyNormalizer strips accents, e.g. "é" becomes "e"
+var fuzzyNormalizer = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// fuzzyTokens lowercases s, strips its accents and splits it into words
+func fuzzyTokens(s string) []string {
+	normalized, _, err := transform.String(fuzzyNormalizer, s)
+
+	if err != nil {
+		normalized = s
+	}
+
+	return strings.FieldsFunc(strings.ToLower(normalized), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// fuzzyScore rates how well title matches query: 1 for the same words, then the average
+// similarity of each query word to its closest title word, lowered for extra title words
+func fuzzyScore(query, title []string) float64 {
+	if len(title) == 0 {
+		return 0
+	}
+
+	if strings.Join(query, " ") == strings.Join(title, " ") {
+		return 1
+	}
+
+	var total float64
+
+	for _, q := range query {
+		best := 0.0
+
+		for _, t := range title {
+			if s := tokenSimilarity(q, t); s > best {
+				best = s
+			}
+		}
+
+		total += best
+	}
+
+	score := 0.9 * total / float64(len(query))
+
+	if extra := len(title) - len(query); extra > 0 {
+		score -= 0.01 * float64(extra)
+	}
+
+	if score < 0.3 {
+		return 0
+	}
+
+	return score
+}
+
+// tokenSimilarity compares two words: 1 when equal, 0.8 when one is a prefix of the other,
+// otherwise based on their edit distance, ignoring words too different to be a typo
+func tokenSimilarity(a, b string) float64 {
+	a = strings.ReplaceAll(a, "'", "")
+	b = strings.ReplaceAll(b, "'", "")
+
+	if a == b {
+		return 1
+	}
+
+	if len(a) >= 3 && len(b) >= 3 && (strings.HasPrefix(a, b) || strings.HasPrefix(b, a)) {
+		return 0.8
+	}
+
+	longest := len([]rune(a))
+
+	if l := len([]rune(b)); l > longest {
+		longest = l
+	}
+
+	distance := levenshtein([]rune(a), []rune(b))
+
+	if longest < 4 || distance*3 > longest {
+		return 0
+	}
+
+	return 0.8 * (1 - float64(distance)/float64(longest))
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,103 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"Amélie", []string{"amelie"}},
+		{"Le Fabuleux Destin d'Amélie Poulain", []string{"le", "fabuleux", "destin", "d'amelie", "poulain"}},
+		{"  Star Wars: Episode IV - A New Hope ", []string{"star", "wars", "episode", "iv", "a", "new", "hope"}},
+		{"Pokémon 2000", []string{"pokemon", "2000"}},
+		{"", []string{}},
+	}
+
+	for _, test := range tests {
+		if got := fuzzyTokens(test.input); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("fuzzyTokens(%q) = %q; want %q", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestFuzzyRank(t *testing.T) {
+	items := []Metadata{
+		{Title: "Star Trek"},
+		{Title: "Star Wars: Episode IV - A New Hope"},
+		{Title: "Star Wars"},
+		{Title: "Pilot", GrandparentTitle: "Star Wars: Andor"},
+		{Title: "Heat"},
+	}
+
+	matches := FuzzyRank("star wars", items)
+
+	var titles []string
+	for _, match := range matches {
+		titles = append(titles, match.Item.Title)
+	}
+
+	// "Star Trek" only matches one word and ranks last, "Heat" does not match at all
+	expected := []string{"Star Wars", "Pilot", "Star Wars: Episode IV - A New Hope", "Star Trek"}
+
+	if !reflect.DeepEqual(titles, expected) {
+		t.Errorf("FuzzyRank() = %q; want %q", titles, expected)
+	}
+
+	if matches[0].Score != 1 {
+		t.Errorf("expected an exact match to score 1, got %v", matches[0].Score)
+	}
+
+	typo := FuzzyRank("pokemon the moovie", []Metadata{{Title: "Pokémon: The Movie"}})
+
+	if len(typo) != 1 || typo[0].Score < 0.7 {
+		t.Errorf("expected a typo to still match well, got %+v", typo)
+	}
+}
+
+func TestPlex_FuzzySearch(t *testing.T) {
+	var queries []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+
+		w.Header().Set("Content-Type", applicationJson)
+
+		if query != "amelie" {
+			_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":2,"Metadata":[{"title":"Amélie's Garden"},{"title":"Amélie"}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	matches, err := p.FuzzySearch("play Amélie", SearchOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("FuzzySearch() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(queries, []string{"play amelie", "amelie"}) {
+		t.Errorf("unexpected queries %q", queries)
+	}
+
+	if len(matches) != 1 || matches[0].Item.Title != "Amélie" {
+		t.Errorf("unexpected matches %+v", matches)
+	}
+
+	// like SearchPlexWithOptions, 0 keeps DefaultSearchLimit matches and a negative limit keeps them all
+	if matches, err = p.FuzzySearch("amelie", SearchOptions{Limit: -1}); err != nil || len(matches) != 2 {
+		t.Errorf("FuzzySearch() = %+v, %v", matches, err)
+	}
+}
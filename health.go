@@ -0,0 +1,208 @@
+package plex
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultHealthInterval is the interval between checks of a HealthMonitor without Interval
+const DefaultHealthInterval = time.Minute
+
+// HealthStatus is the result of checking a server
+type HealthStatus struct {
+	Server            string
+	Up                bool
+	MachineIdentifier string
+	Version           string
+	FriendlyName      string
+	Latency           time.Duration
+	CheckedAt         time.Time
+	// Err is why the server is down
+	Err error
+}
+
+// HealthMonitor periodically checks the identity and capabilities of servers and reports
+// changes, e.g. to send alerts. The identity check tells whether the server answers, the
+// capabilities check whether it accepts the token. Callbacks run on the goroutine that
+// checks, one server after another, and may call the methods of the monitor.
+type HealthMonitor struct {
+	Servers []ServerClient
+	// Interval is the time between checks, DefaultHealthInterval when 0
+	Interval time.Duration
+	// OnDown is called when a server fails its first check or stops answering
+	OnDown func(HealthStatus)
+	// OnUp is called when a server that was down answers again
+	OnUp func(HealthStatus)
+	// OnVersionChange is called when a server answers with another version than when it
+	// was last up, e.g. after an update
+	OnVersionChange func(previous, current HealthStatus)
+
+	mu     sync.Mutex
+	last   map[string]HealthStatus
+	lastUp map[string]HealthStatus
+	// cancel and done are set while the monitor runs in the background, see Start
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHealthMonitor returns a HealthMonitor for the given servers
+func NewHealthMonitor(servers ...ServerClient) *HealthMonitor {
+	return &HealthMonitor{Servers: servers}
+}
+
+// Run checks the servers right away and then every Interval until ctx is done
+func (m *HealthMonitor) Run(ctx context.Context) error {
+	interval := m.Interval
+
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Start runs the monitor in the background until it is closed, it does nothing when
+// already started
+func (m *HealthMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.done != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.cancel, m.done = cancel, done
+
+	go func() {
+		defer close(done)
+
+		_ = m.Run(ctx)
+	}()
+}
+
+// Close stops a started monitor and waits for the check in progress
+func (m *HealthMonitor) Close() error {
+	return m.Shutdown(context.Background())
+}
+
+// Shutdown stops a started monitor and waits for the check in progress until ctx is done
+func (m *HealthMonitor) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Check checks every server concurrently, calls the callbacks of the changes since the
+// previous check and returns the statuses in the order of Servers
+func (m *HealthMonitor) Check() []HealthStatus {
+	statuses, _ := fanOut(&MultiServer{Servers: m.Servers}, func(s ServerClient) ([]HealthStatus, error) {
+		return []HealthStatus{checkHealth(s)}, nil
+	})
+
+	// callbacks are collected under the lock and called after, so they can use the monitor
+	var callbacks []func()
+
+	m.mu.Lock()
+
+	if m.last == nil {
+		m.last = map[string]HealthStatus{}
+		m.lastUp = map[string]HealthStatus{}
+	}
+
+	for _, current := range statuses {
+		previous, seen := m.last[current.Server]
+		m.last[current.Server] = current
+
+		switch {
+		case !current.Up && (!seen || previous.Up):
+			if m.OnDown != nil {
+				callbacks = append(callbacks, func() { m.OnDown(current) })
+			}
+		case current.Up && seen && !previous.Up:
+			if m.OnUp != nil {
+				callbacks = append(callbacks, func() { m.OnUp(current) })
+			}
+		}
+
+		if !current.Up {
+			continue
+		}
+
+		if up, ok := m.lastUp[current.Server]; ok && up.Version != current.Version && m.OnVersionChange != nil {
+			callbacks = append(callbacks, func() { m.OnVersionChange(up, current) })
+		}
+
+		m.lastUp[current.Server] = current
+	}
+
+	m.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback()
+	}
+
+	return statuses
+}
+
+// Status returns the last status of a server, ok is false before its first check
+func (m *HealthMonitor) Status(server string) (status HealthStatus, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status, ok = m.last[server]
+
+	return status, ok
+}
+
+// checkHealth fetches the identity and then the capabilities of a server
+func checkHealth(s ServerClient) HealthStatus {
+	start := time.Now()
+	status := HealthStatus{Server: s.Name, CheckedAt: start}
+
+	identity, err := s.Client.GetIdentity()
+
+	if err == nil {
+		status.MachineIdentifier = identity.MachineIdentifier
+		status.Version = identity.Version
+
+		var capabilities BaseAPIResponse
+
+		if capabilities, err = s.Client.GetCapabilities(); err == nil {
+			status.FriendlyName = capabilities.MediaContainer.FriendlyName
+		}
+	}
+
+	status.Up = err == nil
+	status.Latency = time.Since(start)
+	status.Err = err
+
+	return status
+}
@@ -0,0 +1,88 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHealthMonitor_Check(t *testing.T) {
+	version, up := "1.40.0", true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+
+		switch r.URL.Path {
+		case "/identity":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"claimed":true,"machineIdentifier":"abc","version":"` + version + `"}}`))
+		case "/":
+			if r.Header.Get("X-Plex-Token") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"MediaContainer":{"friendlyName":"Home","machineIdentifier":"abc","version":"` + version + `"}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	var events []string
+
+	m := NewHealthMonitor(ServerClient{Name: "home", Client: client})
+	// callbacks may use the monitor
+	m.OnDown = func(s HealthStatus) {
+		if _, ok := m.Status(s.Server); ok {
+			events = append(events, "down")
+		}
+	}
+	m.OnUp = func(s HealthStatus) { events = append(events, "up") }
+	m.OnVersionChange = func(previous, current HealthStatus) {
+		events = append(events, previous.Version+"->"+current.Version)
+	}
+
+	statuses := m.Check()
+
+	if len(statuses) != 1 || !statuses[0].Up || statuses[0].MachineIdentifier != "abc" || statuses[0].Version != "1.40.0" || statuses[0].FriendlyName != "Home" {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+
+	// an update restarts the server with a new version
+	up = false
+	m.Check()
+
+	up, version = true, "1.41.0"
+	m.Check()
+	m.Check()
+
+	if want := []string{"down", "up", "1.40.0->1.41.0"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+
+	if status, ok := m.Status("home"); !ok || status.Version != "1.41.0" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// a server that rejects the token is down even though it answers
+	client.Token = "revoked"
+
+	if statuses := m.Check(); statuses[0].Up || statuses[0].Err == nil {
+		t.Errorf("expected a rejected token to fail the check, got %+v", statuses[0])
+	}
+
+	if _, ok := m.Status("cabin"); ok {
+		t.Error("expected no status for an unknown server")
+	}
+}
@@ -1,43 +1,103 @@
 package plex
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
 
-// GetMediaTypeID returns plex's media type id
+// MediaType is the type of a plex metadata item, e.g. "movie" or "episode"
+type MediaType string
+
+// Media types known by plex, see https://github.com/Arcanemagus/plex-api/wiki/MediaTypes
+const (
+	MediaTypeMovie        MediaType = "movie"
+	MediaTypeShow         MediaType = "show"
+	MediaTypeSeason       MediaType = "season"
+	MediaTypeEpisode      MediaType = "episode"
+	MediaTypeTrailer      MediaType = "trailer"
+	MediaTypeComic        MediaType = "comic"
+	MediaTypePerson       MediaType = "person"
+	MediaTypeArtist       MediaType = "artist"
+	MediaTypeAlbum        MediaType = "album"
+	MediaTypeTrack        MediaType = "track"
+	MediaTypePhotoAlbum   MediaType = "photoAlbum"
+	MediaTypePicture      MediaType = "picture"
+	MediaTypePhoto        MediaType = "photo"
+	MediaTypeClip         MediaType = "clip"
+	MediaTypePlaylistItem MediaType = "playlistItem"
+)
+
+// mediaTypes is indexed by the numeric id plex uses for each media type
+var mediaTypes = []MediaType{
+	"",
+	MediaTypeMovie,
+	MediaTypeShow,
+	MediaTypeSeason,
+	MediaTypeEpisode,
+	MediaTypeTrailer,
+	MediaTypeComic,
+	MediaTypePerson,
+	MediaTypeArtist,
+	MediaTypeAlbum,
+	MediaTypeTrack,
+	MediaTypePhotoAlbum,
+	MediaTypePicture,
+	MediaTypePhoto,
+	MediaTypeClip,
+	MediaTypePlaylistItem,
+}
+
+// MediaTypeFromID returns the media type for a numeric plex type id, or an empty MediaType if unknown
+func MediaTypeFromID(id int) MediaType {
+	if id <= 0 || id >= len(mediaTypes) {
+		return ""
+	}
+
+	return mediaTypes[id]
+}
+
+// ParseMediaType accepts a media type name such as "movie" or its numeric id such as "1"
+func ParseMediaType(s string) (MediaType, error) {
+	if id, err := strconv.Atoi(s); err == nil {
+		if t := MediaTypeFromID(id); t != "" {
+			return t, nil
+		}
+	} else if t := MediaType(s); t.ID() != 0 {
+		return t, nil
+	}
+
+	return "", fmt.Errorf("unknown media type %q", s)
+}
+
+// ID returns the numeric id plex uses for the media type, or 0 if it is unknown
+func (t MediaType) ID() int {
+	for id, mediaType := range mediaTypes {
+		if id > 0 && mediaType == t {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// String returns the media type name
+func (t MediaType) String() string {
+	return string(t)
+}
+
+// MediaType returns Type as a MediaType
+func (m Metadata) MediaType() MediaType {
+	return MediaType(m.Type)
+}
+
+// GetMediaTypeID returns plex's media type id. Unknown media types, including ids, are returned unchanged.
 func GetMediaTypeID(mediaType string) string {
-	switch mediaType {
-	case "movie":
-		return "1"
-	case "show":
-		return "2"
-	case "season":
-		return "3"
-	case "episode":
-		return "4"
-	case "trailer":
-		return "5"
-	case "comic":
-		return "6"
-	case "person":
-		return "7"
-	case "artist":
-		return "8"
-	case "album":
-		return "9"
-	case "track":
-		return "10"
-	case "photoAlbum":
-		return "11"
-	case "picture":
-		return "12"
-	case "photo":
-		return "13"
-	case "clip":
-		return "14"
-	case "playlistItem":
-		return "15"
-	default:
-		return mediaType
+	if id := MediaType(mediaType).ID(); id != 0 {
+		return strconv.Itoa(id)
 	}
+
+	return mediaType
 }
 
 // GetMediaType is a helper function that returns the media type. Usually, used after GetMetadata().
@@ -39,6 +39,45 @@ func TestGetMediaTypeID(t *testing.T) {
 	}
 }
 
+// Test MediaType conversions
+func TestMediaType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected MediaType
+		wantErr  bool
+	}{
+		{"movie", MediaTypeMovie, false},
+		{"4", MediaTypeEpisode, false},
+		{"15", MediaTypePlaylistItem, false},
+		{"16", "", true},
+		{"0", "", true},
+		{"invalid", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := ParseMediaType(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseMediaType(%s) error = %v, wantErr %v", test.input, err, test.wantErr)
+		}
+
+		if result != test.expected {
+			t.Errorf("ParseMediaType(%s) = %s, want %s", test.input, result, test.expected)
+		}
+	}
+
+	if id := MediaTypeTrack.ID(); id != 10 {
+		t.Errorf("MediaTypeTrack.ID() = %d, want 10", id)
+	}
+
+	if mediaType := MediaTypeFromID(10); mediaType != MediaTypeTrack {
+		t.Errorf("MediaTypeFromID(10) = %s, want %s", mediaType, MediaTypeTrack)
+	}
+
+	if mediaType := (Metadata{Type: "show"}).MediaType(); mediaType != MediaTypeShow {
+		t.Errorf("Metadata.MediaType() = %s, want %s", mediaType, MediaTypeShow)
+	}
+}
+
 // Test GetMediaType function
 func TestGetMediaType(t *testing.T) {
 	// Test with metadata containing type
@@ -0,0 +1,357 @@
+package plex
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// PlaybackRecord is a normalized playback, from the first play event to the stop event
+type PlaybackRecord struct {
+	// SessionKey identifies the playback, the websocket session key or, for webhooks which do
+	// not carry one, the player and media rating key
+	SessionKey       string        `json:"sessionKey"`
+	RatingKey        string        `json:"ratingKey"`
+	GUID             string        `json:"guid,omitempty"`
+	MediaType        string        `json:"type,omitempty"`
+	Title            string        `json:"title,omitempty"`
+	ParentTitle      string        `json:"parentTitle,omitempty"`
+	GrandparentTitle string        `json:"grandparentTitle,omitempty"`
+	LibrarySectionID int           `json:"librarySectionID,omitempty"`
+	User             string        `json:"user,omitempty"`
+	UserID           int           `json:"userID,omitempty"`
+	Player           string        `json:"player,omitempty"`
+	PlayerID         string        `json:"playerID,omitempty"`
+	Local            bool          `json:"local"`
+	Started          time.Time     `json:"started"`
+	Stopped          time.Time     `json:"stopped"`
+	Paused           time.Duration `json:"paused"`     // total time spent paused
+	ViewOffset       time.Duration `json:"viewOffset"` // position when playback stopped, when known
+	Watched          bool          `json:"watched"`    // plex counted the playback as a view
+}
+
+// PlayDuration returns the time spent playing, excluding pauses
+func (r PlaybackRecord) PlayDuration() time.Duration {
+	return r.Stopped.Sub(r.Started) - r.Paused
+}
+
+// HistoryStore persists playback records of a HistoryRecorder
+type HistoryStore interface {
+	Save(record PlaybackRecord) error
+	List() ([]PlaybackRecord, error)
+}
+
+// HistoryRecorder turns webhook or websocket playing events into PlaybackRecords. Wire it to
+// either source:
+//
+//	webhooks.OnAny(recorder.RecordWebhook)
+//	notifications.OnPlaying(recorder.RecordNotification)
+//
+// A record is saved to the store when its playback stops. Close the recorder to wait for
+// the metadata lookups it started, events recorded after that are ignored.
+type HistoryRecorder struct {
+	store HistoryStore
+	// Plex, when set, is used to look up the titles of media reported by websocket
+	// notifications, which only carry rating keys
+	Plex *Plex
+	// OnError receives errors saving records or looking up metadata
+	OnError func(err error)
+
+	mu     sync.Mutex
+	active map[string]*activePlayback
+	now    func() time.Time
+	// lookups tracks the metadata lookups running in the background
+	lookups sync.WaitGroup
+	closed  bool
+}
+
+// activePlayback is a playback that did not stop yet
+type activePlayback struct {
+	record      PlaybackRecord
+	pausedSince time.Time
+}
+
+// NewHistoryRecorder returns a recorder saving records to store
+func NewHistoryRecorder(store HistoryStore) *HistoryRecorder {
+	return &HistoryRecorder{
+		store:  store,
+		active: map[string]*activePlayback{},
+		now:    time.Now,
+	}
+}
+
+// RecordWebhook records a webhook event, events other than media.* ones are ignored
+func (h *HistoryRecorder) RecordWebhook(w Webhook) {
+	key := w.Player.UUID + "/" + string(w.Metadata.RatingKey)
+
+	h.update(key, playbackState(w.Event), 0, func(r *PlaybackRecord) {
+		r.RatingKey = string(w.Metadata.RatingKey)
+		r.GUID = w.Metadata.GUID
+		r.MediaType = w.Metadata.MediaType
+		r.Title = w.Metadata.Title
+		r.ParentTitle = w.Metadata.ParentTitle
+		r.GrandparentTitle = w.Metadata.GrandparentTitle
+		r.LibrarySectionID = w.Metadata.LibrarySectionID
+		r.User = w.Account.Title
+		r.UserID = w.Account.ID
+		r.Player = w.Player.Title
+		r.PlayerID = w.Player.UUID
+		r.Local = w.Player.Local
+	})
+}
+
+// RecordNotification records the playing notifications of a websocket message
+func (h *HistoryRecorder) RecordNotification(n NotificationContainer) {
+	for _, session := range n.PlaySessionStateNotification {
+		session := session
+
+		h.update(session.SessionKey, session.State, milliseconds(session.ViewOffset), func(r *PlaybackRecord) {
+			r.RatingKey = session.RatingKey
+			r.GUID = session.GUID
+		})
+	}
+}
+
+// playbackState maps webhook events to the states of playing notifications
+func playbackState(event string) string {
+	switch event {
+	case "media.play", "media.resume":
+		return "playing"
+	case "media.pause":
+		return "paused"
+	case "media.stop":
+		return "stopped"
+	case "media.scrobble":
+		return "scrobble"
+	default:
+		return ""
+	}
+}
+
+// update applies a state change to the playback with key, saving it when it stops
+func (h *HistoryRecorder) update(key, state string, offset time.Duration, fill func(r *PlaybackRecord)) {
+	if state == "" || key == "" {
+		return
+	}
+
+	now := h.now()
+
+	h.mu.Lock()
+
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+
+	playback, ok := h.active[key]
+
+	if !ok {
+		if state == "stopped" {
+			// the start was missed, e.g. the recorder started mid playback
+			h.mu.Unlock()
+			return
+		}
+
+		playback = &activePlayback{record: PlaybackRecord{SessionKey: key, Started: now}}
+		h.active[key] = playback
+		fill(&playback.record)
+
+		// the lookup runs in the background, update may be called from the websocket reader
+		if h.Plex != nil && playback.record.Title == "" {
+			h.lookups.Add(1)
+
+			go func() {
+				defer h.lookups.Done()
+
+				h.lookup(key)
+			}()
+		}
+	}
+
+	if offset > 0 {
+		playback.record.ViewOffset = offset
+	}
+
+	switch state {
+	case "playing":
+		playback.resume(now)
+	case "paused":
+		if playback.pausedSince.IsZero() {
+			playback.pausedSince = now
+		}
+	case "scrobble":
+		playback.record.Watched = true
+	case "stopped":
+		playback.resume(now)
+		playback.record.Stopped = now
+		delete(h.active, key)
+	}
+
+	record := playback.record
+
+	h.mu.Unlock()
+
+	if state == "stopped" {
+		if err := h.store.Save(record); err != nil {
+			h.reportError(err)
+		}
+	}
+}
+
+// Close stops recording and waits for the running metadata lookups, see Shutdown
+func (h *HistoryRecorder) Close() error {
+	return h.Shutdown(context.Background())
+}
+
+// Shutdown stops recording and waits for the running metadata lookups. When ctx is done
+// first ctx.Err() is returned, the lookups then finish in the background. Playbacks that
+// did not stop are not saved.
+func (h *HistoryRecorder) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		h.lookups.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resume ends a pause, adding its length to the paused time
+func (a *activePlayback) resume(now time.Time) {
+	if !a.pausedSince.IsZero() {
+		a.record.Paused += now.Sub(a.pausedSince)
+		a.pausedSince = time.Time{}
+	}
+}
+
+// lookup fills the titles of an active playback from its metadata
+func (h *HistoryRecorder) lookup(key string) {
+	h.mu.Lock()
+	playback, ok := h.active[key]
+	var ratingKey string
+	if ok {
+		ratingKey = playback.record.RatingKey
+	}
+	h.mu.Unlock()
+
+	if !ok || ratingKey == "" {
+		return
+	}
+
+	metadata, err := h.Plex.GetMetadata(ratingKey)
+
+	if err != nil {
+		h.reportError(err)
+		return
+	}
+
+	if len(metadata.MediaContainer.Metadata) == 0 {
+		return
+	}
+
+	m := metadata.MediaContainer.Metadata[0]
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// the playback may have stopped meanwhile, its record was then saved without titles
+	if playback, ok := h.active[key]; ok {
+		playback.record.MediaType = m.Type
+		playback.record.Title = m.Title
+		playback.record.ParentTitle = m.ParentTitle
+		playback.record.GrandparentTitle = m.GrandparentTitle
+		playback.record.LibrarySectionID = int(m.LibrarySectionID)
+	}
+}
+
+func (h *HistoryRecorder) reportError(err error) {
+	if h.OnError != nil {
+		h.OnError(err)
+	}
+}
+
+// JSONFileHistoryStore is a HistoryStore appending records as json lines to a file
+type JSONFileHistoryStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJSONFileHistoryStore returns a store using the file at path, created on the first Save
+func NewJSONFileHistoryStore(path string) *JSONFileHistoryStore {
+	return &JSONFileHistoryStore{path: path}
+}
+
+// Save appends record to the file
+func (s *JSONFileHistoryStore) Save(record PlaybackRecord) error {
+	line, err := json.Marshal(record)
+
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		safeClose(f)
+		return err
+	}
+
+	return f.Close()
+}
+
+// List returns every saved record, oldest first
+func (s *JSONFileHistoryStore) List() ([]PlaybackRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer safeClose(f)
+
+	var records []PlaybackRecord
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record PlaybackRecord
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
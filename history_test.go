@@ -0,0 +1,150 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a time advanced by each call to advance
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestHistoryRecorder_Webhooks(t *testing.T) {
+	store := NewJSONFileHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)}
+
+	recorder := NewHistoryRecorder(store)
+	recorder.now = clock.now
+
+	webhook := func(event string) Webhook {
+		return Webhook{
+			Event:    event,
+			Account:  WebhookAccount{ID: 1, Title: "alice"},
+			Player:   WebhookPlayer{Title: "Living Room", UUID: "tv-1", Local: true},
+			Metadata: WebhookMetadata{RatingKey: "42", MediaType: "episode", Title: "Pilot", GrandparentTitle: "Leverage"},
+		}
+	}
+
+	recorder.RecordWebhook(webhook("media.play"))
+	clock.advance(10 * time.Minute)
+	recorder.RecordWebhook(webhook("media.pause"))
+	clock.advance(5 * time.Minute)
+	recorder.RecordWebhook(webhook("media.resume"))
+	clock.advance(30 * time.Minute)
+	recorder.RecordWebhook(webhook("media.scrobble"))
+	recorder.RecordWebhook(webhook("library.new"))
+	clock.advance(time.Minute)
+	recorder.RecordWebhook(webhook("media.stop"))
+
+	// a stop without a start is ignored
+	recorder.RecordWebhook(Webhook{Event: "media.stop", Player: WebhookPlayer{UUID: "tv-2"}, Metadata: WebhookMetadata{RatingKey: "7"}})
+
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %+v", records)
+	}
+
+	r := records[0]
+
+	if r.SessionKey != "tv-1/42" || r.User != "alice" || r.Player != "Living Room" || r.GrandparentTitle != "Leverage" || !r.Watched {
+		t.Errorf("unexpected record: %+v", r)
+	}
+
+	if r.Paused != 5*time.Minute || r.PlayDuration() != 41*time.Minute {
+		t.Errorf("paused = %v, played = %v", r.Paused, r.PlayDuration())
+	}
+}
+
+func TestHistoryRecorder_Notifications(t *testing.T) {
+	store := NewJSONFileHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	p := newBrowseTestServer(t, map[string]string{
+		"/library/metadata/42": `{"MediaContainer":{"Metadata":[{"ratingKey":"42","type":"movie","title":"Heat","librarySectionID":1}]}}`,
+	})
+
+	recorder := NewHistoryRecorder(store)
+	recorder.Plex = p
+	recorder.OnError = func(err error) { t.Errorf("unexpected error: %v", err) }
+
+	notify := func(state string, offset int64) {
+		recorder.RecordNotification(NotificationContainer{
+			PlaySessionStateNotification: []PlaySessionStateNotification{{SessionKey: "5", RatingKey: "42", State: state, ViewOffset: offset}},
+		})
+	}
+
+	notify("playing", 1000)
+	notify("playing", 60000)
+	recorder.lookups.Wait()
+	notify("stopped", 90000)
+
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(records) != 1 || records[0].Title != "Heat" || records[0].ViewOffset != 90*time.Second || records[0].LibrarySectionID != 1 {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
+
+// Test RecordNotification does not wait for the metadata lookup, as it runs on the websocket reader
+func TestHistoryRecorder_AsyncLookup(t *testing.T) {
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[{"ratingKey":"42","type":"movie","title":"Heat"}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	recorder := NewHistoryRecorder(NewJSONFileHistoryStore(filepath.Join(t.TempDir(), "history.jsonl")))
+	recorder.Plex = p
+
+	returned := make(chan struct{})
+
+	go func() {
+		recorder.RecordNotification(NotificationContainer{
+			PlaySessionStateNotification: []PlaySessionStateNotification{{SessionKey: "5", RatingKey: "42", State: "playing"}},
+		})
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("expected RecordNotification to return while the metadata is fetched")
+	}
+
+	close(release)
+	recorder.lookups.Wait()
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if title := recorder.active["5"].record.Title; title != "Heat" {
+		t.Errorf("expected the lookup to fill the title, got %q", title)
+	}
+}
+
+func TestJSONFileHistoryStore_Empty(t *testing.T) {
+	records, err := NewJSONFileHistoryStore(filepath.Join(t.TempDir(), "missing.jsonl")).List()
+
+	if err != nil || len(records) != 0 {
+		t.Errorf("List() = %v, %v; want no records", records, err)
+	}
+}
@@ -0,0 +1,213 @@
+package plex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RestrictionProfile is a preset of content restrictions for a managed home user
+type RestrictionProfile string
+
+// Restriction profiles plex offers, RestrictionProfileCustom uses the filters of
+// HomeUserRestrictions only
+const (
+	RestrictionProfileCustom    RestrictionProfile = ""
+	RestrictionProfileLittleKid RestrictionProfile = "little_kid"
+	RestrictionProfileOlderKid  RestrictionProfile = "older_kid"
+	RestrictionProfileTeen      RestrictionProfile = "teen"
+)
+
+// HomeUser is a member of your plex home, managed users have Restricted set
+type HomeUser struct {
+	ID                 int64              `json:"id"`
+	UUID               string             `json:"uuid"`
+	Title              string             `json:"title"`
+	Username           string             `json:"username"`
+	Email              string             `json:"email"`
+	Thumb              string             `json:"thumb"`
+	Admin              bool               `json:"admin"`
+	Guest              bool               `json:"guest"`
+	Protected          bool               `json:"protected"`
+	Restricted         bool               `json:"restricted"`
+	RestrictionProfile RestrictionProfile `json:"restrictionProfile"`
+}
+
+// GetHomeUsers lists the members of your plex home
+func (p *Plex) GetHomeUsers() ([]HomeUser, error) {
+	var result struct {
+		Users []HomeUser `json:"users"`
+	}
+
+	resp, err := p.get(p.plexTV()+"/api/v2/home/users", p.Headers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Users, nil
+}
+
+// ContentFilter limits the items of a library type a user can see
+type ContentFilter struct {
+	ContentRatings         []string // only items with these ratings, e.g. "G", "PG"
+	ExcludedContentRatings []string
+	Labels                 []string // only items with these labels
+	ExcludedLabels         []string
+}
+
+// String encodes the filter the way plex stores it, e.g. "contentRating=G,PG|label!=Horror"
+func (f ContentFilter) String() string {
+	var parts []string
+
+	add := func(field string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+
+		escaped := make([]string, len(values))
+
+		for i, v := range values {
+			escaped[i] = url.QueryEscape(v)
+		}
+
+		parts = append(parts, field+"="+strings.Join(escaped, ","))
+	}
+
+	add("contentRating", f.ContentRatings)
+	add("contentRating!", f.ExcludedContentRatings)
+	add("label", f.Labels)
+	add("label!", f.ExcludedLabels)
+
+	return strings.Join(parts, "|")
+}
+
+// ParseContentFilter decodes a filter as stored by plex, see ContentFilter.String
+func ParseContentFilter(s string) (ContentFilter, error) {
+	var f ContentFilter
+
+	for _, part := range strings.Split(s, "|") {
+		if part == "" {
+			continue
+		}
+
+		field, values, ok := strings.Cut(part, "=")
+
+		if !ok {
+			return f, fmt.Errorf(ErrorCommon, "invalid content filter "+part)
+		}
+
+		var list []string
+
+		for _, v := range strings.Split(values, ",") {
+			unescaped, err := url.QueryUnescape(v)
+
+			if err != nil {
+				return f, err
+			}
+
+			list = append(list, unescaped)
+		}
+
+		switch field {
+		case "contentRating":
+			f.ContentRatings = append(f.ContentRatings, list...)
+		case "contentRating!":
+			f.ExcludedContentRatings = append(f.ExcludedContentRatings, list...)
+		case "label":
+			f.Labels = append(f.Labels, list...)
+		case "label!":
+			f.ExcludedLabels = append(f.ExcludedLabels, list...)
+		default:
+			return f, fmt.Errorf(ErrorCommon, "unknown content filter field "+field)
+		}
+	}
+
+	return f, nil
+}
+
+// HomeUserRestrictions are the content restrictions of a managed home user
+type HomeUserRestrictions struct {
+	Profile    RestrictionProfile
+	Movies     ContentFilter
+	Television ContentFilter
+	Music      ContentFilter
+}
+
+// SetHomeUserRestrictions replaces the restriction profile and content filters of the
+// managed home user with userID
+func (p *Plex) SetHomeUserRestrictions(userID int64, restrictions HomeUserRestrictions) error {
+	if userID <= 0 {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	id := strconv.FormatInt(userID, 10)
+
+	vals := url.Values{"restrictionProfile": {string(restrictions.Profile)}}
+
+	if err := p.homeRequest(http.MethodPut, "/api/v2/home/users/restricted/"+id+"?"+vals.Encode(), nil); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"invitedId": userID,
+		"settings": map[string]string{
+			"filterMovies":     restrictions.Movies.String(),
+			"filterTelevision": restrictions.Television.String(),
+			"filterMusic":      restrictions.Music.String(),
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return p.homeRequest(http.MethodPost, "/api/v2/sharing_settings", body)
+}
+
+// homeRequest sends a json request to a plex.tv endpoint, expecting no content back
+func (p *Plex) homeRequest(method, endpoint string, body []byte) error {
+	h := p.Headers
+	h.ContentType = applicationJson
+
+	query := p.plexTV() + endpoint
+
+	var resp *http.Response
+	var err error
+
+	if method == http.MethodPut {
+		resp, err = p.put(query, body, h)
+	} else {
+		resp, err = p.post(query, body, h)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
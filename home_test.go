@@ -0,0 +1,90 @@
+package plex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestContentFilter(t *testing.T) {
+	filter := ContentFilter{ContentRatings: []string{"G", "PG"}, ExcludedLabels: []string{"Horror", "Kids & Up"}}
+
+	encoded := filter.String()
+
+	if encoded != "contentRating=G,PG|label!=Horror,Kids+%26+Up" {
+		t.Errorf("String() = %q", encoded)
+	}
+
+	parsed, err := ParseContentFilter(encoded)
+	if err != nil {
+		t.Fatalf("ParseContentFilter() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed, filter) {
+		t.Errorf("ParseContentFilter() = %+v, want %+v", parsed, filter)
+	}
+
+	if _, err := ParseContentFilter("year=2000"); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+}
+
+func TestPlex_HomeUsers(t *testing.T) {
+	var requests []string
+	var settings map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+
+		switch r.URL.Path {
+		case "/api/v2/home/users":
+			w.Header().Set("Content-Type", applicationJson)
+			_, _ = w.Write([]byte(`{"id":1,"users":[{"id":1,"title":"alice","admin":true},{"id":7,"title":"kid","restricted":true,"restrictionProfile":"little_kid"}]}`))
+		case "/api/v2/sharing_settings":
+			_ = json.NewDecoder(r.Body).Decode(&settings)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	plex, err := New("", "token", WithPlexTVURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	users, err := plex.GetHomeUsers()
+	if err != nil {
+		t.Fatalf("GetHomeUsers() error = %v", err)
+	}
+
+	if len(users) != 2 || !users[1].Restricted || users[1].RestrictionProfile != RestrictionProfileLittleKid {
+		t.Errorf("unexpected users %+v", users)
+	}
+
+	err = plex.SetHomeUserRestrictions(7, HomeUserRestrictions{
+		Profile: RestrictionProfileOlderKid,
+		Movies:  ContentFilter{ContentRatings: []string{"PG"}},
+	})
+	if err != nil {
+		t.Fatalf("SetHomeUserRestrictions() error = %v", err)
+	}
+
+	if requests[1] != "PUT /api/v2/home/users/restricted/7?restrictionProfile=older_kid" {
+		t.Errorf("unexpected request %q", requests[1])
+	}
+
+	want := map[string]interface{}{
+		"invitedId": float64(7),
+		"settings":  map[string]interface{}{"filterMovies": "contentRating=PG", "filterTelevision": "", "filterMusic": ""},
+	}
+
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("sharing settings = %v, want %v", settings, want)
+	}
+
+	if err := plex.SetHomeUserRestrictions(0, HomeUserRestrictions{}); err == nil {
+		t.Errorf("expected an error without a user id")
+	}
+}
@@ -0,0 +1,197 @@
+package plex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// DefaultLabelConcurrency is the number of items ApplyLabelToMany labels at once
+const DefaultLabelConcurrency = 4
+
+// ApplyLabelToMany adds label to the items of a section with the given rating keys,
+// labelling up to DefaultLabelConcurrency items at once. The items must be of mediaType,
+// the section's type, e.g. movies or shows but not episodes. Requires a Plex Pass.
+func (p *Plex) ApplyLabelToMany(sectionID string, ratingKeys []string, mediaType MediaType, label string) error {
+	return p.ApplyLabelToManyConcurrently(sectionID, ratingKeys, mediaType, label, DefaultLabelConcurrency)
+}
+
+// ApplyLabelToManyConcurrently is ApplyLabelToMany labelling up to limit items at once.
+// Every item is attempted, the failures are returned together.
+func (p *Plex) ApplyLabelToManyConcurrently(sectionID string, ratingKeys []string, mediaType MediaType, label string, limit int) error {
+	return p.editLabels(sectionID, ratingKeys, mediaType, limit, url.Values{"label[0].tag.tag": {label}})
+}
+
+// RemoveLabelFromMany removes label from the items of a section with the given rating keys,
+// up to DefaultLabelConcurrency items at once
+func (p *Plex) RemoveLabelFromMany(sectionID string, ratingKeys []string, mediaType MediaType, label string) error {
+	// plex splits the list on commas, so commas in the label are escaped as in RemoveTags
+	return p.editLabels(sectionID, ratingKeys, mediaType, DefaultLabelConcurrency, url.Values{"label[].tag.tag-": {url.QueryEscape(label)}})
+}
+
+// ShareLabeledItems shares only the given items of a section with a friend: it labels them
+// with label and adds the label to the friend's filter for the section's type, e.g.
+// share a handful of movies with grandma. Items already visible through the friend's
+// other labels stay visible. Requires a Plex Pass.
+func (p *Plex) ShareLabeledItems(friendID, sectionID string, ratingKeys []string, label string) error {
+	if friendID == "" || label == "" {
+		return fmt.Errorf(ErrorCommon, "a friend and a label are required")
+	}
+
+	libraries, err := p.GetLibraries()
+
+	if err != nil {
+		return err
+	}
+
+	var sectionType string
+
+	for _, dir := range libraries.MediaContainer.Directory {
+		if dir.Key == sectionID {
+			sectionType = dir.Type
+		}
+	}
+
+	if sectionType == "" {
+		return fmt.Errorf(ErrorCommon, "section "+sectionID+" not found")
+	}
+
+	friends, err := p.GetFriends()
+
+	if err != nil {
+		return err
+	}
+
+	var friend *Friends
+
+	for i := range friends {
+		if strconv.Itoa(friends[i].ID) == friendID {
+			friend = &friends[i]
+		}
+	}
+
+	if friend == nil {
+		return fmt.Errorf(ErrorCommon, "friend "+friendID+" not found")
+	}
+
+	params := UpdateFriendParams{
+		AllowSync:         friend.AllowSync,
+		AllowCameraUpload: friend.AllowCameraUpload,
+		AllowChannels:     friend.AllowChannels,
+		FilterMovies:      friend.FilterMovies,
+		FilterTelevision:  friend.FilterTelevision,
+		FilterMusic:       friend.FilterMusic,
+		FilterPhotos:      friend.FilterPhotos,
+	}
+
+	var filter *string
+
+	switch sectionType {
+	case "movie":
+		filter = &params.FilterMovies
+	case "show":
+		filter = &params.FilterTelevision
+	case "artist":
+		filter = &params.FilterMusic
+	case "photo":
+		filter = &params.FilterPhotos
+	default:
+		return fmt.Errorf(ErrorCommon, "can not filter "+sectionType+" sections")
+	}
+
+	contentFilter, err := ParseContentFilter(*filter)
+
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(contentFilter.Labels, label) {
+		contentFilter.Labels = append(contentFilter.Labels, label)
+	}
+
+	*filter = contentFilter.String()
+
+	if err := p.ApplyLabelToMany(sectionID, ratingKeys, MediaType(sectionType), label); err != nil {
+		return err
+	}
+
+	_, err = p.UpdateFriendAccess(friendID, params)
+
+	return err
+}
+
+// editLabels applies the label edit in vals to each item of mediaType, up to limit at once
+func (p *Plex) editLabels(sectionID string, ratingKeys []string, mediaType MediaType, limit int, vals url.Values) error {
+	if sectionID == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	if id := mediaType.ID(); id != 0 {
+		vals.Set("type", strconv.Itoa(id))
+	}
+
+	if limit <= 0 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, ratingKey := range ratingKeys {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(ratingKey string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := p.editItem(sectionID, ratingKey, vals); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("labelling %s: %w", ratingKey, err))
+				mu.Unlock()
+			}
+		}(ratingKey)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// editItem applies the edit in vals to the item with ratingKey through its section
+func (p *Plex) editItem(sectionID, ratingKey string, vals url.Values) error {
+	if sectionID == "" || ratingKey == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := url.Values{"id": {ratingKey}}
+
+	for k, v := range vals {
+		query[k] = v
+	}
+
+	resp, err := p.put(fmt.Sprintf("%s/library/sections/%s/all?%s", p.ServerURL(), sectionID, query.Encode()), nil, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPlex_ApplyLabelToMany(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		labelled []string
+		running  int32
+		peak     int32
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+
+		q := r.URL.Query()
+
+		if r.Method != http.MethodPut || r.URL.Path != "/library/sections/1/all" || q.Get("label[0].tag.tag") != "Kids" || q.Get("type") != "1" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+
+		if q.Get("id") == "3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		mu.Lock()
+		labelled = append(labelled, q.Get("id"))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	err = p.ApplyLabelToManyConcurrently("1", []string{"1", "2", "3", "4", "5"}, MediaTypeMovie, "Kids", 2)
+
+	if err == nil || !strings.Contains(err.Error(), "labelling 3") {
+		t.Errorf("expected the failed item to be reported, got %v", err)
+	}
+
+	sort.Strings(labelled)
+
+	if strings.Join(labelled, ",") != "1,2,4,5" {
+		t.Errorf("labelled %v", labelled)
+	}
+
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", peak)
+	}
+
+	if err := p.ApplyLabelToMany("", []string{"1"}, MediaTypeMovie, "Kids"); err == nil {
+		t.Errorf("expected an error without a section")
+	}
+}
+
+func TestPlex_RemoveLabelFromMany(t *testing.T) {
+	var got url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/library/sections/2/all" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+
+		got = r.URL.Query()
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if err := p.RemoveLabelFromMany("2", []string{"7"}, MediaTypeShow, "Kids, Teens"); err != nil {
+		t.Fatalf("RemoveLabelFromMany() error = %v", err)
+	}
+
+	if got.Get("label[].tag.tag-") != "Kids%2C+Teens" || got.Get("type") != "2" || got.Get("id") != "7" {
+		t.Errorf("RemoveLabelFromMany() query = %v", got)
+	}
+}
+
+func TestPlex_ShareLabeledItems(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		labelled []string
+		access   url.Values
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/library/sections" && r.Method == http.MethodGet:
+			w.Header().Set("Content-Type", applicationJson)
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Directory":[{"key":"1","type":"movie"},{"key":"2","type":"show"}]}}`))
+		case r.URL.Path == "/api/users":
+			w.Header().Set("Content-Type", applicationXml)
+			_, _ = w.Write([]byte(`<MediaContainer size="1"><User id="9" title="grandma" allowSync="1" filterMovies="label=Classics" filterTelevision="contentRating=TV-G"/></MediaContainer>`))
+		case r.URL.Path == "/library/sections/1/all":
+			if r.URL.Query().Get("type") != "1" {
+				t.Errorf("expected the section type to be sent, got %v", r.URL.Query())
+			}
+
+			mu.Lock()
+			labelled = append(labelled, r.URL.Query().Get("id"))
+			mu.Unlock()
+		case r.URL.Path == "/api/friends/9":
+			access = r.URL.Query()
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token", WithPlexTVURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if err := p.ShareLabeledItems("9", "1", []string{"10", "11"}, "Grandma"); err != nil {
+		t.Fatalf("ShareLabeledItems() error = %v", err)
+	}
+
+	sort.Strings(labelled)
+
+	if strings.Join(labelled, ",") != "10,11" {
+		t.Errorf("labelled %v", labelled)
+	}
+
+	if access.Get("filterMovies") != "label=Classics,Grandma" || access.Get("filterTelevision") != "contentRating=TV-G" || access.Get("allowSync") != "1" {
+		t.Errorf("unexpected friend access %v", access)
+	}
+
+	if err := p.ShareLabeledItems("8", "1", []string{"10"}, "Grandma"); err == nil {
+		t.Errorf("expected an error for an unknown friend")
+	}
+}
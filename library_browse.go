@@ -0,0 +1,92 @@
+package plex
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BrowseCategory is a secondary directory of a library section
+type BrowseCategory string
+
+// Secondary directories available for most library sections
+const (
+	BrowseGenre         BrowseCategory = "genre"
+	BrowseYear          BrowseCategory = "year"
+	BrowseDecade        BrowseCategory = "decade"
+	BrowseCountry       BrowseCategory = "country"
+	BrowseDirector      BrowseCategory = "director"
+	BrowseActor         BrowseCategory = "actor"
+	BrowseCollection    BrowseCategory = "collection"
+	BrowseContentRating BrowseCategory = "contentRating"
+	BrowseResolution    BrowseCategory = "resolution"
+)
+
+// GetSecondaryDirectories lists the entries of a section's secondary directory, e.g. all genres
+func (p *Plex) GetSecondaryDirectories(sectionKey string, category BrowseCategory) (SecondaryDirectories, error) {
+	if sectionKey == "" {
+		return SecondaryDirectories{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/%s", p.ServerURL(), sectionKey, url.PathEscape(string(category)))
+
+	return getContainer[SecondaryDirectoryContainer](p, query)
+}
+
+// GetSecondaryDirectoryItems returns the items under a secondary directory entry.
+// key is the Key of a SecondaryDirectory returned by GetSecondaryDirectories.
+func (p *Plex) GetSecondaryDirectoryItems(sectionKey string, category BrowseCategory, key string) (SearchResults, error) {
+	if sectionKey == "" || key == "" {
+		return SearchResults{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/%s/%s", p.ServerURL(), sectionKey, url.PathEscape(string(category)), url.PathEscape(key))
+
+	return getContainer[SearchMediaContainer](p, query)
+}
+
+// GetLibraryFolders lists the top level folders of a section, following the on-disk
+// directory structure instead of the metadata hierarchy
+func (p *Plex) GetLibraryFolders(sectionKey string) (LibraryFolders, error) {
+	if sectionKey == "" {
+		return LibraryFolders{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/folder", p.ServerURL(), sectionKey)
+
+	return getContainer[FolderContainer](p, query)
+}
+
+// GetFolderContents lists the sub folders and media items of a folder. key is the Key
+// of a Folder returned by GetLibraryFolders or a previous GetFolderContents call.
+func (p *Plex) GetFolderContents(key string) (LibraryFolders, error) {
+	if !strings.HasPrefix(key, "/library/sections/") {
+		return LibraryFolders{}, fmt.Errorf(ErrorCommon, "folder key must start with /library/sections/")
+	}
+
+	return getContainer[FolderContainer](p, p.ServerURL()+key)
+}
+
+// GetFirstCharacters returns the index of first characters of the titles in a section,
+// suitable for building an A-Z jump list
+func (p *Plex) GetFirstCharacters(sectionKey string) (FirstCharacters, error) {
+	if sectionKey == "" {
+		return FirstCharacters{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/firstCharacter", p.ServerURL(), sectionKey)
+
+	return getContainer[FirstCharacterContainer](p, query)
+}
+
+// GetItemsByFirstCharacter returns the items of a section whose title starts with character.
+// character is the Key of a FirstCharacter, e.g. "A" or "#" for titles starting with a digit.
+func (p *Plex) GetItemsByFirstCharacter(sectionKey, character string) (SearchResults, error) {
+	if sectionKey == "" || character == "" {
+		return SearchResults{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/firstCharacter/%s", p.ServerURL(), sectionKey, url.PathEscape(character))
+
+	return getContainer[SearchMediaContainer](p, query)
+}
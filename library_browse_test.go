@@ -0,0 +1,117 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newBrowseTestServer(t *testing.T, responses map[string]string) *Plex {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	return p
+}
+
+func TestPlex_GetSecondaryDirectories(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/library/sections/1/genre":    `{"MediaContainer":{"size":2,"Directory":[{"fastKey":"/library/sections/1/all?genre=10","key":"10","title":"Action"},{"key":"11","title":"Drama"}]}}`,
+		"/library/sections/1/genre/10": `{"MediaContainer":{"size":1,"Metadata":[{"ratingKey":"100","title":"Die Hard"}]}}`,
+	})
+
+	genres, err := p.GetSecondaryDirectories("1", BrowseGenre)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(genres.MediaContainer.Directory) != 2 || genres.MediaContainer.Directory[0].Title != "Action" {
+		t.Fatalf("unexpected genres: %+v", genres.MediaContainer.Directory)
+	}
+
+	items, err := p.GetSecondaryDirectoryItems("1", BrowseGenre, genres.MediaContainer.Directory[0].Key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(items.MediaContainer.Metadata) != 1 || items.MediaContainer.Metadata[0].Title != "Die Hard" {
+		t.Errorf("unexpected items: %+v", items.MediaContainer.Metadata)
+	}
+
+	if _, err := p.GetSecondaryDirectories("1", BrowseDecade); err == nil {
+		t.Error("expected error for unknown directory")
+	}
+
+	if _, err := p.GetSecondaryDirectoryItems("1", BrowseGenre, ""); err == nil {
+		t.Error("expected error for empty key")
+	}
+}
+
+func TestPlex_GetLibraryFolders(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/library/sections/2/folder": `{"MediaContainer":{"size":1,"Directory":[{"key":"/library/sections/2/folder?parent=7","title":"TV"}]}}`,
+	})
+
+	folders, err := p.GetLibraryFolders("2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(folders.MediaContainer.Directory) != 1 || folders.MediaContainer.Directory[0].Title != "TV" {
+		t.Fatalf("unexpected folders: %+v", folders.MediaContainer.Directory)
+	}
+
+	contents, err := p.GetFolderContents(folders.MediaContainer.Directory[0].Key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if contents.MediaContainer.Size != 1 {
+		t.Errorf("expected the folder key to be requested as is, got %+v", contents.MediaContainer)
+	}
+
+	if _, err := p.GetFolderContents("http://evil.example/"); err == nil {
+		t.Error("expected error for a key outside of the library")
+	}
+}
+
+func TestPlex_GetFirstCharacters(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/library/sections/1/firstCharacter":   `{"MediaContainer":{"size":2,"Directory":[{"key":"#","size":3,"title":"#"},{"key":"A","size":12,"title":"A"}]}}`,
+		"/library/sections/1/firstCharacter/#": `{"MediaContainer":{"size":1,"Metadata":[{"ratingKey":"5","title":"2001: A Space Odyssey"}]}}`,
+	})
+
+	index, err := p.GetFirstCharacters("1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(index.MediaContainer.Directory) != 2 || index.MediaContainer.Directory[1].Size != 12 {
+		t.Fatalf("unexpected index: %+v", index.MediaContainer.Directory)
+	}
+
+	items, err := p.GetItemsByFirstCharacter("1", "#")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(items.MediaContainer.Metadata) != 1 {
+		t.Errorf("expected # to be escaped in the path, got %+v", items.MediaContainer)
+	}
+}
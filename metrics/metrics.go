@@ -0,0 +1,210 @@
+// Package metrics exposes Prometheus metrics for requests made by a plex client.
+//
+//	collector := metrics.NewCollector("plex")
+//	prometheus.MustRegister(collector)
+//
+//	client, err := plex.New(url, token, metrics.WithCollector(collector))
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/timothystewart6/go-plex-client"
+)
+
+// Collector records request count, latency and errors per endpoint. It implements
+// prometheus.Collector and can be shared by several clients.
+type Collector struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+// NewCollector creates a collector whose metrics are prefixed with namespace.
+func NewCollector(namespace string) *Collector {
+	labels := []string{"method", "endpoint"}
+
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of requests sent, by method, endpoint and status code.",
+		}, []string{"method", "endpoint", "code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_errors_total",
+			Help:      "Number of requests that failed or returned a status code of 400 or above.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Request latency in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.latency.Collect(ch)
+}
+
+// Instrument wraps next so every round trip is recorded. A nil next uses http.DefaultTransport.
+func (c *Collector) Instrument(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &roundTripper{collector: c, next: next}
+}
+
+// WithCollector instruments the api and download clients of a plex client. Pass it after
+// options that replace the transport, such as WithHTTPClient. Options modifying the transport,
+// such as WithProxy or WithInsecureSkipVerify, can come before or after it.
+func WithCollector(c *Collector) plex.Option {
+	return func(p *plex.Plex) {
+		p.HTTPClient.Transport = c.Instrument(p.HTTPClient.Transport)
+		p.DownloadClient.Transport = c.Instrument(p.DownloadClient.Transport)
+	}
+}
+
+var _ plex.TransportWrapper = (*roundTripper)(nil)
+
+type roundTripper struct {
+	collector *Collector
+	next      http.RoundTripper
+}
+
+// Unwrap returns the instrumented round tripper, see plex.TransportWrapper
+func (rt *roundTripper) Unwrap() http.RoundTripper {
+	return rt.next
+}
+
+// Wrap instruments next with the same collector, see plex.TransportWrapper
+func (rt *roundTripper) Wrap(next http.RoundTripper) http.RoundTripper {
+	return &roundTripper{collector: rt.collector, next: next}
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req)
+
+	endpoint := Endpoint(req.URL.Path)
+	rt.collector.latency.WithLabelValues(req.Method, endpoint).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		rt.collector.requests.WithLabelValues(req.Method, endpoint, "error").Inc()
+		rt.collector.errors.WithLabelValues(req.Method, endpoint).Inc()
+
+		return resp, err
+	}
+
+	rt.collector.requests.WithLabelValues(req.Method, endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		rt.collector.errors.WithLabelValues(req.Method, endpoint).Inc()
+	}
+
+	return resp, nil
+}
+
+// Endpoint collapses ids in a request path so label cardinality stays bounded,
+// e.g. /library/metadata/123/children becomes /library/metadata/:id/children and
+// /library/sections/1/firstCharacter/A becomes /library/sections/:id/firstCharacter/:id.
+func Endpoint(path string) string {
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		if isID(segment) || (followsID(segments, i) && !isWord(segment)) {
+			segments[i] = ":id"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// idParents are segments followed by an id, e.g. /library/metadata/{id}
+var idParents = map[string]bool{
+	"metadata":    true,
+	"sections":    true,
+	"parts":       true,
+	"streams":     true,
+	"sessions":    true,
+	"playlists":   true,
+	"collections": true,
+	"playQueues":  true,
+	"devices":     true,
+	"accounts":    true,
+	"users":       true,
+}
+
+// keyParents are segments followed by an id and then a key, e.g. the secondary
+// directories of /library/sections/{id}/{directory}/{key} or the file name of
+// /library/parts/{id}/{updatedAt}/{file}
+var keyParents = map[string]bool{
+	"sections": true,
+	"parts":    true,
+}
+
+// followsID reports whether segment i of segments is at the position of an id or key
+func followsID(segments []string, i int) bool {
+	return (i >= 1 && idParents[segments[i-1]]) || (i >= 3 && keyParents[segments[i-3]])
+}
+
+// isWord reports whether a segment is a camelCase word, i.e. an endpoint name such as
+// "all" or "firstCharacter" rather than a key
+func isWord(segment string) bool {
+	if segment == "" {
+		return true
+	}
+
+	if segment[0] < 'a' || segment[0] > 'z' {
+		return false
+	}
+
+	for _, r := range segment {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isID reports whether a path segment looks like a rating key, session id, uuid or a
+// comma separated list of those.
+func isID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+
+	if strings.Contains(segment, ",") {
+		for _, part := range strings.Split(segment, ",") {
+			if !isID(part) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if _, err := strconv.ParseInt(segment, 10, 64); err == nil {
+		return true
+	}
+
+	// machine identifiers, transcode sessions and uuids are long and contain digits
+	return len(segment) >= 16 && strings.ContainsAny(segment, "0123456789")
+}
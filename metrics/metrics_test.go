@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/timothystewart6/go-plex-client"
+)
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/library/sections", "/library/sections"},
+		{"/library/metadata/123/children", "/library/metadata/:id/children"},
+		{"/transcode/sessions/abc123def456ghi789", "/transcode/sessions/:id"},
+		{"/status/sessions", "/status/sessions"},
+		{"/status/sessions/history/all", "/status/sessions/history/all"},
+		{"/library/metadata/1,2,3", "/library/metadata/:id"},
+		{"/library/metadata/12,34/children", "/library/metadata/:id/children"},
+		{"/library/sections/1/all", "/library/sections/:id/all"},
+		{"/library/sections/1/firstCharacter/A", "/library/sections/:id/firstCharacter/:id"},
+		{"/library/sections/1/firstCharacter/%23", "/library/sections/:id/firstCharacter/:id"},
+		{"/library/sections/2/genre/Action", "/library/sections/:id/genre/:id"},
+		{"/library/parts/123/1700000000/file.mkv", "/library/parts/:id/:id/:id"},
+		{"/playlists/Xyz/items", "/playlists/:id/items"},
+	}
+
+	for _, test := range tests {
+		if got := Endpoint(test.path); got != test.expected {
+			t.Errorf("Endpoint(%q) = %q; want %q", test.path, got, test.expected)
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/library/metadata/404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	collector := NewCollector("plex")
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("unexpected error registering collector: %v", err)
+	}
+
+	p, err := plex.New(server.URL, "token", WithCollector(collector))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	for _, path := range []string{"/library/metadata/1", "/library/metadata/2", "/library/metadata/404"} {
+		resp, err := p.HTTPClient.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if got := testutil.ToFloat64(collector.requests.WithLabelValues("GET", "/library/metadata/:id", "200")); got != 2 {
+		t.Errorf("expected 2 successful requests, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(collector.errors.WithLabelValues("GET", "/library/metadata/:id")); got != 1 {
+		t.Errorf("expected 1 error, got %v", got)
+	}
+
+	if got := testutil.CollectAndCount(collector, "plex_request_duration_seconds"); got != 1 {
+		t.Errorf("expected 1 latency series, got %d", got)
+	}
+}
+
+// Test the proxy applies to requests and websockets whether it is set before or after the collector
+func TestWithCollector_Proxy(t *testing.T) {
+	var requests, connects atomic.Int32
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			connects.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	for name, opts := range map[string][]plex.Option{
+		"collector first": {WithCollector(NewCollector("plex")), plex.WithProxy(proxyURL)},
+		"proxy first":     {plex.WithProxy(proxyURL), WithCollector(NewCollector("plex"))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			requests.Store(0)
+			connects.Store(0)
+
+			p, err := plex.New("http://plex.invalid:32400", "token", opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from New: %v", err)
+			}
+
+			if _, ok := p.HTTPClient.Transport.(*roundTripper); !ok {
+				t.Fatalf("expected the transport to stay instrumented, got %T", p.HTTPClient.Transport)
+			}
+
+			resp, err := p.HTTPClient.Get("http://plex.invalid:32400/identity")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if requests.Load() != 1 {
+				t.Errorf("expected the request to go through the proxy")
+			}
+
+			if _, err := p.Subscribe(context.Background(), plex.NewNotificationEvents(), nil); err == nil {
+				t.Fatal("expected the proxy to refuse the websocket")
+			}
+
+			if connects.Load() != 1 {
+				t.Errorf("expected the websocket to go through the proxy")
+			}
+		})
+	}
+}
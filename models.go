@@ -1,10 +1,12 @@
 package plex
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -24,15 +26,81 @@ func (f *FlexibleInt64) UnmarshalJSON(b []byte) error {
 
 func (f FlexibleInt64) Int64() int64 { return int64(f) }
 
+// RatingKey identifies a piece of media on a plex server.
+// Plex encodes it as a quoted string in most payloads and as a number in others.
+type RatingKey string
+
+func (k *RatingKey) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*k = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*k = RatingKey(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("invalid rating key: %s", string(b))
+	}
+
+	*k = RatingKey(n.String())
+
+	return nil
+}
+
+// String returns the rating key as used in plex urls, e.g. /library/metadata/<key>
+func (k RatingKey) String() string { return string(k) }
+
+// Int64 returns the numeric value of the rating key
+func (k RatingKey) Int64() (int64, error) { return strconv.ParseInt(string(k), 10, 64) }
+
+// RequestHook inspects or mutates a request before it is sent
+type RequestHook func(req *http.Request)
+
+// ResponseHook observes the outcome of a request
+type ResponseHook func(resp *http.Response, err error)
+
+// TokenProvider supplies the token of each request, e.g. to rotate tokens, switch
+// between Plex Home users or read the token from a secret manager
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenProviderFunc adapts a function to a TokenProvider
+type TokenProviderFunc func(ctx context.Context) (string, error)
+
+// Token calls f
+func (f TokenProviderFunc) Token(ctx context.Context) (string, error) { return f(ctx) }
+
 // Plex contains fields that are required to make
 // an api call to your plex server
 type Plex struct {
-	URL              string
-	Token            string
+	URL   string
+	Token string
+	// PlexTVURL is the base url used for plex.tv requests. If empty, https://plex.tv is used.
+	PlexTVURL        string
 	ClientIdentifier string
 	Headers          headers
 	HTTPClient       http.Client
 	DownloadClient   http.Client
+	// ExtraHeaders are added to every request made to the server or plex.tv.
+	ExtraHeaders http.Header
+	// Logger receives debug logs for every request. Requests are not logged when nil.
+	Logger Logger
+	// BeforeRequest hooks run in order right before each request is sent and may mutate it.
+	BeforeRequest []RequestHook
+	// AfterResponse hooks run in order after each request completes. resp is nil when err is not.
+	AfterResponse []ResponseHook
+	// TokenProvider, when set, is asked for the token of every request instead of using Token.
+	TokenProvider TokenProvider
+
+	cache    *responseCache
+	etags    *etagCache
+	failover *failover
 	// WebsocketDialer controls websocket connections created by SubscribeToNotifications.
 	// If nil, the package uses websocket.DefaultDialer.
 	WebsocketDialer *websocket.Dialer
@@ -48,6 +116,13 @@ type Provider struct {
 	Type  string `json:"type"`
 }
 
+// Container is the MediaContainer envelope around every Plex Media Server json response.
+// T describes the contents, so a new endpoint only needs a type for its container,
+// e.g. Container[MediaContainer] for endpoints returning metadata.
+type Container[T any] struct {
+	MediaContainer T `json:"MediaContainer"`
+}
+
 // SearchMediaContainer ...
 type SearchMediaContainer struct {
 	MediaContainer
@@ -55,57 +130,122 @@ type SearchMediaContainer struct {
 }
 
 // SearchResults ...
-type SearchResults struct {
-	MediaContainer SearchMediaContainer `json:"MediaContainer"`
-}
+type SearchResults = Container[SearchMediaContainer]
 
 // Metadata ...
 type Metadata struct {
-	Player                Player        `json:"Player"`
-	Session               Session       `json:"Session"`
-	User                  User          `json:"User"`
-	AddedAt               int           `json:"addedAt"`
-	Art                   string        `json:"art"`
-	ContentRating         string        `json:"contentRating"`
-	Duration              int           `json:"duration"`
-	Genres                []Genre       `json:"Genre"`
-	GrandparentArt        string        `json:"grandparentArt"`
-	GrandparentKey        string        `json:"grandparentKey"`
-	GrandparentRatingKey  string        `json:"grandparentRatingKey"`
-	GrandparentTheme      string        `json:"grandparentTheme"`
-	GrandparentThumb      string        `json:"grandparentThumb"`
-	GrandparentTitle      string        `json:"grandparentTitle"`
-	GUID                  string        `json:"guid"`
-	AltGUIDs              []AltGUID     `json:"Guid"`
-	Index                 int64         `json:"index"`
-	Key                   string        `json:"key"`
-	LastViewedAt          int           `json:"lastViewedAt"`
-	LibrarySectionID      FlexibleInt64 `json:"librarySectionID"`
-	LibrarySectionKey     string        `json:"librarySectionKey"`
-	LibrarySectionTitle   string        `json:"librarySectionTitle"`
-	OriginallyAvailableAt string        `json:"originallyAvailableAt"`
-	ParentIndex           int64         `json:"parentIndex"`
-	ParentKey             string        `json:"parentKey"`
-	ParentRatingKey       string        `json:"parentRatingKey"`
-	ParentThumb           string        `json:"parentThumb"`
-	ParentTitle           string        `json:"parentTitle"`
-	RatingCount           int           `json:"ratingCount"`
-	Rating                float64       `json:"rating"`
-	Ratings               []Rating      `json:"Rating"`
-	RatingKey             string        `json:"ratingKey"`
-	SessionKey            string        `json:"sessionKey"`
-	Summary               string        `json:"summary"`
-	Thumb                 string        `json:"thumb"`
-	Media                 []Media       `json:"Media"`
-	Title                 string        `json:"title"`
-	TitleSort             string        `json:"titleSort"`
-	Type                  string        `json:"type"`
-	UpdatedAt             int           `json:"updatedAt"`
-	ViewCount             FlexibleInt64 `json:"viewCount"`
-	ViewOffset            int           `json:"viewOffset"`
-	Year                  int           `json:"year"`
-	Director              []TaggedData  `json:"Director"`
-	Writer                []TaggedData  `json:"Writer"`
+	Player                Player            `json:"Player"`
+	Session               Session           `json:"Session"`
+	TranscodeSession      *TranscodeSession `json:"TranscodeSession"`
+	User                  User              `json:"User"`
+	AddedAt               int               `json:"addedAt"`
+	Art                   string            `json:"art"`
+	ContentRating         string            `json:"contentRating"`
+	Duration              int               `json:"duration"`
+	Genres                []Genre           `json:"Genre"`
+	GrandparentArt        string            `json:"grandparentArt"`
+	GrandparentKey        string            `json:"grandparentKey"`
+	GrandparentRatingKey  string            `json:"grandparentRatingKey"`
+	GrandparentTheme      string            `json:"grandparentTheme"`
+	GrandparentThumb      string            `json:"grandparentThumb"`
+	GrandparentTitle      string            `json:"grandparentTitle"`
+	GUID                  string            `json:"guid"`
+	AltGUIDs              []AltGUID         `json:"Guid"`
+	Index                 int64             `json:"index"`
+	Key                   string            `json:"key"`
+	LastViewedAt          int               `json:"lastViewedAt"`
+	LibrarySectionID      FlexibleInt64     `json:"librarySectionID"`
+	LibrarySectionKey     string            `json:"librarySectionKey"`
+	LibrarySectionTitle   string            `json:"librarySectionTitle"`
+	OriginallyAvailableAt string            `json:"originallyAvailableAt"`
+	ParentIndex           int64             `json:"parentIndex"`
+	ParentKey             string            `json:"parentKey"`
+	ParentRatingKey       string            `json:"parentRatingKey"`
+	ParentThumb           string            `json:"parentThumb"`
+	ParentTitle           string            `json:"parentTitle"`
+	RatingCount           int               `json:"ratingCount"`
+	Rating                float64           `json:"rating"`
+	Ratings               []Rating          `json:"Rating"`
+	RatingKey             string            `json:"ratingKey"`
+	SessionKey            string            `json:"sessionKey"`
+	Summary               string            `json:"summary"`
+	Thumb                 string            `json:"thumb"`
+	Media                 []Media           `json:"Media"`
+	Title                 string            `json:"title"`
+	TitleSort             string            `json:"titleSort"`
+	Type                  string            `json:"type"`
+	UpdatedAt             int               `json:"updatedAt"`
+	ViewCount             FlexibleInt64     `json:"viewCount"`
+	ViewOffset            int               `json:"viewOffset"`
+	Year                  int               `json:"year"`
+	Director              []TaggedData      `json:"Director"`
+	Writer                []TaggedData      `json:"Writer"`
+	Markers               []Marker          `json:"Marker"`
+	Chapters              []Chapter         `json:"Chapter"`
+	Extras                Extras            `json:"Extras"`
+	Reviews               []Review          `json:"Review"`
+	Related               Related           `json:"Related"`
+}
+
+// MetadataOptions selects the optional data plex only returns when asked for, see GetMetadataWithOptions
+type MetadataOptions struct {
+	IncludeGuids    bool // external ids (imdb, tmdb, tvdb) in AltGUIDs
+	IncludeMarkers  bool // intro and credits markers
+	IncludeChapters bool
+	IncludeExtras   bool // trailers and other extras
+	IncludeReviews  bool
+	IncludeRelated  bool // hubs of related items
+	CheckFiles      bool // sets Exists and Accessible on each part
+}
+
+// Marker is an intro or credits marker of an episode or movie, offsets are in milliseconds
+type Marker struct {
+	ID              int64  `json:"id"`
+	Type            string `json:"type"`
+	StartTimeOffset int64  `json:"startTimeOffset"`
+	EndTimeOffset   int64  `json:"endTimeOffset"`
+}
+
+// Chapter of a media item, offsets are in milliseconds
+type Chapter struct {
+	ID              int64  `json:"id"`
+	Index           int64  `json:"index"`
+	Tag             string `json:"tag"`
+	Thumb           string `json:"thumb"`
+	StartTimeOffset int64  `json:"startTimeOffset"`
+	EndTimeOffset   int64  `json:"endTimeOffset"`
+}
+
+// Extras are the trailers, featurettes and other extras of an item
+type Extras struct {
+	Size     int        `json:"size"`
+	Metadata []Metadata `json:"Metadata"`
+}
+
+// Review is a critic review of an item
+type Review struct {
+	ID     int64  `json:"id"`
+	Tag    string `json:"tag"`
+	Text   string `json:"text"`
+	Image  string `json:"image"`
+	Link   string `json:"link"`
+	Source string `json:"source"`
+}
+
+// Related contains hubs of items related to an item
+type Related struct {
+	Hub []Hub `json:"Hub"`
+}
+
+// Hub is a titled list of items, e.g. "More Movies by Christopher Nolan"
+type Hub struct {
+	HubIdentifier string     `json:"hubIdentifier"`
+	Key           string     `json:"key"`
+	Title         string     `json:"title"`
+	Type          string     `json:"type"`
+	Size          int        `json:"size"`
+	More          bool       `json:"more"`
+	Metadata      []Metadata `json:"Metadata"`
 }
 
 // AltGUID represents a Globally Unique Identifier for a metadata provider that is not actively being used.
@@ -176,13 +316,13 @@ type MediaContainer struct {
 	LibrarySectionUUID  string     `json:"librarySectionUUID"`
 	MediaTagPrefix      string     `json:"mediaTagPrefix"`
 	MediaTagVersion     int        `json:"mediaTagVersion"`
+	Offset              int        `json:"offset"`
 	Size                int        `json:"size"`
+	TotalSize           int        `json:"totalSize"`
 }
 
 // MediaMetadata ...
-type MediaMetadata struct {
-	MediaContainer MediaContainer `json:"MediaContainer"`
-}
+type MediaMetadata = Container[MediaContainer]
 
 // Location is the path of a plex server directory
 type Location struct {
@@ -234,78 +374,59 @@ type Role struct {
 }
 
 // MetadataChildren returns metadata about a piece of media (tv show, movie, music, etc)
-type MetadataChildren struct {
-	MediaContainer MediaContainer `json:"MediaContainer"`
-}
+type MetadataChildren = Container[MediaContainer]
 
 // SearchResultsEpisode contains metadata about an episode
-type SearchResultsEpisode struct {
-	MediaContainer MediaContainer `json:"MediaContainer"`
-}
+type SearchResultsEpisode = Container[MediaContainer]
 
 //nolint:unused
 type plexResponse struct {
-	Children []struct {
-		ElementType string `json:"_elementType"`
-		Count       string `json:"count"`
-		Key         string `json:"key"`
-		Title       string `json:"title"`
-	} `json:"_children"`
-	ElementType                   string `json:"_elementType"`
-	AllowCameraUpload             string `json:"allowCameraUpload"`
-	AllowChannelAccess            string `json:"allowChannelAccess"`
-	AllowSync                     string `json:"allowSync"`
-	BackgroundProcessing          string `json:"backgroundProcessing"`
-	Certificate                   string `json:"certificate"`
-	CompanionProxy                string `json:"companionProxy"`
-	FriendlyName                  string `json:"friendlyName"`
-	HubSearch                     string `json:"hubSearch"`
-	MachineIdentifier             string `json:"machineIdentifier"`
-	Multiuser                     string `json:"multiuser"`
-	MyPlex                        string `json:"myPlex"`
-	MyPlexMappingState            string `json:"myPlexMappingState"`
-	MyPlexSigninState             string `json:"myPlexSigninState"`
-	MyPlexSubscription            string `json:"myPlexSubscription"`
-	MyPlexUsername                string `json:"myPlexUsername"`
-	Platform                      string `json:"platform"`
-	PlatformVersion               string `json:"platformVersion"`
-	RequestParametersInCookie     string `json:"requestParametersInCookie"`
-	Sync                          string `json:"sync"`
-	TranscoderActiveVideoSessions string `json:"transcoderActiveVideoSessions"`
-	TranscoderAudio               string `json:"transcoderAudio"`
-	TranscoderLyrics              string `json:"transcoderLyrics"`
-	TranscoderPhoto               string `json:"transcoderPhoto"`
-	TranscoderSubtitles           string `json:"transcoderSubtitles"`
-	TranscoderVideo               string `json:"transcoderVideo"`
-	TranscoderVideoBitrates       string `json:"transcoderVideoBitrates"`
-	TranscoderVideoQualities      string `json:"transcoderVideoQualities"`
-	TranscoderVideoResolutions    string `json:"transcoderVideoResolutions"`
-	UpdatedAt                     string `json:"updatedAt"`
-	Version                       string `json:"version"`
+	Children                      []plexResponseChild `json:"_children"`
+	ElementType                   string              `json:"_elementType"`
+	AllowCameraUpload             string              `json:"allowCameraUpload"`
+	AllowChannelAccess            string              `json:"allowChannelAccess"`
+	AllowSync                     string              `json:"allowSync"`
+	BackgroundProcessing          string              `json:"backgroundProcessing"`
+	Certificate                   string              `json:"certificate"`
+	CompanionProxy                string              `json:"companionProxy"`
+	FriendlyName                  string              `json:"friendlyName"`
+	HubSearch                     string              `json:"hubSearch"`
+	MachineIdentifier             string              `json:"machineIdentifier"`
+	Multiuser                     string              `json:"multiuser"`
+	MyPlex                        string              `json:"myPlex"`
+	MyPlexMappingState            string              `json:"myPlexMappingState"`
+	MyPlexSigninState             string              `json:"myPlexSigninState"`
+	MyPlexSubscription            string              `json:"myPlexSubscription"`
+	MyPlexUsername                string              `json:"myPlexUsername"`
+	Platform                      string              `json:"platform"`
+	PlatformVersion               string              `json:"platformVersion"`
+	RequestParametersInCookie     string              `json:"requestParametersInCookie"`
+	Sync                          string              `json:"sync"`
+	TranscoderActiveVideoSessions string              `json:"transcoderActiveVideoSessions"`
+	TranscoderAudio               string              `json:"transcoderAudio"`
+	TranscoderLyrics              string              `json:"transcoderLyrics"`
+	TranscoderPhoto               string              `json:"transcoderPhoto"`
+	TranscoderSubtitles           string              `json:"transcoderSubtitles"`
+	TranscoderVideo               string              `json:"transcoderVideo"`
+	TranscoderVideoBitrates       string              `json:"transcoderVideoBitrates"`
+	TranscoderVideoQualities      string              `json:"transcoderVideoQualities"`
+	TranscoderVideoResolutions    string              `json:"transcoderVideoResolutions"`
+	UpdatedAt                     string              `json:"updatedAt"`
+	Version                       string              `json:"version"`
 }
 
 //nolint:unused
-type killTranscodeResponse struct {
-	Children []struct {
-		ElementType   string  `json:"_elementType"`
-		AudioChannels int     `json:"audioChannels"`
-		AudioCodec    string  `json:"audioCodec"`
-		AudioDecision string  `json:"audioDecision"`
-		Container     string  `json:"container"`
-		Context       string  `json:"context"`
-		Duration      int     `json:"duration"`
-		Height        int     `json:"height"`
-		Key           string  `json:"key"`
-		Progress      float64 `json:"progress"`
-		Protocol      string  `json:"protocol"`
-		Remaining     int     `json:"remaining"`
-		Speed         float64 `json:"speed"`
-		Throttled     bool    `json:"throttled"`
-		VideoCodec    string  `json:"videoCodec"`
-		VideoDecision string  `json:"videoDecision"`
-		Width         int     `json:"width"`
-	} `json:"_children"`
+type plexResponseChild struct {
 	ElementType string `json:"_elementType"`
+	Count       string `json:"count"`
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+}
+
+//nolint:unused
+type killTranscodeResponse struct {
+	Children    []TranscodeSession `json:"_children"`
+	ElementType string             `json:"_elementType"`
 }
 
 // CreateLibraryParams params required to create a library
@@ -318,45 +439,67 @@ type CreateLibraryParams struct {
 	Language    string
 }
 
+// UpdateLibraryParams are the changes made by UpdateLibrary. Empty fields are left unchanged.
+type UpdateLibraryParams struct {
+	Name     string
+	Agent    string
+	Scanner  string
+	Language string
+	// Prefs are section settings such as "enableBIFGeneration", sent as prefs[name]=value
+	Prefs map[string]string
+}
+
 // DevicesResponse  metadata of a device that has connected to your server
 type DevicesResponse struct {
-	ID         int    `json:"id"`
-	LastSeenAt string `json:"lastSeenAt"`
-	Name       string `json:"name"`
-	Product    string `json:"product"`
-	Version    string `json:"version"`
+	ID               int          `json:"id"`
+	Name             string       `json:"name"`
+	Product          string       `json:"product"`
+	Version          string       `json:"version"`
+	Platform         string       `json:"platform"`
+	PlatformVersion  string       `json:"platformVersion"`
+	Device           string       `json:"device"`
+	ClientIdentifier string       `json:"clientIdentifier"`
+	Token            string       `json:"token"`
+	Provides         string       `json:"provides"`
+	PublicAddress    string       `json:"publicAddress"`
+	CreatedAt        string       `json:"createdAt"`
+	LastSeenAt       string       `json:"lastSeenAt"`
+	Connections      []Connection `json:"connections"`
 }
 
 // Friends are the plex accounts that have access to your server
 type Friends struct {
-	ID                        int    `xml:"id,attr"`
-	Title                     string `xml:"title,attr"`
-	Thumb                     string `xml:"thumb,attr"`
-	Protected                 string `xml:"protected,attr"`
-	Home                      string `xml:"home,attr"`
-	AllowSync                 string `xml:"allowSync,attr"`
-	AllowCameraUpload         string `xml:"allowCameraUpload,attr"`
-	AllowChannels             string `xml:"allowChannels,attr"`
-	FilterAll                 string `xml:"filterAll,attr"`
-	FilterMovies              string `xml:"filterMovies,attr"`
-	FilterMusic               string `xml:"filterMusic,attr"`
-	FilterPhotos              string `xml:"filterPhotos,attr"`
-	FilterTelevision          string `xml:"filterTelevision,attr"`
-	Restricted                string `xml:"restricted,attr"`
-	Username                  string `xml:"username,attr"`
-	Email                     string `xml:"email,attr"`
-	RecommendationsPlaylistID string `xml:"recommendationsPlaylistId,attr"`
-	Server                    struct {
-		ID                string `xml:"id,attr"`
-		ServerID          string `xml:"serverId,attr"`
-		MachineIdentifier string `xml:"machineIdentifier,attr"`
-		Name              string `xml:"name,attr"`
-		LastSeenAt        string `xml:"lastSeenAt,attr"`
-		NumLibraries      string `xml:"numLibraries,attr"`
-		AllLibraries      string `xml:"allLibraries,attr"`
-		Owned             string `xml:"owned,attr"`
-		Pending           string `xml:"pending,attr"`
-	} `xml:"Server"`
+	ID                        int          `xml:"id,attr"`
+	Title                     string       `xml:"title,attr"`
+	Thumb                     string       `xml:"thumb,attr"`
+	Protected                 string       `xml:"protected,attr"`
+	Home                      string       `xml:"home,attr"`
+	AllowSync                 string       `xml:"allowSync,attr"`
+	AllowCameraUpload         string       `xml:"allowCameraUpload,attr"`
+	AllowChannels             string       `xml:"allowChannels,attr"`
+	FilterAll                 string       `xml:"filterAll,attr"`
+	FilterMovies              string       `xml:"filterMovies,attr"`
+	FilterMusic               string       `xml:"filterMusic,attr"`
+	FilterPhotos              string       `xml:"filterPhotos,attr"`
+	FilterTelevision          string       `xml:"filterTelevision,attr"`
+	Restricted                string       `xml:"restricted,attr"`
+	Username                  string       `xml:"username,attr"`
+	Email                     string       `xml:"email,attr"`
+	RecommendationsPlaylistID string       `xml:"recommendationsPlaylistId,attr"`
+	Server                    FriendServer `xml:"Server"`
+}
+
+// FriendServer is your server as shared with one of your Friends
+type FriendServer struct {
+	ID                string `xml:"id,attr"`
+	ServerID          string `xml:"serverId,attr"`
+	MachineIdentifier string `xml:"machineIdentifier,attr"`
+	Name              string `xml:"name,attr"`
+	LastSeenAt        string `xml:"lastSeenAt,attr"`
+	NumLibraries      string `xml:"numLibraries,attr"`
+	AllLibraries      string `xml:"allLibraries,attr"`
+	Owned             string `xml:"owned,attr"`
+	Pending           string `xml:"pending,attr"`
 }
 
 type friendsResponse struct {
@@ -377,71 +520,110 @@ type resultResponse struct {
 	} `xml:"Response"`
 }
 
-type inviteFriendResponse struct {
-	ID                int64  `json:"id"`
-	Name              string `json:"name"`
-	OwnerID           int64  `json:"ownerId"`
-	InvitedID         int64  `json:"invitedId"`
-	InvitedEmail      string `json:"invitedEmail"`
-	ServerID          int64  `json:"serverId"`
-	Accepted          bool   `json:"accepted"`
-	AcceptedAt        string `json:"acceptedAt"`
-	DeletedAt         string `json:"deletedAt"`
-	LeftAt            string `json:"leftAt"`
-	Owned             bool   `json:"owned"`
-	InviteToken       string `json:"inviteToken"`
-	MachineIdentifier string `json:"machineIdentifier"`
+// SharedServer is your server shared with a friend, InviteFriend returns it for the invite
+// it created. Invited.Status is "pending" until the friend accepts.
+type SharedServer struct {
+	ID                int64         `json:"id"`
+	Name              string        `json:"name"`
+	OwnerID           int64         `json:"ownerId"`
+	InvitedID         int64         `json:"invitedId"`
+	InvitedEmail      string        `json:"invitedEmail"`
+	ServerID          int64         `json:"serverId"`
+	Accepted          bool          `json:"accepted"`
+	InvitedAt         FlexibleInt64 `json:"invitedAt"`
+	AcceptedAt        string        `json:"acceptedAt"`
+	DeletedAt         string        `json:"deletedAt"`
+	LeftAt            string        `json:"leftAt"`
+	Owned             bool          `json:"owned"`
+	InviteToken       string        `json:"inviteToken"`
+	MachineIdentifier string        `json:"machineIdentifier"`
 	LastSeenAt        time.Time
-	NumLibraries      int64 `json:"numLibraries"`
-	Invited           struct {
-		ID         int64  `json:"id"`
-		UUID       string `json:"uuid"`
-		Title      string `json:"title"`
-		Username   string `json:"username"`
-		Restricted bool   `json:"restricted"`
-		Thumb      string `json:"thumb"`
-		Status     string `json:"status"`
-	} `json:"invited"`
-	SharingSettings struct {
-		AllowChannels      bool        `json:"allowChannels"`
-		FilterMovies       string      `json:"filterMovies"`
-		FilterMusic        string      `json:"filterMusic"`
-		FilterPhotos       string      `json:"filterPhotos"`
-		FilterTelevision   string      `json:"filterTelevision"`
-		FilterAll          interface{} `json:"filterAll"`
-		AllowSync          bool        `json:"allowSync"`
-		AllowCameraUpload  bool        `json:"allowCameraUpload"`
-		AllowSubtitleAdmin bool        `json:"allowSubtitleAdmin"`
-		AllowTuners        int64       `json:"allowTuners"`
-	} `json:"sharingSettings"`
-	Libraries []struct {
-		ID    int64  `json:"id"`
-		Key   int64  `json:"key"`
-		Title string `json:"title"`
-		Type  string `json:"type"`
-	} `json:"libraries"`
-	AllLibraries bool `json:"allLibraries"`
-}
-
-// UnmarshalJSON for inviteFriendResponse parses flexible numeric fields.
-func (i *inviteFriendResponse) UnmarshalJSON(b []byte) error {
-	type alias inviteFriendResponse
+	NumLibraries      int64                 `json:"numLibraries"`
+	Invited           SharedServerUser      `json:"invited"`
+	SharingSettings   SharingSettings       `json:"sharingSettings"`
+	Libraries         []SharedServerLibrary `json:"libraries"`
+	AllLibraries      bool                  `json:"allLibraries"`
+}
+
+// PendingInvite returns the invite the friend still has to accept, as GetInvitedFriends
+// lists it, so it can be cancelled with CancelInvite. ok is false once accepted.
+// Friends invited by an email without a plex account are identified by that email.
+func (i SharedServer) PendingInvite() (invite InvitedFriend, ok bool) {
+	if i.Accepted {
+		return invite, false
+	}
+
+	invite = InvitedFriend{
+		ID:           i.InvitedEmail,
+		IsServer:     true,
+		Username:     i.Invited.Username,
+		Email:        i.InvitedEmail,
+		Thumb:        i.Invited.Thumb,
+		FriendlyName: i.Invited.Title,
+	}
+
+	if id := i.InvitedID; id > 0 {
+		invite.ID = strconv.FormatInt(id, 10)
+	} else if id := i.Invited.ID; id > 0 {
+		invite.ID = strconv.FormatInt(id, 10)
+	}
+
+	if i.InvitedAt > 0 {
+		invite.CreatedAt = strconv.FormatInt(i.InvitedAt.Int64(), 10)
+	}
+
+	if invite.FriendlyName == "" {
+		invite.FriendlyName = i.InvitedEmail
+	}
+
+	invite.Server.Name = i.Name
+	invite.Server.NumLibraries = strconv.FormatInt(i.NumLibraries, 10)
+
+	return invite, true
+}
+
+// SharedServerUser is the friend a server is shared with
+type SharedServerUser struct {
+	ID         int64  `json:"id"`
+	UUID       string `json:"uuid"`
+	Title      string `json:"title"`
+	Username   string `json:"username"`
+	Restricted bool   `json:"restricted"`
+	Thumb      string `json:"thumb"`
+	Status     string `json:"status"`
+}
+
+// SharingSettings are the restrictions of a friend on a shared server
+type SharingSettings struct {
+	AllowChannels      bool        `json:"allowChannels"`
+	FilterMovies       string      `json:"filterMovies"`
+	FilterMusic        string      `json:"filterMusic"`
+	FilterPhotos       string      `json:"filterPhotos"`
+	FilterTelevision   string      `json:"filterTelevision"`
+	FilterAll          interface{} `json:"filterAll"`
+	AllowSync          bool        `json:"allowSync"`
+	AllowCameraUpload  bool        `json:"allowCameraUpload"`
+	AllowSubtitleAdmin bool        `json:"allowSubtitleAdmin"`
+	AllowTuners        int64       `json:"allowTuners"`
+}
+
+// SharedServerLibrary is a library shared by an invite
+type SharedServerLibrary struct {
+	ID    int64  `json:"id"`
+	Key   int64  `json:"key"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+}
+
+// UnmarshalJSON for SharedServer parses flexible numeric fields.
+func (i *SharedServer) UnmarshalJSON(b []byte) error {
+	type alias SharedServer
 	var aux struct {
 		ID           json.RawMessage `json:"id"`
 		OwnerID      json.RawMessage `json:"ownerId"`
 		InvitedID    json.RawMessage `json:"invitedId"`
 		ServerID     json.RawMessage `json:"serverId"`
 		NumLibraries json.RawMessage `json:"numLibraries"`
-		Invited      struct {
-			ID json.RawMessage `json:"id"`
-		} `json:"invited"`
-		SharingSettings struct {
-			AllowTuners json.RawMessage `json:"allowTuners"`
-		} `json:"sharingSettings"`
-		Libraries []struct {
-			ID  json.RawMessage `json:"id"`
-			Key json.RawMessage `json:"key"`
-		} `json:"libraries"`
 		alias
 	}
 
@@ -449,7 +631,7 @@ func (i *inviteFriendResponse) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	*i = inviteFriendResponse(aux.alias)
+	*i = SharedServer(aux.alias)
 
 	if v, err := parseFlexibleInt64(aux.ID); err == nil {
 		i.ID = v
@@ -476,53 +658,99 @@ func (i *inviteFriendResponse) UnmarshalJSON(b []byte) error {
 	} else {
 		return fmt.Errorf("invalid numLibraries: %w", err)
 	}
-	if v, err := parseFlexibleInt64(aux.Invited.ID); err == nil {
-		i.Invited.ID = v
+
+	return nil
+}
+
+// UnmarshalJSON for SharedServerUser parses a numeric-or-string id.
+func (u *SharedServerUser) UnmarshalJSON(b []byte) error {
+	type alias SharedServerUser
+	var aux struct {
+		ID json.RawMessage `json:"id"`
+		alias
+	}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	*u = SharedServerUser(aux.alias)
+
+	if v, err := parseFlexibleInt64(aux.ID); err == nil {
+		u.ID = v
 	} else {
 		return fmt.Errorf("invalid invited.id: %w", err)
 	}
-	if v, err := parseFlexibleInt64(aux.SharingSettings.AllowTuners); err == nil {
-		i.SharingSettings.AllowTuners = v
+
+	return nil
+}
+
+// UnmarshalJSON for SharingSettings parses a numeric-or-string allowTuners.
+func (s *SharingSettings) UnmarshalJSON(b []byte) error {
+	type alias SharingSettings
+	var aux struct {
+		AllowTuners json.RawMessage `json:"allowTuners"`
+		alias
+	}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	*s = SharingSettings(aux.alias)
+
+	if v, err := parseFlexibleInt64(aux.AllowTuners); err == nil {
+		s.AllowTuners = v
 	} else {
 		return fmt.Errorf("invalid allowTuners: %w", err)
 	}
 
-	// libraries: ensure we have a slice to populate even if alias unmarshalling didn't fill it
-	if len(i.Libraries) < len(aux.Libraries) {
-		// create a slice with the correct element shape
-		i.Libraries = make([]struct {
-			ID    int64  `json:"id"`
-			Key   int64  `json:"key"`
-			Title string `json:"title"`
-			Type  string `json:"type"`
-		}, len(aux.Libraries))
+	return nil
+}
+
+// UnmarshalJSON for SharedServerLibrary parses a numeric-or-string id and key.
+func (l *SharedServerLibrary) UnmarshalJSON(b []byte) error {
+	type alias SharedServerLibrary
+	var aux struct {
+		ID  json.RawMessage `json:"id"`
+		Key json.RawMessage `json:"key"`
+		alias
+	}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
 	}
 
-	for idx, lib := range aux.Libraries {
-		if idx >= len(i.Libraries) {
-			break
-		}
-		if v, err := parseFlexibleInt64(lib.ID); err == nil {
-			i.Libraries[idx].ID = v
-		} else {
-			return fmt.Errorf("invalid library id: %w", err)
-		}
-		if v, err := parseFlexibleInt64(lib.Key); err == nil {
-			i.Libraries[idx].Key = v
-		} else {
-			return fmt.Errorf("invalid library key: %w", err)
-		}
+	*l = SharedServerLibrary(aux.alias)
+
+	if v, err := parseFlexibleInt64(aux.ID); err == nil {
+		l.ID = v
+	} else {
+		return fmt.Errorf("invalid library id: %w", err)
+	}
+	if v, err := parseFlexibleInt64(aux.Key); err == nil {
+		l.Key = v
+	} else {
+		return fmt.Errorf("invalid library key: %w", err)
 	}
 
 	return nil
 }
 
-// InviteFriendParams are the params to invite a friend
+// InviteFriendParams are the params to invite a friend. The filters restrict what the
+// friend sees in each type of library, e.g. "label=kids" or "contentRating=G".
 type InviteFriendParams struct {
-	UsernameOrEmail string
-	MachineID       string
-	Label           string
-	LibraryIDs      []int
+	UsernameOrEmail   string
+	MachineID         string
+	Label             string // shorthand for a label filter on movies and tv shows
+	LibraryIDs        []int
+	AllowSync         bool
+	AllowCameraUpload bool
+	AllowChannels     bool
+	FilterMovies      string
+	FilterTelevision  string
+	FilterMusic       string
+	FilterPhotos      string
 }
 
 // UpdateFriendParams optional parameters to update your friends access to your server
@@ -544,9 +772,11 @@ type inviteFriendBody struct {
 
 type inviteFriendSettings struct {
 	AllowCameraUpload string `json:"allowCameraUpload"`
+	AllowChannels     string `json:"allowChannels"`
 	AllowSync         string `json:"allowSync"`
 	FilterMovies      string `json:"filterMovies"`
 	FilterMusic       string `json:"filterMusic"`
+	FilterPhotos      string `json:"filterPhotos"`
 	FilterTelevision  string `json:"filterTelevision"`
 }
 
@@ -560,19 +790,22 @@ type invitedFriendsResponse struct {
 }
 
 type InvitedFriend struct {
-	ID           string `xml:"id,attr"`
-	CreatedAt    string `xml:"createdAt,attr"`
-	IsFriend     bool   `xml:"friend,attr"`
-	IsHome       bool   `xml:"home,attr"`
-	IsServer     bool   `xml:"server,attr"`
-	Username     string `xml:"username,attr"`
-	Email        string `xml:"email,attr"`
-	Thumb        string `xml:"thumb,attr"`
-	FriendlyName string `xml:"friendlyName,attr"`
-	Server       struct {
-		Name         string `xml:"name,attr"`
-		NumLibraries string `xml:"numLibraries,attr"`
-	} `xml:"Server"`
+	ID           string              `xml:"id,attr"`
+	CreatedAt    string              `xml:"createdAt,attr"`
+	IsFriend     bool                `xml:"friend,attr"`
+	IsHome       bool                `xml:"home,attr"`
+	IsServer     bool                `xml:"server,attr"`
+	Username     string              `xml:"username,attr"`
+	Email        string              `xml:"email,attr"`
+	Thumb        string              `xml:"thumb,attr"`
+	FriendlyName string              `xml:"friendlyName,attr"`
+	Server       InvitedFriendServer `xml:"Server"`
+}
+
+// InvitedFriendServer is the server an InvitedFriend is invited to
+type InvitedFriendServer struct {
+	Name         string `xml:"name,attr"`
+	NumLibraries string `xml:"numLibraries,attr"`
 }
 
 type resourcesResponse struct {
@@ -617,173 +850,199 @@ type Connection struct {
 	Port     string `json:"port" xml:"port,attr"`
 	URI      string `json:"uri" xml:"uri,attr"`
 	Local    int    `json:"local" xml:"local,attr"`
+	Relay    bool   `json:"relay" xml:"relay,attr"`
 }
 
 // BaseAPIResponse info about the Plex Media Server
 type BaseAPIResponse struct {
 	MediaContainer struct {
-		Directory []struct {
-			Count int64  `json:"count"`
-			Key   string `json:"key"`
-			Title string `json:"title"`
-		} `json:"Directory"`
-		AllowCameraUpload             bool   `json:"allowCameraUpload"`
-		AllowChannelAccess            bool   `json:"allowChannelAccess"`
-		AllowSharing                  bool   `json:"allowSharing"`
-		AllowSync                     bool   `json:"allowSync"`
-		BackgroundProcessing          bool   `json:"backgroundProcessing"`
-		Certificate                   bool   `json:"certificate"`
-		CompanionProxy                bool   `json:"companionProxy"`
-		CountryCode                   string `json:"countryCode"`
-		Diagnostics                   string `json:"diagnostics"`
-		EventStream                   bool   `json:"eventStream"`
-		FriendlyName                  string `json:"friendlyName"`
-		HubSearch                     bool   `json:"hubSearch"`
-		ItemClusters                  bool   `json:"itemClusters"`
-		Livetv                        int64  `json:"livetv"`
-		MachineIdentifier             string `json:"machineIdentifier"`
-		MediaProviders                bool   `json:"mediaProviders"`
-		Multiuser                     bool   `json:"multiuser"`
-		MyPlex                        bool   `json:"myPlex"`
-		MyPlexMappingState            string `json:"myPlexMappingState"`
-		MyPlexSigninState             string `json:"myPlexSigninState"`
-		MyPlexSubscription            bool   `json:"myPlexSubscription"`
-		MyPlexUsername                string `json:"myPlexUsername"`
-		OwnerFeatures                 string `json:"ownerFeatures"`
-		PhotoAutoTag                  bool   `json:"photoAutoTag"`
-		Platform                      string `json:"platform"`
-		PlatformVersion               string `json:"platformVersion"`
-		PluginHost                    bool   `json:"pluginHost"`
-		ReadOnlyLibraries             bool   `json:"readOnlyLibraries"`
-		RequestParametersInCookie     bool   `json:"requestParametersInCookie"`
-		Size                          int64  `json:"size"`
-		StreamingBrainABRVersion      int64  `json:"streamingBrainABRVersion"`
-		StreamingBrainVersion         int64  `json:"streamingBrainVersion"`
-		Sync                          bool   `json:"sync"`
-		TranscoderActiveVideoSessions int64  `json:"transcoderActiveVideoSessions"`
-		TranscoderAudio               bool   `json:"transcoderAudio"`
-		TranscoderLyrics              bool   `json:"transcoderLyrics"`
-		TranscoderPhoto               bool   `json:"transcoderPhoto"`
-		TranscoderSubtitles           bool   `json:"transcoderSubtitles"`
-		TranscoderVideo               bool   `json:"transcoderVideo"`
-		TranscoderVideoBitrates       string `json:"transcoderVideoBitrates"`
-		TranscoderVideoQualities      string `json:"transcoderVideoQualities"`
-		TranscoderVideoResolutions    string `json:"transcoderVideoResolutions"`
-		UpdatedAt                     int64  `json:"updatedAt"`
-		Updater                       bool   `json:"updater"`
-		Version                       string `json:"version"`
-		VoiceSearch                   bool   `json:"voiceSearch"`
+		Directory                     []ServerDirectory `json:"Directory"`
+		AllowCameraUpload             bool              `json:"allowCameraUpload"`
+		AllowChannelAccess            bool              `json:"allowChannelAccess"`
+		AllowSharing                  bool              `json:"allowSharing"`
+		AllowSync                     bool              `json:"allowSync"`
+		BackgroundProcessing          bool              `json:"backgroundProcessing"`
+		Certificate                   bool              `json:"certificate"`
+		CompanionProxy                bool              `json:"companionProxy"`
+		CountryCode                   string            `json:"countryCode"`
+		Diagnostics                   string            `json:"diagnostics"`
+		EventStream                   bool              `json:"eventStream"`
+		FriendlyName                  string            `json:"friendlyName"`
+		HubSearch                     bool              `json:"hubSearch"`
+		ItemClusters                  bool              `json:"itemClusters"`
+		Livetv                        int64             `json:"livetv"`
+		MachineIdentifier             string            `json:"machineIdentifier"`
+		MediaProviders                bool              `json:"mediaProviders"`
+		Multiuser                     bool              `json:"multiuser"`
+		MyPlex                        bool              `json:"myPlex"`
+		MyPlexMappingState            string            `json:"myPlexMappingState"`
+		MyPlexSigninState             string            `json:"myPlexSigninState"`
+		MyPlexSubscription            bool              `json:"myPlexSubscription"`
+		MyPlexUsername                string            `json:"myPlexUsername"`
+		OwnerFeatures                 string            `json:"ownerFeatures"`
+		PhotoAutoTag                  bool              `json:"photoAutoTag"`
+		Platform                      string            `json:"platform"`
+		PlatformVersion               string            `json:"platformVersion"`
+		PluginHost                    bool              `json:"pluginHost"`
+		ReadOnlyLibraries             bool              `json:"readOnlyLibraries"`
+		RequestParametersInCookie     bool              `json:"requestParametersInCookie"`
+		Size                          int64             `json:"size"`
+		StreamingBrainABRVersion      int64             `json:"streamingBrainABRVersion"`
+		StreamingBrainVersion         int64             `json:"streamingBrainVersion"`
+		Sync                          bool              `json:"sync"`
+		TranscoderActiveVideoSessions int64             `json:"transcoderActiveVideoSessions"`
+		TranscoderAudio               bool              `json:"transcoderAudio"`
+		TranscoderLyrics              bool              `json:"transcoderLyrics"`
+		TranscoderPhoto               bool              `json:"transcoderPhoto"`
+		TranscoderSubtitles           bool              `json:"transcoderSubtitles"`
+		TranscoderVideo               bool              `json:"transcoderVideo"`
+		TranscoderVideoBitrates       string            `json:"transcoderVideoBitrates"`
+		TranscoderVideoQualities      string            `json:"transcoderVideoQualities"`
+		TranscoderVideoResolutions    string            `json:"transcoderVideoResolutions"`
+		UpdatedAt                     int64             `json:"updatedAt"`
+		Updater                       bool              `json:"updater"`
+		Version                       string            `json:"version"`
+		VoiceSearch                   bool              `json:"voiceSearch"`
 	} `json:"MediaContainer"`
 }
 
+// ServerDirectory is an endpoint listed at the root of the Plex Media Server
+type ServerDirectory struct {
+	Count int64  `json:"count"`
+	Key   string `json:"key"`
+	Title string `json:"title"`
+}
+
 // UserPlexTV plex.tv user. should be used when interacting with plex.tv as the id is an int
 type UserPlexTV struct {
 	// ID is an int when signing in to Plex.tv but a string when access own server
-	ID                int    `json:"id"`
-	UUID              string `json:"uuid"`
-	Email             string `json:"email"`
-	FriendlyName      string `json:"friendlyName"`
-	Locale            string `json:"locale"` // can be null
-	Confirmed         bool   `json:"confirmed"`
-	EmailOnlyAuth     bool   `json:"emailOnlyAuth"`
-	Protected         bool   `json:"protected"`
-	MailingListStatus string `json:"mailingListStatus"`
-	MailingListActive bool   `json:"mailingListActive"`
-	ScrobbleTypes     string `json:"scrobbleTypes"`
-	Country           string `json:"country"`
-	JoinedAt          string `json:"joined_at"`
-	Username          string `json:"username"`
-	Thumb             string `json:"thumb"`
-	HasPassword       bool   `json:"hasPassword"`
-	AuthToken         string `json:"authToken"`
-	// AuthenticationToken string `json:"authenticationToken"`
-	Subscription struct {
-		Active         bool     `json:"active"`
-		Status         string   `json:"Active"`
-		Plan           string   `json:"lifetime"`       // can be null
-		SubscribedAt   string   `json:"subscribedAt"`   // can be null
-		PaymentService string   `json:"paymentService"` // can be null
-		Features       []string `json:"features"`
-	} `json:"subscription"`
-	SubscriptionDescription string `json:"subscriptionDescription"` // can be null
-	Restricted              bool   `json:"restricted"`
-	Anonymous               string `json:"anonymous"` // can be null
-	Home                    bool   `json:"home"`
-	Guest                   bool   `json:"guest"`
-	HomeSize                int64  `json:"homeSize"` // type may be wrong
-	HomeAdmin               bool   `json:"homeAdmin"`
-	MaxHomeSize             int64  `json:"maxHomeSize"` // type may be wrong
-	CertificateVersion      int64  `json:"certificateVersion"`
-	RememberExpiresAt       int64  `json:"rememberExpiresAt"`
-	Profile                 struct {
-		AutoSelectAudio              bool   `json:"autoSelectAudio"`
-		DefaultAudioLanguage         string `json:"defaultAudioLanguage"`
-		DefaultSubtitleLanguage      string `json:"defaultSubtitleLanguage"`
-		AutoSelectSubtitle           int64  `json:"autoSelectSubtitle"`
-		DefaultSubtitleAccessibility int64  `json:"defaultSubtitleAccessibility"`
-		DefaultSubtitleForced        int64  `json:"defaultSubtitleForced"`
-	} `json:"profile"`
-	Subscriptions []struct {
-		ID       int64  `json:"id"`
-		Mode     string `json:"mode"`
-		RenewsAt string `json:"renewsAt"` // can be null; not sure of type as I have lifetime membership
-		EndsAt   string `json:"endsAt"`   // can be null; not sure of type as I have lifetime membership
-		Type     string `json:"type"`
-		Transfer string `json:"transfer"` // can be null; not sure of type
-		State    string `json:"state"`
-	} `json:"subscriptions"`
-	PastSubscriptions    []string   `json:"pastSubscriptions"`
-	Trials               []string   `json:"trials"`
-	Services             []Services `json:"services"`
-	AdsConsent           string     `json:"adsConsent"`           // can be null
-	AdsConsentSetAt      string     `json:"adsConsentSetAt"`      // can be null
-	AdsConsentReminderAt string     `json:"adsConsentReminderAt"` // can be null
-	ExperimentalFeatures bool       `json:"experimentalFeatures"`
-	TwoFactorEnabled     bool       `json:"twoFactorEnabled"`
-	BackupCodesCreated   bool       `json:"backupCodesCreated"`
-	// Roles                struct {
-	// 	Roles []string `json:"roles"`
-	// } `json:"roles"`
-	Entitlements []string `json:"entitlements"`
-	// ConfirmedAt  string      `json:"confirmedAt"`
-	// ForumID    json.Number `json:"forumId"`
-	// RememberMe bool   `json:"rememberMe"`
-	Title string `json:"title"`
+	ID                      int                  `json:"id"`
+	UUID                    string               `json:"uuid"`
+	Email                   string               `json:"email"`
+	FriendlyName            string               `json:"friendlyName"`
+	Locale                  string               `json:"locale"` // can be null
+	Confirmed               bool                 `json:"confirmed"`
+	EmailOnlyAuth           bool                 `json:"emailOnlyAuth"`
+	Protected               bool                 `json:"protected"`
+	MailingListStatus       string               `json:"mailingListStatus"`
+	MailingListActive       bool                 `json:"mailingListActive"`
+	ScrobbleTypes           string               `json:"scrobbleTypes"`
+	Country                 string               `json:"country"`
+	JoinedAt                int64                `json:"joinedAt"`
+	Username                string               `json:"username"`
+	Thumb                   string               `json:"thumb"`
+	HasPassword             bool                 `json:"hasPassword"`
+	AuthToken               string               `json:"authToken"`
+	Subscription            PlexPassSubscription `json:"subscription"`
+	SubscriptionDescription string               `json:"subscriptionDescription"` // can be null
+	Restricted              bool                 `json:"restricted"`
+	Anonymous               bool                 `json:"anonymous"` // can be null
+	Home                    bool                 `json:"home"`
+	Guest                   bool                 `json:"guest"`
+	HomeSize                int64                `json:"homeSize"`
+	HomeAdmin               bool                 `json:"homeAdmin"`
+	MaxHomeSize             int64                `json:"maxHomeSize"`
+	CertificateVersion      int64                `json:"certificateVersion"`
+	RememberExpiresAt       int64                `json:"rememberExpiresAt"`
+	Profile                 UserProfile          `json:"profile"`
+	Subscriptions           []UserSubscription   `json:"subscriptions"`
+	PastSubscriptions       []UserSubscription   `json:"pastSubscriptions"`
+	Trials                  []json.RawMessage    `json:"trials"` // undocumented, kept as sent
+	Services                []Services           `json:"services"`
+	AdsConsent              bool                 `json:"adsConsent"`           // can be null
+	AdsConsentSetAt         int64                `json:"adsConsentSetAt"`      // can be null
+	AdsConsentReminderAt    int64                `json:"adsConsentReminderAt"` // can be null
+	ExperimentalFeatures    bool                 `json:"experimentalFeatures"`
+	TwoFactorEnabled        bool                 `json:"twoFactorEnabled"`
+	BackupCodesCreated      bool                 `json:"backupCodesCreated"`
+	AttributionPartner      string               `json:"attributionPartner"` // can be null
+	Roles                   []string             `json:"roles"`
+	Entitlements            []string             `json:"entitlements"`
+	Title                   string               `json:"title"`
 }
 
+// PlexPassSubscription is the current plex pass subscription of a plex.tv account
+type PlexPassSubscription struct {
+	Active         bool     `json:"active"`
+	Status         string   `json:"status"`
+	Plan           string   `json:"plan"`           // can be null, e.g. "lifetime" or "monthly"
+	SubscribedAt   string   `json:"subscribedAt"`   // can be null
+	PaymentService string   `json:"paymentService"` // can be null
+	Features       []string `json:"features"`
+}
+
+// UserProfile holds the playback preferences of a plex.tv account
+type UserProfile struct {
+	AutoSelectAudio              bool   `json:"autoSelectAudio"`
+	DefaultAudioLanguage         string `json:"defaultAudioLanguage"`
+	DefaultSubtitleLanguage      string `json:"defaultSubtitleLanguage"`
+	AutoSelectSubtitle           int64  `json:"autoSelectSubtitle"`
+	DefaultSubtitleAccessibility int64  `json:"defaultSubtitleAccessibility"`
+	DefaultSubtitleForced        int64  `json:"defaultSubtitleForced"`
+	WatchedIndicator             int64  `json:"watchedIndicator"`
+	MediaReviewsVisibility       int64  `json:"mediaReviewsVisibility"`
+}
+
+// UserSubscription is a subscription of a plex.tv account, current or past
+type UserSubscription struct {
+	ID            int64  `json:"id"`
+	Mode          string `json:"mode"`
+	RenewsAt      int64  `json:"renewsAt"` // can be null
+	EndsAt        int64  `json:"endsAt"`   // can be null
+	Canceled      bool   `json:"canceled"`
+	GracePeriod   bool   `json:"gracePeriod"`
+	OnHold        bool   `json:"onHold"`
+	CanReactivate bool   `json:"canReactivate"`
+	CanUpgrade    bool   `json:"canUpgrade"`
+	CanDowngrade  bool   `json:"canDowngrade"`
+	CanConvert    bool   `json:"canConvert"`
+	Type          string `json:"type"`
+	Transfer      string `json:"transfer"` // can be null
+	State         string `json:"state"`
+}
+
+// Services are the plex.tv services an account can use, e.g. metadata or epg providers,
+// with the token to authenticate to them
 type Services struct {
 	Identifier string `json:"identifier"`
 	Endpoint   string `json:"endpoint"`
 	Token      string `json:"token"`
+	Secret     string `json:"secret"` // can be null
 	Status     string `json:"status"`
 }
 
 // User plex server user. only difference is id is a string
 type User struct {
 	// ID is an int when signing in to Plex.tv but a string when access own server
-	ID                  string `json:"id"`
-	UUID                string `json:"uuid"`
-	Email               string `json:"email"`
-	JoinedAt            string `json:"joined_at"`
-	Username            string `json:"username"`
-	Thumb               string `json:"thumb"`
-	HasPassword         bool   `json:"hasPassword"`
-	AuthToken           string `json:"authToken"`
-	AuthenticationToken string `json:"authenticationToken"`
-	Subscription        struct {
-		Active   bool     `json:"active"`
-		Status   string   `json:"Active"`
-		Plan     string   `json:"lifetime"`
-		Features []string `json:"features"`
-	} `json:"subscription"`
-	Roles struct {
-		Roles []string `json:"roles"`
-	} `json:"roles"`
-	Entitlements []string `json:"entitlements"`
-	ConfirmedAt  string   `json:"confirmedAt"`
-	ForumID      string   `json:"forumId"`
-	RememberMe   bool     `json:"rememberMe"`
-	Title        string   `json:"title"`
+	ID                  string              `json:"id"`
+	UUID                string              `json:"uuid"`
+	Email               string              `json:"email"`
+	JoinedAt            string              `json:"joined_at"`
+	Username            string              `json:"username"`
+	Thumb               string              `json:"thumb"`
+	HasPassword         bool                `json:"hasPassword"`
+	AuthToken           string              `json:"authToken"`
+	AuthenticationToken string              `json:"authenticationToken"`
+	Subscription        AccountSubscription `json:"subscription"`
+	Roles               AccountRoles        `json:"roles"`
+	Entitlements        []string            `json:"entitlements"`
+	ConfirmedAt         string              `json:"confirmedAt"`
+	ForumID             string              `json:"forumId"`
+	RememberMe          bool                `json:"rememberMe"`
+	Title               string              `json:"title"`
+}
+
+// AccountSubscription is the plex pass subscription of a User
+type AccountSubscription struct {
+	Active   bool     `json:"active"`
+	Status   string   `json:"Active"`
+	Plan     string   `json:"lifetime"`
+	Features []string `json:"features"`
+}
+
+// AccountRoles are the roles of a User
+type AccountRoles struct {
+	Roles []string `json:"roles"`
 }
 
 // SignInResponse response from plex.tv sign in
@@ -791,51 +1050,57 @@ type SignInResponse UserPlexTV
 
 // ServerInfo is the result of the https://plex.tv/api/servers endpoint
 type ServerInfo struct {
-	XMLName           xml.Name `xml:"MediaContainer"`
-	FriendlyName      string   `xml:"friendlyName,attr"`
-	Identifier        string   `xml:"identifier,attr"`
-	MachineIdentifier string   `xml:"machineIdentifier,attr"`
-	Size              int      `xml:"size,attr"`
-	Server            []struct {
-		AccessToken       string `xml:"accessToken,attr"`
-		Name              string `xml:"name,attr"`
-		Address           string `xml:"address,attr"`
-		Port              string `xml:"port,attr"`
-		Version           string `xml:"version,attr"`
-		Scheme            string `xml:"scheme,attr"`
-		Host              string `xml:"host,attr"`
-		LocalAddresses    string `xml:"localAddresses,attr"`
-		MachineIdentifier string `xml:"machineIdentifier,attr"`
-		CreatedAt         string `xml:"createdAt,attr"`
-		UpdatedAt         string `xml:"updatedAt,attr"`
-		Owned             string `xml:"owned,attr"`
-		Synced            string `xml:"synced,attr"`
-	} `xml:"Server"`
+	XMLName           xml.Name           `xml:"MediaContainer"`
+	FriendlyName      string             `xml:"friendlyName,attr"`
+	Identifier        string             `xml:"identifier,attr"`
+	MachineIdentifier string             `xml:"machineIdentifier,attr"`
+	Size              int                `xml:"size,attr"`
+	Server            []ServerInfoServer `xml:"Server"`
+}
+
+// ServerInfoServer is a server listed by the https://plex.tv/api/servers endpoint
+type ServerInfoServer struct {
+	AccessToken       string `xml:"accessToken,attr"`
+	Name              string `xml:"name,attr"`
+	Address           string `xml:"address,attr"`
+	Port              string `xml:"port,attr"`
+	Version           string `xml:"version,attr"`
+	Scheme            string `xml:"scheme,attr"`
+	Host              string `xml:"host,attr"`
+	LocalAddresses    string `xml:"localAddresses,attr"`
+	MachineIdentifier string `xml:"machineIdentifier,attr"`
+	CreatedAt         string `xml:"createdAt,attr"`
+	UpdatedAt         string `xml:"updatedAt,attr"`
+	Owned             string `xml:"owned,attr"`
+	Synced            string `xml:"synced,attr"`
 }
 
 // SectionIDResponse the section id (or library id) of your server
 // useful when inviting a user to the server
 type SectionIDResponse struct {
-	XMLName           xml.Name `xml:"MediaContainer"`
-	FriendlyName      string   `xml:"friendlyName,attr"`
-	Identifier        string   `xml:"identifier,attr"`
-	MachineIdentifier string   `xml:"machineIdentifier,attr"`
-	Size              int      `xml:"size,attr"`
-	Server            []struct {
-		Name              string           `xml:"name,attr"`
-		Address           string           `xml:"address,attr"`
-		Port              string           `xml:"port,attr"`
-		Version           string           `xml:"version,attr"`
-		Scheme            string           `xml:"scheme,attr"`
-		Host              string           `xml:"host,attr"`
-		LocalAddresses    string           `xml:"localAddresses,attr"`
-		MachineIdentifier string           `xml:"machineIdentifier,attr"`
-		CreatedAt         int              `xml:"createdAt,attr"`
-		UpdatedAt         int              `xml:"updatedAt,attr"`
-		Owned             int              `xml:"owned,attr"`
-		Synced            string           `xml:"synced,attr"`
-		Section           []ServerSections `xml:"Section"`
-	} `xml:"Server"`
+	XMLName           xml.Name          `xml:"MediaContainer"`
+	FriendlyName      string            `xml:"friendlyName,attr"`
+	Identifier        string            `xml:"identifier,attr"`
+	MachineIdentifier string            `xml:"machineIdentifier,attr"`
+	Size              int               `xml:"size,attr"`
+	Server            []SectionIDServer `xml:"Server"`
+}
+
+// SectionIDServer is a server and its library sections in a SectionIDResponse
+type SectionIDServer struct {
+	Name              string           `xml:"name,attr"`
+	Address           string           `xml:"address,attr"`
+	Port              string           `xml:"port,attr"`
+	Version           string           `xml:"version,attr"`
+	Scheme            string           `xml:"scheme,attr"`
+	Host              string           `xml:"host,attr"`
+	LocalAddresses    string           `xml:"localAddresses,attr"`
+	MachineIdentifier string           `xml:"machineIdentifier,attr"`
+	CreatedAt         int              `xml:"createdAt,attr"`
+	UpdatedAt         int              `xml:"updatedAt,attr"`
+	Owned             int              `xml:"owned,attr"`
+	Synced            string           `xml:"synced,attr"`
+	Section           []ServerSections `xml:"Section"`
 }
 
 // ServerSections contains information of your library sections
@@ -848,24 +1113,27 @@ type ServerSections struct {
 
 // LibraryLabels are the existing labels set on your server
 type LibraryLabels struct {
-	ElementType     string `json:"_elementType"`
-	AllowSync       string `json:"allowSync"`
-	Art             string `json:"art"`
-	Content         string `json:"content"`
-	Identifier      string `json:"identifier"`
-	MediaTagPrefix  string `json:"mediaTagPrefix"`
-	MediaTagVersion string `json:"mediaTagVersion"`
-	Thumb           string `json:"thumb"`
-	Title1          string `json:"title1"`
-	Title2          string `json:"title2"`
-	ViewGroup       string `json:"viewGroup"`
-	ViewMode        string `json:"viewMode"`
-	Children        []struct {
-		ElementType string `json:"_elementType"`
-		FastKey     string `json:"fastKey"`
-		Key         string `json:"key"`
-		Title       string `json:"title"`
-	} `json:"_children"`
+	ElementType     string         `json:"_elementType"`
+	AllowSync       string         `json:"allowSync"`
+	Art             string         `json:"art"`
+	Content         string         `json:"content"`
+	Identifier      string         `json:"identifier"`
+	MediaTagPrefix  string         `json:"mediaTagPrefix"`
+	MediaTagVersion string         `json:"mediaTagVersion"`
+	Thumb           string         `json:"thumb"`
+	Title1          string         `json:"title1"`
+	Title2          string         `json:"title2"`
+	ViewGroup       string         `json:"viewGroup"`
+	ViewMode        string         `json:"viewMode"`
+	Children        []LibraryLabel `json:"_children"`
+}
+
+// LibraryLabel is a label set on your server
+type LibraryLabel struct {
+	ElementType string `json:"_elementType"`
+	FastKey     string `json:"fastKey"`
+	Key         string `json:"key"`
+	Title       string `json:"title"`
 }
 
 type headers struct {
@@ -892,30 +1160,21 @@ type request struct {
 // Sessions
 
 // TranscodeSessionsResponse is the result for transcode session endpoint /transcode/sessions
-type TranscodeSessionsResponse struct {
-	Children []struct {
-		ElementType      string  `json:"_elementType"`
-		AudioChannels    int     `json:"audioChannels"`
-		AudioCodec       string  `json:"audioCodec"`
-		AudioDecision    string  `json:"audioDecision"`
-		SubtitleDecision string  `json:"subtitleDecision"`
-		Container        string  `json:"container"`
-		Context          string  `json:"context"`
-		Duration         int     `json:"duration"`
-		Height           int     `json:"height"`
-		Key              string  `json:"key"`
-		Progress         float64 `json:"progress"`
-		Protocol         string  `json:"protocol"`
-		Remaining        int     `json:"remaining"`
-		Speed            float64 `json:"speed"`
-		Throttled        bool    `json:"throttled"`
-		VideoCodec       string  `json:"videoCodec"`
-		VideoDecision    string  `json:"videoDecision"`
-		Width            int     `json:"width"`
-	} `json:"_children"`
-	ElementType string `json:"_elementType"`
+type TranscodeSessionsResponse = Container[TranscodeSessionsContainer]
+
+// TranscodeSessionsContainer lists the active transcode sessions
+type TranscodeSessionsContainer struct {
+	Size             int                `json:"size"`
+	TranscodeSession []TranscodeSession `json:"TranscodeSession"`
 }
 
+// Stream types of Stream.StreamType
+const (
+	StreamTypeVideo    = 1
+	StreamTypeAudio    = 2
+	StreamTypeSubtitle = 3
+)
+
 // Stream ...
 type Stream struct {
 	AlbumGain          string        `json:"albumGain"`
@@ -937,13 +1196,17 @@ type Stream struct {
 	Default            bool          `json:"default"`
 	DisplayTitle       string        `json:"displayTitle"`
 	Duration           string        `json:"duration"`
+	Forced             bool          `json:"forced"`
+	Format             string        `json:"format"`
 	FrameRate          float64       `json:"frameRate"`
 	FrameRateMode      string        `json:"frameRateMode"`
 	Gain               string        `json:"gain"`
 	HasScalingMatrix   bool          `json:"hasScalingMatrix"`
+	HearingImpaired    bool          `json:"hearingImpaired"`
 	Height             int           `json:"height"`
 	ID                 FlexibleInt64 `json:"id"`
 	Index              int           `json:"index"`
+	Key                string        `json:"key"` // only set for external subtitles, which can be downloaded
 	Language           string        `json:"language"`
 	LanguageCode       string        `json:"languageCode"`
 	Level              int           `json:"level"`
@@ -960,6 +1223,7 @@ type Stream struct {
 	Selected           bool          `json:"selected"`
 	StreamIdentifier   string        `json:"streamIdentifier"`
 	StreamType         int           `json:"streamType"`
+	Title              string        `json:"title"`
 	Width              int           `json:"width"`
 }
 
@@ -979,6 +1243,8 @@ type Part struct {
 	Size                  int           `json:"size"`
 	Stream                []Stream      `json:"Stream"`
 	VideoProfile          string        `json:"videoProfile"`
+	Exists                bool          `json:"exists"`     // only set with MetadataOptions.CheckFiles
+	Accessible            bool          `json:"accessible"` // only set with MetadataOptions.CheckFiles
 }
 
 // Player ...
@@ -1028,3 +1294,501 @@ type Genre struct {
 	ID     FlexibleInt64 `json:"id"`
 	Tag    string        `json:"tag"`
 }
+
+// SecondaryDirectory is an entry of a library section's secondary directory, e.g. a genre or a year
+type SecondaryDirectory struct {
+	FastKey string `json:"fastKey"`
+	Key     string `json:"key"`
+	Title   string `json:"title"`
+	Type    string `json:"type"`
+}
+
+// SecondaryDirectoryContainer lists the entries of a secondary directory
+type SecondaryDirectoryContainer struct {
+	Directory           []SecondaryDirectory `json:"Directory"`
+	LibrarySectionID    int                  `json:"librarySectionID"`
+	LibrarySectionTitle string               `json:"librarySectionTitle"`
+	Size                int                  `json:"size"`
+	Title1              string               `json:"title1"`
+	Title2              string               `json:"title2"`
+}
+
+// SecondaryDirectories is the response of GetSecondaryDirectories
+type SecondaryDirectories = Container[SecondaryDirectoryContainer]
+
+// Folder is a directory on disk inside a library section
+type Folder struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+}
+
+// FolderContainer lists the sub folders and media items of a folder
+type FolderContainer struct {
+	Directory           []Folder   `json:"Directory"`
+	Metadata            []Metadata `json:"Metadata"`
+	LibrarySectionID    int        `json:"librarySectionID"`
+	LibrarySectionTitle string     `json:"librarySectionTitle"`
+	Size                int        `json:"size"`
+	Title1              string     `json:"title1"`
+	Title2              string     `json:"title2"`
+}
+
+// LibraryFolders is the response of GetLibraryFolders and GetFolderContents
+type LibraryFolders = Container[FolderContainer]
+
+// FirstCharacter is an entry of a section's A-Z index with the number of items starting with it
+type FirstCharacter struct {
+	Key   string `json:"key"`
+	Size  int    `json:"size"`
+	Title string `json:"title"`
+}
+
+// FirstCharacterContainer lists the first characters of the titles in a section
+type FirstCharacterContainer struct {
+	Directory []FirstCharacter `json:"Directory"`
+	Size      int              `json:"size"`
+}
+
+// FirstCharacters is the response of GetFirstCharacters
+type FirstCharacters = Container[FirstCharacterContainer]
+
+// LibraryPrefsContainer holds the settings of a library section
+type LibraryPrefsContainer struct {
+	Setting []Setting `json:"Setting"`
+	Size    int       `json:"size"`
+}
+
+// LibraryPrefs is the response of GetLibraryPrefs
+type LibraryPrefs = Container[LibraryPrefsContainer]
+
+// Agent is a metadata agent installed on the server
+type Agent struct {
+	HasAttribution bool            `json:"hasAttribution"`
+	HasPrefs       bool            `json:"hasPrefs"`
+	Identifier     string          `json:"identifier"`
+	Language       []AgentLanguage `json:"Language"`
+	Name           string          `json:"name"`
+	Primary        bool            `json:"primary"`
+}
+
+// AgentLanguage is a metadata language supported by an agent
+type AgentLanguage struct {
+	Code  string `json:"code"`
+	Title string `json:"title"`
+}
+
+// AgentContainer lists the agents installed on the server
+type AgentContainer struct {
+	Agent []Agent `json:"Agent"`
+	Size  int     `json:"size"`
+}
+
+// Agents is the response of GetAgents
+type Agents = Container[AgentContainer]
+
+// Scanner is a library scanner installed on the server
+type Scanner struct {
+	Name string `json:"name"`
+}
+
+// ScannerContainer lists the scanners installed on the server
+type ScannerContainer struct {
+	Scanner []Scanner `json:"Scanner"`
+	Size    int       `json:"size"`
+}
+
+// Scanners is the response of GetScanners
+type Scanners = Container[ScannerContainer]
+
+// Season is a season of a show with its episode counts
+type Season struct {
+	AddedAt         int64  `json:"addedAt"`
+	Art             string `json:"art"`
+	Index           int64  `json:"index"`
+	Key             string `json:"key"`
+	LastViewedAt    int64  `json:"lastViewedAt"`
+	LeafCount       int64  `json:"leafCount"`
+	ParentIndex     int64  `json:"parentIndex"`
+	ParentKey       string `json:"parentKey"`
+	ParentRatingKey string `json:"parentRatingKey"`
+	ParentTitle     string `json:"parentTitle"`
+	RatingKey       string `json:"ratingKey"`
+	Summary         string `json:"summary"`
+	Thumb           string `json:"thumb"`
+	Title           string `json:"title"`
+	UpdatedAt       int64  `json:"updatedAt"`
+	ViewedLeafCount int64  `json:"viewedLeafCount"`
+}
+
+// UnwatchedLeafCount returns the number of episodes of the season that have not been watched
+func (s Season) UnwatchedLeafCount() int64 {
+	return s.LeafCount - s.ViewedLeafCount
+}
+
+// Watched reports whether every episode of the season has been watched
+func (s Season) Watched() bool {
+	return s.LeafCount > 0 && s.ViewedLeafCount >= s.LeafCount
+}
+
+// SeasonContainer lists the seasons of a show
+type SeasonContainer struct {
+	Metadata []Season `json:"Metadata"`
+	Size     int      `json:"size"`
+	Title1   string   `json:"title1"`
+	Title2   string   `json:"title2"`
+}
+
+// Seasons is the response of GetSeasons
+type Seasons = Container[SeasonContainer]
+
+// Artist is an artist of a music library section
+type Artist struct {
+	AddedAt      int64         `json:"addedAt"`
+	Art          string        `json:"art"`
+	Country      []TaggedData  `json:"Country"`
+	Genres       []Genre       `json:"Genre"`
+	GUID         string        `json:"guid"`
+	Key          string        `json:"key"`
+	LastViewedAt int64         `json:"lastViewedAt"`
+	RatingKey    string        `json:"ratingKey"`
+	Summary      string        `json:"summary"`
+	Thumb        string        `json:"thumb"`
+	Title        string        `json:"title"`
+	TitleSort    string        `json:"titleSort"`
+	Type         string        `json:"type"`
+	UpdatedAt    int64         `json:"updatedAt"`
+	ViewCount    FlexibleInt64 `json:"viewCount"`
+	Stations     []Station     `json:"Stations"` // only set by GetArtistStations
+}
+
+// Station is a radio station generated by plex, e.g. the radio of an artist
+type Station struct {
+	GUID  string `json:"guid"`
+	Key   string `json:"key"`
+	Radio bool   `json:"radio"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+}
+
+// stationHubContainer lists the hubs of a music section, including its station hub
+type stationHubContainer struct {
+	Hub []stationHub `json:"Hub"`
+}
+
+// stationHub is a hub of a music section whose entries are stations
+type stationHub struct {
+	HubIdentifier string    `json:"hubIdentifier"`
+	Type          string    `json:"type"`
+	Directory     []Station `json:"Directory"`
+	Metadata      []Station `json:"Metadata"`
+}
+
+// ArtistContainer lists the artists of a music section
+type ArtistContainer struct {
+	Metadata            []Artist `json:"Metadata"`
+	LibrarySectionID    int      `json:"librarySectionID"`
+	LibrarySectionTitle string   `json:"librarySectionTitle"`
+	Size                int      `json:"size"`
+}
+
+// Artists is the response of GetArtists and GetArtistStations
+type Artists = Container[ArtistContainer]
+
+// Album is an album of an artist
+type Album struct {
+	AddedAt                 int64         `json:"addedAt"`
+	Art                     string        `json:"art"`
+	Genres                  []Genre       `json:"Genre"`
+	GUID                    string        `json:"guid"`
+	Index                   int64         `json:"index"`
+	Key                     string        `json:"key"`
+	LastViewedAt            int64         `json:"lastViewedAt"`
+	LeafCount               int64         `json:"leafCount"`
+	LoudnessAnalysisVersion string        `json:"loudnessAnalysisVersion"`
+	OriginallyAvailableAt   string        `json:"originallyAvailableAt"`
+	ParentKey               string        `json:"parentKey"`
+	ParentRatingKey         string        `json:"parentRatingKey"`
+	ParentThumb             string        `json:"parentThumb"`
+	ParentTitle             string        `json:"parentTitle"`
+	Rating                  float64       `json:"rating"`
+	RatingKey               string        `json:"ratingKey"`
+	Studio                  string        `json:"studio"`
+	Summary                 string        `json:"summary"`
+	Thumb                   string        `json:"thumb"`
+	Title                   string        `json:"title"`
+	Type                    string        `json:"type"`
+	UpdatedAt               int64         `json:"updatedAt"`
+	UserRating              float64       `json:"userRating"`
+	ViewCount               FlexibleInt64 `json:"viewCount"`
+	ViewedLeafCount         int64         `json:"viewedLeafCount"`
+	Year                    int           `json:"year"`
+}
+
+// AlbumContainer lists the albums of an artist
+type AlbumContainer struct {
+	Metadata    []Album `json:"Metadata"`
+	Key         string  `json:"key"`
+	ParentTitle string  `json:"parentTitle"`
+	Size        int     `json:"size"`
+	Summary     string  `json:"summary"`
+	Title1      string  `json:"title1"`
+	Title2      string  `json:"title2"`
+}
+
+// Albums is the response of GetAlbums
+type Albums = Container[AlbumContainer]
+
+// Track is a track of an album. OriginalTitle is the track artist when it differs from
+// the album artist in GrandparentTitle.
+type Track struct {
+	AddedAt              int64         `json:"addedAt"`
+	Duration             int64         `json:"duration"`
+	GrandparentKey       string        `json:"grandparentKey"`
+	GrandparentRatingKey string        `json:"grandparentRatingKey"`
+	GrandparentThumb     string        `json:"grandparentThumb"`
+	GrandparentTitle     string        `json:"grandparentTitle"`
+	GUID                 string        `json:"guid"`
+	Index                int64         `json:"index"`
+	Key                  string        `json:"key"`
+	LastViewedAt         int64         `json:"lastViewedAt"`
+	Media                []Media       `json:"Media"`
+	OriginalTitle        string        `json:"originalTitle"`
+	ParentIndex          int64         `json:"parentIndex"`
+	ParentKey            string        `json:"parentKey"`
+	ParentRatingKey      string        `json:"parentRatingKey"`
+	ParentThumb          string        `json:"parentThumb"`
+	ParentTitle          string        `json:"parentTitle"`
+	ParentYear           int           `json:"parentYear"`
+	RatingCount          int64         `json:"ratingCount"`
+	RatingKey            string        `json:"ratingKey"`
+	Thumb                string        `json:"thumb"`
+	Title                string        `json:"title"`
+	Type                 string        `json:"type"`
+	UpdatedAt            int64         `json:"updatedAt"`
+	UserRating           float64       `json:"userRating"`
+	ViewCount            FlexibleInt64 `json:"viewCount"`
+	ViewOffset           int64         `json:"viewOffset"`
+	Distance             float64       `json:"distance"` // only set by GetSonicallySimilar
+}
+
+// TrackContainer lists the tracks of an album
+type TrackContainer struct {
+	Metadata    []Track `json:"Metadata"`
+	Key         string  `json:"key"`
+	ParentIndex int64   `json:"parentIndex"`
+	ParentTitle string  `json:"parentTitle"`
+	ParentYear  int     `json:"parentYear"`
+	Size        int     `json:"size"`
+	Title1      string  `json:"title1"`
+	Title2      string  `json:"title2"`
+}
+
+// Tracks is the response of GetTracks
+type Tracks = Container[TrackContainer]
+
+// SonicOptions tunes the results of GetSonicallySimilar. Zero values use the server defaults.
+type SonicOptions struct {
+	Limit           int     // maximum number of tracks
+	MaxDistance     float64 // maximum sonic distance, between 0 and 1
+	ExcludeParentID string  // leave out the tracks of an album, e.g. the album of the seed track
+}
+
+// PlayQueueOptions configures a play queue created by CreatePlayQueue
+type PlayQueueOptions struct {
+	Shuffle bool
+	Repeat  bool
+}
+
+// PlayQueueContainer is a play queue. Stations are continuous, plex appends items as the
+// queue is played.
+type PlayQueueContainer struct {
+	Metadata                    []Metadata `json:"Metadata"`
+	Identifier                  string     `json:"identifier"`
+	PlayQueueID                 int64      `json:"playQueueID"`
+	PlayQueueSelectedItemID     int64      `json:"playQueueSelectedItemID"`
+	PlayQueueSelectedItemOffset int64      `json:"playQueueSelectedItemOffset"`
+	PlayQueueShuffled           bool       `json:"playQueueShuffled"`
+	PlayQueueSourceURI          string     `json:"playQueueSourceURI"`
+	PlayQueueTotalCount         int64      `json:"playQueueTotalCount"`
+	PlayQueueVersion            int64      `json:"playQueueVersion"`
+	Size                        int        `json:"size"`
+}
+
+// PlayQueue is the response of CreatePlayQueue
+type PlayQueue = Container[PlayQueueContainer]
+
+// Photo is a photo or a photo album of a photo library section, see IsAlbum
+type Photo struct {
+	AddedAt               int64        `json:"addedAt"`
+	ChildCount            int64        `json:"childCount"`
+	Composite             string       `json:"composite"`
+	CreatedAtAccuracy     string       `json:"createdAtAccuracy"`
+	CreatedAtTZOffset     string       `json:"createdAtTZOffset"`
+	GUID                  string       `json:"guid"`
+	Index                 int64        `json:"index"`
+	Key                   string       `json:"key"`
+	LeafCount             int64        `json:"leafCount"`
+	Media                 []PhotoMedia `json:"Media"`
+	OriginallyAvailableAt string       `json:"originallyAvailableAt"`
+	ParentKey             string       `json:"parentKey"`
+	ParentRatingKey       string       `json:"parentRatingKey"`
+	ParentTitle           string       `json:"parentTitle"`
+	RatingKey             string       `json:"ratingKey"`
+	Summary               string       `json:"summary"`
+	Thumb                 string       `json:"thumb"`
+	Title                 string       `json:"title"`
+	Type                  string       `json:"type"`
+	UpdatedAt             int64        `json:"updatedAt"`
+	Year                  int          `json:"year"`
+}
+
+// IsAlbum reports whether the item is an album, whose contents are listed with GetPhotoAlbumContents
+func (p Photo) IsAlbum() bool {
+	return p.Type == "photoalbum" || p.Type == string(MediaTypePhotoAlbum)
+}
+
+// PhotoMedia is the media of a photo, including the fields plex reads from its EXIF data
+type PhotoMedia struct {
+	Aperture    string `json:"aperture"`
+	AspectRatio string `json:"aspectRatio"`
+	Container   string `json:"container"`
+	Exposure    string `json:"exposure"`
+	Height      int    `json:"height"`
+	ID          int64  `json:"id"`
+	ISO         int    `json:"iso"`
+	Lens        string `json:"lens"`
+	Make        string `json:"make"`
+	Model       string `json:"model"`
+	Part        []Part `json:"Part"`
+	Width       int    `json:"width"`
+}
+
+// PhotoContainer lists photos and albums
+type PhotoContainer struct {
+	Metadata            []Photo `json:"Metadata"`
+	LibrarySectionID    int     `json:"librarySectionID"`
+	LibrarySectionTitle string  `json:"librarySectionTitle"`
+	Size                int     `json:"size"`
+	Title1              string  `json:"title1"`
+	Title2              string  `json:"title2"`
+}
+
+// Photos is the response of the photo browsing helpers
+type Photos = Container[PhotoContainer]
+
+// OptimizeParams describes an optimized version to create with Optimize
+type OptimizeParams struct {
+	Title  string         // defaults to the title of the item
+	Target OptimizeTarget // defaults to OptimizeForMobile
+	// DeviceProfile and VideoQuality create a custom optimization, e.g. "Universal TV" and 60.
+	// Target is ignored when DeviceProfile is set.
+	DeviceProfile string
+	VideoQuality  int
+	// Unwatched only optimizes unwatched items, Limit optimizes at most Limit items of a show
+	// or season. Both are useful for items that keep growing.
+	Unwatched bool
+	Limit     int
+}
+
+// OptimizedItem is an optimization queued or done by the server
+type OptimizedItem struct {
+	ID          int64                 `json:"id"`
+	Title       string                `json:"title"`
+	Target      string                `json:"target"`
+	TargetTagID int                   `json:"targetTagID"`
+	Type        int                   `json:"type"`
+	Location    OptimizedItemLocation `json:"Location"`
+	Status      OptimizedItemStatus   `json:"Status"`
+}
+
+// OptimizedItemLocation is the library uri of the content of an OptimizedItem
+type OptimizedItemLocation struct {
+	URI string `json:"uri"`
+}
+
+// OptimizedItemStatus is the progress of an OptimizedItem
+type OptimizedItemStatus struct {
+	ItemsCount           int64  `json:"itemsCount"`
+	ItemsCompleteCount   int64  `json:"itemsCompleteCount"`
+	ItemsSuccessfulCount int64  `json:"itemsSuccessfulCount"`
+	State                string `json:"state"`
+	TotalSize            int64  `json:"totalSize"`
+}
+
+// OptimizedItemContainer lists the optimizations of the server
+type OptimizedItemContainer struct {
+	Item []OptimizedItem `json:"Item"`
+	Size int             `json:"size"`
+}
+
+// OptimizedItems is the response of GetOptimizedItems
+type OptimizedItems = Container[OptimizedItemContainer]
+
+// SyncItem is content a device keeps for offline playback
+type SyncItem struct {
+	ID                int               `xml:"id,attr"`
+	Version           int               `xml:"version,attr"`
+	RootTitle         string            `xml:"rootTitle,attr"`
+	Title             string            `xml:"title,attr"`
+	MetadataType      string            `xml:"metadataType,attr"`
+	ContentType       string            `xml:"contentType,attr"`
+	MachineIdentifier string            `xml:"machineIdentifier,attr"`
+	Status            SyncItemStatus    `xml:"Status"`
+	MediaSettings     SyncMediaSettings `xml:"MediaSettings"`
+	Policy            SyncPolicy        `xml:"Policy"`
+	Location          SyncItemLocation  `xml:"Location"`
+}
+
+// SyncItemStatus is the progress of a SyncItem
+type SyncItemStatus struct {
+	State                string `xml:"state,attr"`
+	Failure              string `xml:"failure,attr"`
+	FailureCode          string `xml:"failureCode,attr"`
+	ItemsCount           int    `xml:"itemsCount,attr"`
+	ItemsCompleteCount   int    `xml:"itemsCompleteCount,attr"`
+	ItemsDownloadedCount int    `xml:"itemsDownloadedCount,attr"`
+	ItemsReadyCount      int    `xml:"itemsReadyCount,attr"`
+	ItemsSuccessfulCount int    `xml:"itemsSuccessfulCount,attr"`
+	TotalSize            int64  `xml:"totalSize,attr"`
+}
+
+// SyncMediaSettings is the quality a SyncItem is transcoded to
+type SyncMediaSettings struct {
+	MaxVideoBitrate int    `xml:"maxVideoBitrate,attr"`
+	MusicBitrate    int    `xml:"musicBitrate,attr"`
+	PhotoResolution string `xml:"photoResolution,attr"`
+	VideoQuality    int    `xml:"videoQuality,attr"`
+	VideoResolution string `xml:"videoResolution,attr"`
+}
+
+// SyncPolicy is how many items of a SyncItem are kept on the device
+type SyncPolicy struct {
+	Scope     string `xml:"scope,attr"`
+	Value     int    `xml:"value,attr"`
+	Unwatched bool   `xml:"unwatched,attr"`
+}
+
+// SyncItemLocation is the library uri of the content of a SyncItem
+type SyncItemLocation struct {
+	URI string `xml:"uri,attr"`
+}
+
+// SyncItems is the result of the https://plex.tv/devices/{id}/sync_items endpoint
+type SyncItems struct {
+	ClientIdentifier string     `xml:"clientIdentifier,attr"`
+	SyncItem         []SyncItem `xml:"SyncItem"`
+}
+
+// SyncItemParams describes content to sync to a device with CreateSyncItem
+type SyncItemParams struct {
+	Key       string // rating key of the movie, show, season, episode, album or playlist
+	Title     string // defaults to the title of the item
+	Unwatched bool   // only sync unwatched items
+	Limit     int    // sync at most Limit items of a show, season or album, 0 syncs all
+	// Optional media settings, the device defaults are used when unset
+	VideoQuality    int    // 0 to 100
+	VideoResolution string // e.g. "1280x720"
+	MaxVideoBitrate int    // in kbps
+	MusicBitrate    int    // in kbps
+	PhotoResolution string // e.g. "1920x1080"
+}
@@ -0,0 +1,170 @@
+package plex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ServerClient is a client of one of the servers of a MultiServer
+type ServerClient struct {
+	Name              string
+	MachineIdentifier string
+	Client            *Plex
+}
+
+// MultiServer sends the same request to several servers at once and merges the results,
+// attributing each result to its server
+type MultiServer struct {
+	Servers []ServerClient
+	// Concurrency is the number of servers queried at once, 0 queries them all at once
+	Concurrency int
+}
+
+// NewMultiServer returns a MultiServer for the given servers
+func NewMultiServer(servers ...ServerClient) *MultiServer {
+	return &MultiServer{Servers: servers}
+}
+
+// NewMultiServerFromResources builds a MultiServer from the servers among devices, as
+// returned by GetServers, using each server's access token. Local connections are
+// preferred over remote ones.
+func NewMultiServerFromResources(devices []PMSDevices, opts ...Option) (*MultiServer, error) {
+	m := &MultiServer{}
+
+	for _, device := range devices {
+		if !strings.Contains(device.Provides, "server") {
+			continue
+		}
+
+		connection, ok := preferredConnection(device.Connection)
+
+		if !ok {
+			return nil, fmt.Errorf(ErrorCommon, "no connection to "+device.Name)
+		}
+
+		client, err := New(connection.URI, device.AccessToken, opts...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		m.Servers = append(m.Servers, ServerClient{
+			Name:              device.Name,
+			MachineIdentifier: device.ClientIdentifier,
+			Client:            client,
+		})
+	}
+
+	return m, nil
+}
+
+// preferredConnection picks the first local connection, else the first remote one
+func preferredConnection(connections []Connection) (Connection, bool) {
+	best, ok := Connection{}, false
+
+	for _, c := range connections {
+		if c.URI == "" {
+			continue
+		}
+
+		if !ok || c.Local == 1 && best.Local != 1 {
+			best, ok = c, true
+		}
+	}
+
+	return best, ok
+}
+
+// ServerItem is an item found on one of the servers of a MultiServer
+type ServerItem struct {
+	Server string
+	Item   Metadata
+}
+
+// ServerLibrary is a library of one of the servers of a MultiServer
+type ServerLibrary struct {
+	Server  string
+	Library Directory
+}
+
+// Search searches every server for title, see SearchPlexWithOptions. Results are grouped
+// by server in the order of Servers. When some servers fail, the results of the others
+// are returned with an error naming the failed servers.
+func (m *MultiServer) Search(title string, opts SearchOptions) ([]ServerItem, error) {
+	return fanOut(m, func(s ServerClient) ([]ServerItem, error) {
+		results, err := s.Client.SearchPlexWithOptions(title, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]ServerItem, len(results.MediaContainer.Metadata))
+
+		for i, item := range results.MediaContainer.Metadata {
+			items[i] = ServerItem{Server: s.Name, Item: item}
+		}
+
+		return items, nil
+	})
+}
+
+// GetLibraries lists the libraries of every server, failures are handled like in Search
+func (m *MultiServer) GetLibraries() ([]ServerLibrary, error) {
+	return fanOut(m, func(s ServerClient) ([]ServerLibrary, error) {
+		libraries, err := s.Client.GetLibraries()
+
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]ServerLibrary, len(libraries.MediaContainer.Directory))
+
+		for i, dir := range libraries.MediaContainer.Directory {
+			result[i] = ServerLibrary{Server: s.Name, Library: dir}
+		}
+
+		return result, nil
+	})
+}
+
+// fanOut calls fn for every server of m, up to m.Concurrency at once, and merges the results
+func fanOut[T any](m *MultiServer, fn func(s ServerClient) ([]T, error)) ([]T, error) {
+	limit := m.Concurrency
+
+	if limit <= 0 || limit > len(m.Servers) {
+		limit = len(m.Servers)
+	}
+
+	results := make([][]T, len(m.Servers))
+	errs := make([]error, len(m.Servers))
+
+	sem := make(chan struct{}, max(limit, 1))
+
+	var wg sync.WaitGroup
+
+	for i, server := range m.Servers {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, server ServerClient) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if results[i], errs[i] = fn(server); errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", server.Name, errs[i])
+			}
+		}(i, server)
+	}
+
+	wg.Wait()
+
+	var merged []T
+
+	for _, r := range results {
+		merged = append(merged, r...)
+	}
+
+	return merged, errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMultiServer(t *testing.T) {
+	newServer := func(title string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", applicationJson)
+
+			switch r.URL.Path {
+			case "/search":
+				_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Metadata":[{"title":"` + title + `"}]}}`))
+			case "/library/sections":
+				_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Directory":[{"key":"1","title":"Movies"}]}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+
+	home := newServer("Heat")
+	defer home.Close()
+
+	cabin := newServer("Heat (1995)")
+	defer cabin.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	m, err := NewMultiServerFromResources([]PMSDevices{
+		{Name: "home", Provides: "server", AccessToken: "a", Connection: []Connection{{URI: "http://unreachable.invalid"}, {URI: home.URL, Local: 1}}},
+		{Name: "phone", Provides: "client,player"},
+		{Name: "cabin", Provides: "server", AccessToken: "b", Connection: []Connection{{URI: cabin.URL}}},
+	})
+	if err != nil {
+		t.Fatalf("NewMultiServerFromResources() error = %v", err)
+	}
+
+	if len(m.Servers) != 2 || m.Servers[0].Client.URL != home.URL || m.Servers[1].Client.Token != "b" {
+		t.Fatalf("unexpected servers %+v", m.Servers)
+	}
+
+	items, err := m.Search("heat", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(items) != 2 || items[0].Server != "home" || items[0].Item.Title != "Heat" || items[1].Server != "cabin" {
+		t.Errorf("unexpected search results %+v", items)
+	}
+
+	brokenClient, err := New(broken.URL, "c")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	m.Servers = append(m.Servers, ServerClient{Name: "attic", Client: brokenClient})
+	m.Concurrency = 1
+
+	libraries, err := m.GetLibraries()
+	if err == nil || !strings.Contains(err.Error(), "attic") {
+		t.Errorf("expected an error naming the failed server, got %v", err)
+	}
+
+	if len(libraries) != 2 || libraries[1].Server != "cabin" || libraries[1].Library.Title != "Movies" {
+		t.Errorf("unexpected libraries %+v", libraries)
+	}
+}
@@ -0,0 +1,134 @@
+package plex
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GetArtists lists the artists of a music library section
+func (p *Plex) GetArtists(sectionID string) (Artists, error) {
+	if sectionID == "" {
+		return Artists{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/all?type=%d", p.ServerURL(), sectionID, MediaTypeArtist.ID())
+
+	return getContainer[ArtistContainer](p, query)
+}
+
+// GetAlbums lists the albums of an artist. artistKey is the RatingKey of an Artist.
+func (p *Plex) GetAlbums(artistKey string) (Albums, error) {
+	if artistKey == "" {
+		return Albums{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/metadata/%s/children", p.ServerURL(), artistKey)
+
+	return getContainer[AlbumContainer](p, query)
+}
+
+// GetTracks lists the tracks of an album. albumKey is the RatingKey of an Album.
+func (p *Plex) GetTracks(albumKey string) (Tracks, error) {
+	if albumKey == "" {
+		return Tracks{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/metadata/%s/children", p.ServerURL(), albumKey)
+
+	return getContainer[TrackContainer](p, query)
+}
+
+// GetSonicallySimilar returns the tracks closest to a track by plex's sonic analysis, ordered by
+// Distance. The music section must have sonic analysis enabled.
+func (p *Plex) GetSonicallySimilar(trackKey string, opts SonicOptions) (Tracks, error) {
+	if trackKey == "" {
+		return Tracks{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	params := url.Values{}
+
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	if opts.MaxDistance > 0 {
+		params.Set("maxDistance", strconv.FormatFloat(opts.MaxDistance, 'f', -1, 64))
+	}
+
+	if opts.ExcludeParentID != "" {
+		params.Set("excludeParentID", opts.ExcludeParentID)
+	}
+
+	query := fmt.Sprintf("%s/library/metadata/%s/nearest", p.ServerURL(), trackKey)
+
+	if len(params) > 0 {
+		query += "?" + params.Encode()
+	}
+
+	return getContainer[TrackContainer](p, query)
+}
+
+// GetArtistStations returns the radio stations plex offers for an artist, usually a single
+// "artist radio" station
+func (p *Plex) GetArtistStations(artistKey string) ([]Station, error) {
+	if artistKey == "" {
+		return nil, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/metadata/%s?includeStations=1", p.ServerURL(), artistKey)
+
+	artists, err := getContainer[ArtistContainer](p, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(artists.MediaContainer.Metadata) == 0 {
+		return nil, nil
+	}
+
+	return artists.MediaContainer.Metadata[0].Stations, nil
+}
+
+// GetLibraryStations returns the radio stations of a music section, such as library radio
+// or deep cuts radio, read from the section's station hub. Play them with
+// CreateStationPlayQueue.
+func (p *Plex) GetLibraryStations(sectionKey string) ([]Station, error) {
+	if sectionKey == "" {
+		return nil, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/hubs/sections/%s?includeStations=1", p.ServerURL(), sectionKey)
+
+	hubs, err := getContainer[stationHubContainer](p, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stations []Station
+
+	for _, hub := range hubs.MediaContainer.Hub {
+		if hub.Type != "station" && !strings.HasPrefix(hub.HubIdentifier, "music.stations") {
+			continue
+		}
+
+		// depending on the server version the stations are directories or metadata
+		stations = append(stations, hub.Directory...)
+		stations = append(stations, hub.Metadata...)
+	}
+
+	return stations, nil
+}
+
+// GetStationTracks returns the tracks of a station returned by GetArtistStations or
+// GetLibraryStations. Plex generates a new selection each time a station is requested.
+func (p *Plex) GetStationTracks(station Station) (Tracks, error) {
+	if !strings.HasPrefix(station.Key, "/library/") {
+		return Tracks{}, fmt.Errorf(ErrorCommon, "station key must start with /library/")
+	}
+
+	return getContainer[TrackContainer](p, p.ServerURL()+station.Key)
+}
@@ -0,0 +1,118 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPlex_MusicBrowsing(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/library/sections/3/all":       `{"MediaContainer":{"size":1,"Metadata":[{"ratingKey":"10","title":"Daft Punk","type":"artist"}]}}`,
+		"/library/metadata/10/children": `{"MediaContainer":{"size":1,"Metadata":[{"ratingKey":"11","parentRatingKey":"10","title":"Discovery","year":2001,"leafCount":14}]}}`,
+		"/library/metadata/11/children": `{"MediaContainer":{"size":1,"parentYear":2001,"Metadata":[{"ratingKey":"12","title":"One More Time","index":1,"parentIndex":1,"parentYear":2001,"ratingCount":1500000,"originalTitle":"Daft Punk feat. Romanthony","duration":320000}]}}`,
+	})
+
+	artists, err := p.GetArtists("3")
+	if err != nil {
+		t.Fatalf("GetArtists() error = %v", err)
+	}
+
+	if len(artists.MediaContainer.Metadata) != 1 || artists.MediaContainer.Metadata[0].Title != "Daft Punk" {
+		t.Fatalf("unexpected artists: %+v", artists.MediaContainer)
+	}
+
+	albums, err := p.GetAlbums(artists.MediaContainer.Metadata[0].RatingKey)
+	if err != nil {
+		t.Fatalf("GetAlbums() error = %v", err)
+	}
+
+	if len(albums.MediaContainer.Metadata) != 1 || albums.MediaContainer.Metadata[0].Year != 2001 || albums.MediaContainer.Metadata[0].LeafCount != 14 {
+		t.Fatalf("unexpected albums: %+v", albums.MediaContainer)
+	}
+
+	tracks, err := p.GetTracks(albums.MediaContainer.Metadata[0].RatingKey)
+	if err != nil {
+		t.Fatalf("GetTracks() error = %v", err)
+	}
+
+	track := tracks.MediaContainer.Metadata[0]
+
+	if track.ParentYear != 2001 || track.RatingCount != 1500000 || track.OriginalTitle != "Daft Punk feat. Romanthony" {
+		t.Errorf("unexpected track: %+v", track)
+	}
+
+	for name, fn := range map[string]func() error{
+		"GetArtists": func() error { _, err := p.GetArtists(""); return err },
+		"GetAlbums":  func() error { _, err := p.GetAlbums(""); return err },
+		"GetTracks":  func() error { _, err := p.GetTracks(""); return err },
+	} {
+		if err := fn(); err == nil {
+			t.Errorf("%s: expected an error for an empty key", name)
+		}
+	}
+}
+
+func TestPlex_GetSonicallySimilar(t *testing.T) {
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library/metadata/12/nearest" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		gotQuery = r.URL.Query()
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Metadata":[{"ratingKey":"40","title":"Around the World","distance":0.12}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	tracks, err := p.GetSonicallySimilar("12", SonicOptions{Limit: 25, MaxDistance: 0.25, ExcludeParentID: "11"})
+	if err != nil {
+		t.Fatalf("GetSonicallySimilar() error = %v", err)
+	}
+
+	if gotQuery.Get("limit") != "25" || gotQuery.Get("maxDistance") != "0.25" || gotQuery.Get("excludeParentID") != "11" {
+		t.Errorf("unexpected query %v", gotQuery)
+	}
+
+	if len(tracks.MediaContainer.Metadata) != 1 || tracks.MediaContainer.Metadata[0].Distance != 0.12 {
+		t.Errorf("unexpected tracks: %+v", tracks.MediaContainer)
+	}
+}
+
+func TestPlex_ArtistStations(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/library/metadata/10":             `{"MediaContainer":{"size":1,"Metadata":[{"ratingKey":"10","title":"Daft Punk","Stations":[{"key":"/library/metadata/10/station/abc","title":"Daft Punk Radio","type":"playlist","radio":true}]}]}}`,
+		"/library/metadata/10/station/abc": `{"MediaContainer":{"size":2,"Metadata":[{"ratingKey":"12"},{"ratingKey":"40"}]}}`,
+	})
+
+	stations, err := p.GetArtistStations("10")
+	if err != nil {
+		t.Fatalf("GetArtistStations() error = %v", err)
+	}
+
+	if len(stations) != 1 || !stations[0].Radio {
+		t.Fatalf("unexpected stations: %+v", stations)
+	}
+
+	tracks, err := p.GetStationTracks(stations[0])
+	if err != nil {
+		t.Fatalf("GetStationTracks() error = %v", err)
+	}
+
+	if len(tracks.MediaContainer.Metadata) != 2 {
+		t.Errorf("unexpected tracks: %+v", tracks.MediaContainer)
+	}
+
+	if _, err := p.GetStationTracks(Station{Key: "http://example.com"}); err == nil {
+		t.Error("expected an error for a foreign station key")
+	}
+}
@@ -0,0 +1,143 @@
+package plex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// OptimizeTarget is a predefined quality for optimized versions
+type OptimizeTarget int
+
+// Targets offered by the plex apps, the values are the target tag ids plex uses
+const (
+	OptimizeForMobile OptimizeTarget = 1
+	OptimizeForTV     OptimizeTarget = 2
+	OptimizeOriginal  OptimizeTarget = 3
+)
+
+// optimizeTargetTitles are the names plex shows for each target
+var optimizeTargetTitles = map[OptimizeTarget]string{
+	OptimizeForMobile: "Optimized for Mobile",
+	OptimizeForTV:     "Optimized for TV",
+	OptimizeOriginal:  "Original Quality",
+}
+
+// optimizerPlaylistID is the id of the generator playlist plex queues optimizations on
+const optimizerPlaylistID = "1"
+
+// optimizedItemType is the generator type of optimized versions
+const optimizedItemType = 42
+
+// Optimize queues the creation of an optimized version of the movie, show, season or episode
+// with rating key key
+func (p *Plex) Optimize(key string, params OptimizeParams) error {
+	if key == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	metadata, err := p.GetMetadata(key)
+
+	if err != nil {
+		return err
+	}
+
+	if len(metadata.MediaContainer.Metadata) == 0 || metadata.MediaContainer.LibrarySectionUUID == "" {
+		return fmt.Errorf(ErrorCommon, "no library item found for key "+key)
+	}
+
+	item := metadata.MediaContainer.Metadata[0]
+
+	vals := url.Values{}
+	vals.Set("Item[type]", strconv.Itoa(optimizedItemType))
+	vals.Set("Item[locationID]", "-1")
+	vals.Set("Item[Location][uri]", libraryItemURI(metadata.MediaContainer.LibrarySectionUUID, item.Key))
+	vals.Set("Item[Policy][scope]", "all")
+	vals.Set("Item[Policy][unwatched]", boolToOneOrZero(params.Unwatched))
+
+	if params.Limit > 0 {
+		vals.Set("Item[Policy][scope]", "count")
+		vals.Set("Item[Policy][value]", strconv.Itoa(params.Limit))
+	}
+
+	title := params.Title
+
+	if title == "" {
+		title = item.Title
+	}
+
+	vals.Set("Item[title]", title)
+
+	if params.DeviceProfile != "" {
+		vals.Set("Item[target]", "Custom")
+		vals.Set("Item[targetTagID]", "")
+		vals.Set("Item[Device][profile]", params.DeviceProfile)
+
+		if params.VideoQuality > 0 {
+			vals.Set("Item[MediaSettings][videoQuality]", strconv.Itoa(params.VideoQuality))
+		}
+	} else {
+		target := params.Target
+
+		if target == 0 {
+			target = OptimizeForMobile
+		}
+
+		name, ok := optimizeTargetTitles[target]
+
+		if !ok {
+			return fmt.Errorf(ErrorCommon, "unknown optimize target "+strconv.Itoa(int(target)))
+		}
+
+		vals.Set("Item[target]", name)
+		vals.Set("Item[targetTagID]", strconv.Itoa(int(target)))
+	}
+
+	query := fmt.Sprintf("%s/playlists/%s/items?%s", p.ServerURL(), optimizerPlaylistID, vals.Encode())
+
+	resp, err := p.put(query, nil, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
+
+// GetOptimizedItems lists the optimizations of the server with their progress
+func (p *Plex) GetOptimizedItems() (OptimizedItems, error) {
+	query := fmt.Sprintf("%s/playlists/generators?type=%d", p.ServerURL(), optimizedItemType)
+
+	return getContainer[OptimizedItemContainer](p, query)
+}
+
+// DeleteOptimizedItem deletes an optimization and the optimized versions it created
+func (p *Plex) DeleteOptimizedItem(id int64) error {
+	query := fmt.Sprintf("%s/playlists/generators/%d", p.ServerURL(), id)
+
+	resp, err := p.delete(query, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPlex_Optimize(t *testing.T) {
+	var gotMethod string
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+
+		switch r.URL.Path {
+		case "/library/metadata/7":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"librarySectionUUID":"uuid-1","Metadata":[{"ratingKey":"7","key":"/library/metadata/7","title":"Heat"}]}}`))
+		case "/playlists/1/items":
+			gotMethod = r.Method
+			gotQuery = r.URL.Query()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params OptimizeParams
+		want   map[string]string
+	}{
+		{
+			name:   "default target",
+			params: OptimizeParams{},
+			want: map[string]string{
+				"Item[type]":          "42",
+				"Item[title]":         "Heat",
+				"Item[target]":        "Optimized for Mobile",
+				"Item[targetTagID]":   "1",
+				"Item[Location][uri]": "library://uuid-1/item/%2Flibrary%2Fmetadata%2F7",
+				"Item[Policy][scope]": "all",
+			},
+		},
+		{
+			name:   "custom profile",
+			params: OptimizeParams{Title: "TV", DeviceProfile: "Universal TV", VideoQuality: 60, Unwatched: true, Limit: 5},
+			want: map[string]string{
+				"Item[title]":                       "TV",
+				"Item[target]":                      "Custom",
+				"Item[Device][profile]":             "Universal TV",
+				"Item[MediaSettings][videoQuality]": "60",
+				"Item[Policy][scope]":               "count",
+				"Item[Policy][value]":               "5",
+				"Item[Policy][unwatched]":           "1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if err := p.Optimize("7", test.params); err != nil {
+			t.Fatalf("%s: Optimize() error = %v", test.name, err)
+		}
+
+		if gotMethod != http.MethodPut {
+			t.Errorf("%s: expected PUT, got %s", test.name, gotMethod)
+		}
+
+		for key, want := range test.want {
+			if got := gotQuery.Get(key); got != want {
+				t.Errorf("%s: %s = %q, want %q", test.name, key, got, want)
+			}
+		}
+	}
+
+	if err := p.Optimize("7", OptimizeParams{Target: 9}); err == nil {
+		t.Error("expected an error for an unknown target")
+	}
+}
+
+func TestPlex_OptimizedItems(t *testing.T) {
+	var deleted string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = r.URL.Path
+			return
+		}
+
+		if r.URL.Query().Get("type") != "42" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Item":[{"id":3,"title":"Heat","target":"Optimized for TV","targetTagID":2,"Status":{"itemsCount":1,"itemsCompleteCount":1,"state":"complete"}}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	items, err := p.GetOptimizedItems()
+	if err != nil {
+		t.Fatalf("GetOptimizedItems() error = %v", err)
+	}
+
+	if len(items.MediaContainer.Item) != 1 || items.MediaContainer.Item[0].Status.State != "complete" {
+		t.Fatalf("unexpected items: %+v", items.MediaContainer)
+	}
+
+	if err := p.DeleteOptimizedItem(items.MediaContainer.Item[0].ID); err != nil {
+		t.Fatalf("DeleteOptimizedItem() error = %v", err)
+	}
+
+	if deleted != "/playlists/generators/3" {
+		t.Errorf("deleted %q", deleted)
+	}
+}
@@ -0,0 +1,123 @@
+package plex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetPhotoAlbums lists the top level albums of a photo library section
+func (p *Plex) GetPhotoAlbums(sectionID string) (Photos, error) {
+	if sectionID == "" {
+		return Photos{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	return p.getPhotos(sectionID, NewLibraryFilter().Type(MediaTypePhotoAlbum))
+}
+
+// GetPhotoAlbumContents lists the nested albums and photos of an album. albumKey is the
+// RatingKey of a Photo for which IsAlbum is true.
+func (p *Plex) GetPhotoAlbumContents(albumKey string) (Photos, error) {
+	if albumKey == "" {
+		return Photos{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/metadata/%s/children", p.ServerURL(), albumKey)
+
+	return getContainer[PhotoContainer](p, query)
+}
+
+// GetPhotoTimeline lists the photos of a section taken during a month, or during the whole
+// year when month is 0, newest first. Dates are in the server's time zone.
+func (p *Plex) GetPhotoTimeline(sectionID string, year int, month time.Month) (Photos, error) {
+	if sectionID == "" {
+		return Photos{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	if month < 0 || month > time.December {
+		return Photos{}, fmt.Errorf(ErrorCommon, "month must be between 1 and 12, or 0 for the whole year")
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(1, 0, 0)
+
+	if month != 0 {
+		from = time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+		to = from.AddDate(0, 1, 0)
+	}
+
+	filter := NewLibraryFilter().
+		Type(MediaTypePhoto).
+		Set("originallyAvailableAt>>", strconv.FormatInt(from.Unix()-1, 10)).
+		Set("originallyAvailableAt<<", strconv.FormatInt(to.Unix(), 10)).
+		SortDesc("originallyAvailableAt")
+
+	return p.getPhotos(sectionID, filter)
+}
+
+// getPhotos lists the items of a photo section matching filter
+func (p *Plex) getPhotos(sectionID string, filter *LibraryFilter) (Photos, error) {
+	query := fmt.Sprintf("%s/library/sections/%s/all%s", p.ServerURL(), sectionID, filter)
+
+	return getContainer[PhotoContainer](p, query)
+}
+
+// UploadPhoto uploads an image to a photo library section the way the mobile apps' camera
+// upload does. directory is the folder inside the section the file is stored in, e.g.
+// "Camera Uploads", and filename the name of the file in it.
+func (p *Plex) UploadPhoto(sectionID, directory, filename string, content io.Reader) error {
+	if sectionID == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	if filename == "" || strings.ContainsAny(filename, `/\`) {
+		return fmt.Errorf(ErrorCommon, "filename is required and must not contain a path")
+	}
+
+	body, err := io.ReadAll(content)
+
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("directory", directory)
+	params.Set("filename", filename)
+
+	query := fmt.Sprintf("%s/library/sections/%s/upload?%s", p.ServerURL(), sectionID, params.Encode())
+
+	h := p.Headers
+	h.ContentType = uploadContentType(filename)
+
+	resp, err := p.post(query, body, h)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
+
+// uploadContentType guesses the content type of an uploaded file from its extension
+func uploadContentType(filename string) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
@@ -0,0 +1,130 @@
+package plex
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlex_PhotoBrowsing(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/library/sections/4/all":       `{"MediaContainer":{"size":1,"Metadata":[{"ratingKey":"20","title":"Holidays","type":"photo","leafCount":3}]}}`,
+		"/library/metadata/20/children": `{"MediaContainer":{"size":2,"Metadata":[{"ratingKey":"21","title":"Italy","type":"photoalbum"},{"ratingKey":"22","title":"IMG_0001","type":"photo","Media":[{"aperture":"f/1.8","exposure":"1/120s","iso":100,"make":"Apple","model":"iPhone 13","width":4032,"height":3024}]}]}}`,
+	})
+
+	albums, err := p.GetPhotoAlbums("4")
+	if err != nil {
+		t.Fatalf("GetPhotoAlbums() error = %v", err)
+	}
+
+	if len(albums.MediaContainer.Metadata) != 1 {
+		t.Fatalf("unexpected albums: %+v", albums.MediaContainer)
+	}
+
+	contents, err := p.GetPhotoAlbumContents("20")
+	if err != nil {
+		t.Fatalf("GetPhotoAlbumContents() error = %v", err)
+	}
+
+	if len(contents.MediaContainer.Metadata) != 2 || !contents.MediaContainer.Metadata[0].IsAlbum() || contents.MediaContainer.Metadata[1].IsAlbum() {
+		t.Fatalf("unexpected contents: %+v", contents.MediaContainer)
+	}
+
+	media := contents.MediaContainer.Metadata[1].Media[0]
+
+	if media.Aperture != "f/1.8" || media.ISO != 100 || media.Make != "Apple" || media.Model != "iPhone 13" {
+		t.Errorf("unexpected exif fields: %+v", media)
+	}
+}
+
+func TestPlex_GetPhotoTimeline(t *testing.T) {
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	tests := []struct {
+		month    time.Month
+		from, to time.Time
+	}{
+		{time.May, time.Date(2021, time.May, 1, 0, 0, 0, 0, time.Local), time.Date(2021, time.June, 1, 0, 0, 0, 0, time.Local)},
+		{0, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.Local), time.Date(2022, time.January, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, test := range tests {
+		if _, err := p.GetPhotoTimeline("4", 2021, test.month); err != nil {
+			t.Fatalf("GetPhotoTimeline() error = %v", err)
+		}
+
+		if gotQuery.Get("type") != "13" || gotQuery.Get("sort") != "originallyAvailableAt:desc" {
+			t.Errorf("unexpected query %v", gotQuery)
+		}
+
+		if got, want := gotQuery.Get("originallyAvailableAt>>"), strconv.FormatInt(test.from.Unix()-1, 10); got != want {
+			t.Errorf("month %d: from = %s, want %s", test.month, got, want)
+		}
+
+		if got, want := gotQuery.Get("originallyAvailableAt<<"), strconv.FormatInt(test.to.Unix(), 10); got != want {
+			t.Errorf("month %d: to = %s, want %s", test.month, got, want)
+		}
+	}
+
+	if _, err := p.GetPhotoTimeline("4", 2021, 13); err == nil {
+		t.Error("expected an error for an invalid month")
+	}
+}
+
+func TestPlex_UploadPhoto(t *testing.T) {
+	var gotQuery url.Values
+	var gotType, gotBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/library/sections/4/upload" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		gotQuery = r.URL.Query()
+		gotType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if err := p.UploadPhoto("4", "Camera Uploads", "IMG_0001.JPG", strings.NewReader("jpeg data")); err != nil {
+		t.Fatalf("UploadPhoto() error = %v", err)
+	}
+
+	if gotQuery.Get("directory") != "Camera Uploads" || gotQuery.Get("filename") != "IMG_0001.JPG" {
+		t.Errorf("unexpected query %v", gotQuery)
+	}
+
+	if gotType != "image/jpeg" || gotBody != "jpeg data" {
+		t.Errorf("unexpected upload: type=%q body=%q", gotType, gotBody)
+	}
+
+	if err := p.UploadPhoto("4", "", "../escape.jpg", strings.NewReader("")); err == nil {
+		t.Error("expected an error for a filename containing a path")
+	}
+}
@@ -0,0 +1,298 @@
+package plex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Kinds of ExportedList
+const (
+	ListKindPlaylist   = "playlist"
+	ListKindCollection = "collection"
+)
+
+// ExportedList is a playlist or collection in a server independent form. It is written as
+// json with encoding/json, or as an m3u playlist with WriteM3U, and recreated on a server
+// with ImportList.
+type ExportedList struct {
+	Kind    string             `json:"kind"`
+	Title   string             `json:"title"`
+	Summary string             `json:"summary,omitempty"`
+	Type    string             `json:"type"` // playlist type (video, audio, photo) or collection subtype (movie, show, ...)
+	Items   []ExportedListItem `json:"items"`
+}
+
+// ExportedListItem is an item of an ExportedList. Items are matched by GUIDs on import.
+type ExportedListItem struct {
+	Title    string   `json:"title"`
+	Type     string   `json:"type"`
+	Show     string   `json:"show,omitempty"`
+	Season   int64    `json:"season,omitempty"`
+	Episode  int64    `json:"episode,omitempty"`
+	Year     int      `json:"year,omitempty"`
+	Duration int      `json:"duration,omitempty"` // in milliseconds
+	GUIDs    []string `json:"guids"`
+	File     string   `json:"file,omitempty"`
+}
+
+// ImportResult reports the outcome of ImportList
+type ImportResult struct {
+	RatingKey string             // rating key of the created playlist or collection
+	Matched   int                // number of items found on the server
+	Unmatched []ExportedListItem // items without a match, they are left out
+}
+
+// listHeader holds the fields of a playlist or collection needed to export it
+type listHeader struct {
+	Metadata []struct {
+		Title        string `json:"title"`
+		Summary      string `json:"summary"`
+		PlaylistType string `json:"playlistType"`
+		Subtype      string `json:"subtype"`
+	} `json:"Metadata"`
+}
+
+// ExportPlaylist exports a playlist with its items
+func (p *Plex) ExportPlaylist(playlistID int) (ExportedList, error) {
+	header, err := getContainer[listHeader](p, fmt.Sprintf("%s/playlists/%d", p.ServerURL(), playlistID))
+
+	if err != nil {
+		return ExportedList{}, err
+	}
+
+	if len(header.MediaContainer.Metadata) == 0 {
+		return ExportedList{}, fmt.Errorf(ErrorCommon, "playlist "+strconv.Itoa(playlistID)+" not found")
+	}
+
+	items, err := getContainer[MediaContainer](p, fmt.Sprintf("%s/playlists/%d/items?includeGuids=1", p.ServerURL(), playlistID))
+
+	if err != nil {
+		return ExportedList{}, err
+	}
+
+	h := header.MediaContainer.Metadata[0]
+
+	return newExportedList(ListKindPlaylist, h.Title, h.Summary, h.PlaylistType, items.MediaContainer.Metadata), nil
+}
+
+// ExportCollection exports a collection with its items. collectionKey is the rating key of the collection.
+func (p *Plex) ExportCollection(collectionKey string) (ExportedList, error) {
+	if collectionKey == "" {
+		return ExportedList{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	header, err := getContainer[listHeader](p, fmt.Sprintf("%s/library/collections/%s", p.ServerURL(), collectionKey))
+
+	if err != nil {
+		return ExportedList{}, err
+	}
+
+	if len(header.MediaContainer.Metadata) == 0 {
+		return ExportedList{}, fmt.Errorf(ErrorCommon, "collection "+collectionKey+" not found")
+	}
+
+	items, err := getContainer[MediaContainer](p, fmt.Sprintf("%s/library/collections/%s/children?includeGuids=1", p.ServerURL(), collectionKey))
+
+	if err != nil {
+		return ExportedList{}, err
+	}
+
+	h := header.MediaContainer.Metadata[0]
+
+	return newExportedList(ListKindCollection, h.Title, h.Summary, h.Subtype, items.MediaContainer.Metadata), nil
+}
+
+func newExportedList(kind, title, summary, listType string, metadata []Metadata) ExportedList {
+	list := ExportedList{Kind: kind, Title: title, Summary: summary, Type: listType, Items: []ExportedListItem{}}
+
+	for _, m := range metadata {
+		item := ExportedListItem{
+			Title:    m.Title,
+			Type:     m.Type,
+			Show:     m.GrandparentTitle,
+			Year:     m.Year,
+			Duration: m.Duration,
+			GUIDs:    exportColumnValues[ColumnGUIDs](Directory{}, m),
+		}
+
+		if m.Type == string(MediaTypeEpisode) {
+			item.Season = m.ParentIndex
+			item.Episode = m.Index
+		}
+
+		if files := exportColumnValues[ColumnFilePath](Directory{}, m); len(files) > 0 {
+			item.File = files[0]
+		}
+
+		list.Items = append(list.Items, item)
+	}
+
+	return list
+}
+
+// WriteM3U writes the list as an extended m3u playlist of file paths. Items are matched by
+// file path only when such a playlist is imported elsewhere, keep the json form for ImportList.
+func (l ExportedList) WriteM3U(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "#EXTM3U\n#PLAYLIST:%s\n", l.Title)
+
+	for _, item := range l.Items {
+		if item.File == "" {
+			continue
+		}
+
+		title := item.Title
+
+		if item.Show != "" {
+			title = item.Show + " - " + title
+		}
+
+		fmt.Fprintf(bw, "#EXTINF:%d,%s\n%s\n", item.Duration/1000, title, item.File)
+	}
+
+	return bw.Flush()
+}
+
+// ImportList recreates a list exported with ExportPlaylist or ExportCollection on this server.
+// Items are looked up by GUID in the section with sectionKey, which collections are created in.
+func (p *Plex) ImportList(list ExportedList, sectionKey string) (ImportResult, error) {
+	if sectionKey == "" {
+		return ImportResult{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	if list.Title == "" {
+		return ImportResult{}, errors.New(ErrorTitleRequired)
+	}
+
+	index, err := p.guidIndex(sectionKey, list.Items)
+
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	var result ImportResult
+	var keys []string
+
+	for _, item := range list.Items {
+		key := ""
+
+		for _, guid := range item.GUIDs {
+			if key = index[guid]; key != "" {
+				break
+			}
+		}
+
+		if key == "" {
+			result.Unmatched = append(result.Unmatched, item)
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	result.Matched = len(keys)
+
+	if len(keys) == 0 {
+		return result, fmt.Errorf(ErrorCommon, "none of the items of "+list.Title+" were found")
+	}
+
+	machineID, err := p.serverMachineID()
+
+	if err != nil {
+		return result, err
+	}
+
+	params := url.Values{}
+	params.Set("title", list.Title)
+	params.Set("smart", "0")
+	params.Set("uri", LibraryURI(machineID, "/library/metadata/"+strings.Join(keys, ",")))
+
+	var query string
+
+	switch list.Kind {
+	case ListKindPlaylist:
+		params.Set("type", list.Type)
+		query = fmt.Sprintf("%s/playlists?%s", p.ServerURL(), params.Encode())
+	case ListKindCollection:
+		params.Set("type", strconv.Itoa(MediaType(list.Type).ID()))
+		params.Set("sectionId", sectionKey)
+		query = fmt.Sprintf("%s/library/collections?%s", p.ServerURL(), params.Encode())
+	default:
+		return result, fmt.Errorf(ErrorCommon, "unknown list kind "+list.Kind)
+	}
+
+	h := p.Headers
+	h.Accept = applicationJson
+
+	resp, err := p.post(query, nil, h)
+
+	if err != nil {
+		return result, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return result, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return result, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	var created MediaMetadata
+
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return result, err
+	}
+
+	if len(created.MediaContainer.Metadata) > 0 {
+		result.RatingKey = created.MediaContainer.Metadata[0].RatingKey
+	}
+
+	return result, nil
+}
+
+// guidIndex maps the GUIDs of the items of a section to their rating keys, listing the
+// section once for each type of items
+func (p *Plex) guidIndex(sectionKey string, items []ExportedListItem) (map[string]string, error) {
+	index := guidIndexWriter{}
+	seen := map[string]bool{}
+
+	for _, item := range items {
+		if seen[item.Type] {
+			continue
+		}
+
+		seen[item.Type] = true
+
+		opts := ExportOptions{Type: MediaType(item.Type), PageSize: defaultExportPageSize}
+
+		if err := p.exportSection(index, Directory{Key: sectionKey}, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return index, nil
+}
+
+// guidIndexWriter is an exportWriter collecting the rating key of every guid
+type guidIndexWriter map[string]string
+
+func (g guidIndexWriter) begin() error { return nil }
+
+func (g guidIndexWriter) write(section Directory, item Metadata) error {
+	for _, guid := range exportColumnValues[ColumnGUIDs](section, item) {
+		g[guid] = item.RatingKey
+	}
+
+	return nil
+}
+
+func (g guidIndexWriter) end() error { return nil }
@@ -0,0 +1,118 @@
+package plex
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPlex_ExportPlaylist(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/playlists/5":       `{"MediaContainer":{"Metadata":[{"title":"Heist night","playlistType":"video","summary":"crime"}]}}`,
+		"/playlists/5/items": `{"MediaContainer":{"Metadata":[{"title":"Heat","type":"movie","year":1995,"duration":10200000,"guid":"plex://movie/1","Guid":[{"id":"imdb://tt0113277"}],"Media":[{"Part":[{"file":"/movies/Heat.mkv"}]}]},{"title":"Pilot","type":"episode","grandparentTitle":"Leverage","parentIndex":1,"index":1,"guid":"plex://episode/9"}]}}`,
+	})
+
+	list, err := p.ExportPlaylist(5)
+	if err != nil {
+		t.Fatalf("ExportPlaylist() error = %v", err)
+	}
+
+	if list.Kind != ListKindPlaylist || list.Title != "Heist night" || list.Type != "video" || len(list.Items) != 2 {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	if movie := list.Items[0]; len(movie.GUIDs) != 2 || movie.File != "/movies/Heat.mkv" {
+		t.Errorf("unexpected movie: %+v", movie)
+	}
+
+	if episode := list.Items[1]; episode.Show != "Leverage" || episode.Season != 1 || episode.Episode != 1 {
+		t.Errorf("unexpected episode: %+v", episode)
+	}
+
+	var m3u bytes.Buffer
+
+	if err := list.WriteM3U(&m3u); err != nil {
+		t.Fatalf("WriteM3U() error = %v", err)
+	}
+
+	if expected := "#EXTM3U\n#PLAYLIST:Heist night\n#EXTINF:10200,Heat\n/movies/Heat.mkv\n"; m3u.String() != expected {
+		t.Errorf("unexpected m3u:\n%s", m3u.String())
+	}
+}
+
+func TestPlex_ImportList(t *testing.T) {
+	var created url.Values
+	var createdPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+
+		switch r.URL.Path {
+		case "/identity":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"machineIdentifier":"abc123"}}`))
+		case "/library/sections/1/all":
+			if r.URL.Query().Get("type") != "1" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+
+			_, _ = w.Write([]byte(`{"MediaContainer":{"totalSize":2,"Metadata":[{"ratingKey":"100","guid":"plex://movie/1","Guid":[{"id":"imdb://tt0113277"}]},{"ratingKey":"101","guid":"plex://movie/2"}]}}`))
+		case "/playlists", "/library/collections":
+			createdPath = r.URL.Path
+			created = r.URL.Query()
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[{"ratingKey":"555"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	list := ExportedList{
+		Kind:  ListKindCollection,
+		Title: "Mann",
+		Type:  "movie",
+		Items: []ExportedListItem{
+			{Title: "Thief", Type: "movie", GUIDs: []string{"plex://movie/3"}},
+			{Title: "Heat", Type: "movie", GUIDs: []string{"tmdb://949", "imdb://tt0113277"}},
+			{Title: "Collateral", Type: "movie", GUIDs: []string{"plex://movie/2"}},
+		},
+	}
+
+	result, err := p.ImportList(list, "1")
+	if err != nil {
+		t.Fatalf("ImportList() error = %v", err)
+	}
+
+	if result.RatingKey != "555" || result.Matched != 2 || len(result.Unmatched) != 1 || result.Unmatched[0].Title != "Thief" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if createdPath != "/library/collections" || created.Get("type") != "1" || created.Get("sectionId") != "1" || created.Get("title") != "Mann" {
+		t.Errorf("unexpected create request %s %v", createdPath, created)
+	}
+
+	if want := "server://abc123/com.plexapp.plugins.library/library/metadata/100,101"; created.Get("uri") != want {
+		t.Errorf("uri = %q, want %q", created.Get("uri"), want)
+	}
+
+	list.Kind = ListKindPlaylist
+	list.Type = "video"
+
+	if _, err := p.ImportList(list, "1"); err != nil {
+		t.Fatalf("ImportList() error = %v", err)
+	}
+
+	if createdPath != "/playlists" || created.Get("type") != "video" {
+		t.Errorf("unexpected create request %s %v", createdPath, created)
+	}
+
+	if _, err := p.ImportList(ExportedList{Kind: ListKindPlaylist, Title: "Empty", Items: []ExportedListItem{{Type: "movie"}}}, "1"); err == nil {
+		t.Error("expected an error when no item matches")
+	}
+}
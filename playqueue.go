@@ -0,0 +1,94 @@
+package plex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LibraryURI returns the uri plex uses to reference key, a path such as a station key or
+// /library/metadata/{id}, on the server with machineID
+func LibraryURI(machineID, key string) string {
+	return fmt.Sprintf("server://%s/com.plexapp.plugins.library%s", machineID, key)
+}
+
+// libraryItemURI returns the uri plex uses to reference a library item in sync and
+// optimize requests
+func libraryItemURI(sectionUUID, key string) string {
+	return fmt.Sprintf("library://%s/item/%s", sectionUUID, url.QueryEscape(key))
+}
+
+// CreatePlayQueue creates a play queue from uri, see LibraryURI. mediaType is the kind of
+// queue: "audio", "video" or "photo".
+func (p *Plex) CreatePlayQueue(uri, mediaType string, opts PlayQueueOptions) (PlayQueue, error) {
+	if uri == "" {
+		return PlayQueue{}, errors.New("uri is required")
+	}
+
+	params := url.Values{}
+	params.Set("uri", uri)
+	params.Set("type", mediaType)
+	params.Set("shuffle", boolToOneOrZero(opts.Shuffle))
+	params.Set("repeat", boolToOneOrZero(opts.Repeat))
+
+	query := fmt.Sprintf("%s/playQueues?%s", p.ServerURL(), params.Encode())
+
+	h := p.Headers
+	h.Accept = applicationJson
+
+	resp, err := p.post(query, nil, h)
+
+	if err != nil {
+		return PlayQueue{}, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return PlayQueue{}, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return PlayQueue{}, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	var result PlayQueue
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return PlayQueue{}, err
+	}
+
+	return result, nil
+}
+
+// CreateStationPlayQueue starts playback of a station, e.g. an artist radio returned by
+// GetArtistStations. The queue is continuous, plex keeps adding tracks as it is played.
+func (p *Plex) CreateStationPlayQueue(station Station, opts PlayQueueOptions) (PlayQueue, error) {
+	if !strings.HasPrefix(station.Key, "/library/") {
+		return PlayQueue{}, fmt.Errorf(ErrorCommon, "station key must start with /library/")
+	}
+
+	machineID, err := p.serverMachineID()
+
+	if err != nil {
+		return PlayQueue{}, err
+	}
+
+	return p.CreatePlayQueue(LibraryURI(machineID, station.Key), "audio", opts)
+}
+
+// serverMachineID returns the machine identifier of the server at p.URL
+func (p *Plex) serverMachineID() (string, error) {
+	identity, err := p.GetIdentity()
+
+	if err != nil {
+		return "", err
+	}
+
+	if identity.MachineIdentifier == "" {
+		return "", errors.New("could not fetch machine id")
+	}
+
+	return identity.MachineIdentifier, nil
+}
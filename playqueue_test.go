@@ -0,0 +1,120 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPlex_CreateStationPlayQueue(t *testing.T) {
+	var gotMethod string
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+
+		switch r.URL.Path {
+		case "/identity":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"machineIdentifier":"abc123"}}`))
+		case "/playQueues":
+			gotMethod = r.Method
+			gotQuery = r.URL.Query()
+			_, _ = w.Write([]byte(`{"MediaContainer":{"playQueueID":99,"playQueueTotalCount":2,"size":2,"Metadata":[{"ratingKey":"12"},{"ratingKey":"40"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	queue, err := p.CreateStationPlayQueue(Station{Key: "/library/metadata/10/station/xyz?type=10"}, PlayQueueOptions{Shuffle: true})
+	if err != nil {
+		t.Fatalf("CreateStationPlayQueue() error = %v", err)
+	}
+
+	if gotMethod != http.MethodPost {
+		t.Errorf("expected POST, got %s", gotMethod)
+	}
+
+	if want := "server://abc123/com.plexapp.plugins.library/library/metadata/10/station/xyz?type=10"; gotQuery.Get("uri") != want {
+		t.Errorf("uri = %q, want %q", gotQuery.Get("uri"), want)
+	}
+
+	if gotQuery.Get("type") != "audio" || gotQuery.Get("shuffle") != "1" || gotQuery.Get("repeat") != "0" {
+		t.Errorf("unexpected query %v", gotQuery)
+	}
+
+	if queue.MediaContainer.PlayQueueID != 99 || len(queue.MediaContainer.Metadata) != 2 {
+		t.Errorf("unexpected queue: %+v", queue.MediaContainer)
+	}
+
+	if _, err := p.CreateStationPlayQueue(Station{}, PlayQueueOptions{}); err == nil {
+		t.Error("expected an error for a station without key")
+	}
+}
+
+// Test a station of the section's station hub starts a station play queue
+func TestPlex_GetLibraryStations(t *testing.T) {
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+
+		switch r.URL.Path {
+		case "/hubs/sections/3":
+			if r.URL.Query().Get("includeStations") != "1" {
+				t.Errorf("expected the stations to be requested, got %v", r.URL.Query())
+			}
+
+			_, _ = w.Write([]byte(`{"MediaContainer":{"size":2,"Hub":[` +
+				`{"hubIdentifier":"music.recent.played.3","type":"album","Metadata":[{"key":"/library/metadata/11","title":"Discovery"}]},` +
+				`{"hubIdentifier":"music.stations.3","type":"station","Directory":[` +
+				`{"key":"/library/sections/3/stations/1/abc?type=10","title":"Library Radio","type":"playlist","radio":true},` +
+				`{"key":"/library/sections/3/stations/2/def?type=10","title":"Deep Cuts Radio","type":"playlist","radio":true}]}]}}`))
+		case "/identity":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"machineIdentifier":"abc123"}}`))
+		case "/playQueues":
+			gotQuery = r.URL.Query()
+			_, _ = w.Write([]byte(`{"MediaContainer":{"playQueueID":7,"size":1,"Metadata":[{"ratingKey":"12"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	stations, err := p.GetLibraryStations("3")
+	if err != nil {
+		t.Fatalf("GetLibraryStations() error = %v", err)
+	}
+
+	if len(stations) != 2 || stations[0].Title != "Library Radio" || !stations[1].Radio {
+		t.Fatalf("unexpected stations: %+v", stations)
+	}
+
+	queue, err := p.CreateStationPlayQueue(stations[0], PlayQueueOptions{})
+	if err != nil {
+		t.Fatalf("CreateStationPlayQueue() error = %v", err)
+	}
+
+	if want := "server://abc123/com.plexapp.plugins.library/library/sections/3/stations/1/abc?type=10"; gotQuery.Get("uri") != want {
+		t.Errorf("uri = %q, want %q", gotQuery.Get("uri"), want)
+	}
+
+	if queue.MediaContainer.PlayQueueID != 7 {
+		t.Errorf("unexpected queue: %+v", queue.MediaContainer)
+	}
+
+	if _, err := p.GetLibraryStations(""); err == nil {
+		t.Error("expected an error without a section")
+	}
+}
@@ -3,18 +3,22 @@ package plex
 // plex is a Plex Media Server and Plex.tv client
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,14 +26,12 @@ import (
 	"go.uber.org/zap"
 )
 
-var (
-	// plexURL is a variable so tests can override it (httptest) for integration testing.
-	plexURL = "https://plex.tv"
-)
-
 const (
 	applicationXml  = "application/xml"
 	applicationJson = "application/json"
+
+	// defaultPlexTVURL is used when a client has no PlexTVURL set
+	defaultPlexTVURL = "https://plex.tv"
 )
 
 func defaultHeaders() headers {
@@ -52,53 +54,403 @@ func defaultHeaders() headers {
 // Option configures a Plex client during creation.
 type Option func(*Plex)
 
-// WithInsecureSkipVerify instructs the client to skip TLS certificate verification.
-// This is insecure and should be used only for testing or in trusted networks.
-func WithInsecureSkipVerify() Option {
+// WithPlexTVURL points the client at a different plex.tv base url, e.g. a proxy or a mock server.
+func WithPlexTVURL(baseURL string) Option {
 	return func(p *Plex) {
-		if p.HTTPClient.Transport == nil {
-			p.HTTPClient.Transport = &http.Transport{}
+		p.PlexTVURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// plexTV returns the plex.tv base url of the client
+func (p *Plex) plexTV() string {
+	if p.PlexTVURL != "" {
+		return p.PlexTVURL
+	}
+
+	return defaultPlexTVURL
+}
+
+// plexTVOptions applies opts to an empty client so package level plex.tv functions
+// can honor the same options as New, sending their requests through it
+func plexTVOptions(opts []Option) *Plex {
+	p := Plex{
+		HTTPClient: http.Client{
+			Timeout: 3 * time.Second,
+		},
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&p)
+		}
+	}
+
+	return &p
+}
+
+// WithHTTPClient replaces the client used for api requests. Options are applied in
+// order, so pass WithInsecureSkipVerify after this option to combine the two.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Plex) {
+		if client != nil {
+			p.HTTPClient = *client
 		}
+	}
+}
 
-		if t, ok := p.HTTPClient.Transport.(*http.Transport); ok {
-			// copy to avoid mutating shared transports
-			nt := t.Clone()
-			nt.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-			p.HTTPClient.Transport = nt
+// WithClientIdentifier sets the X-Plex-Client-Identifier of the client. Plex ties tokens
+// and pins to it, so apps should persist it and reuse it across runs.
+func WithClientIdentifier(id string) Option {
+	return func(p *Plex) {
+		if id != "" {
+			p.ClientIdentifier = id
+			p.Headers.ClientIdentifier = id
 		}
+	}
+}
 
-		if p.DownloadClient.Transport == nil {
-			p.DownloadClient.Transport = &http.Transport{}
+// WithUserAgent overrides the X-Plex-Product, X-Plex-Version and X-Plex-Device headers.
+// Empty values keep the defaults.
+func WithUserAgent(product, version, device string) Option {
+	return func(p *Plex) {
+		if product != "" {
+			p.Headers.Product = product
+		}
+
+		if version != "" {
+			p.Headers.Version = version
 		}
 
-		if dt, ok := p.DownloadClient.Transport.(*http.Transport); ok {
-			ndt := dt.Clone()
-			ndt.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-			p.DownloadClient.Transport = ndt
+		if device != "" {
+			p.Headers.Device = device
+		}
+	}
+}
+
+// WithHeaders adds extra headers to every request. Calling it more than once merges the headers.
+func WithHeaders(h http.Header) Option {
+	return func(p *Plex) {
+		if p.ExtraHeaders == nil {
+			p.ExtraHeaders = http.Header{}
+		}
+
+		for key, values := range h {
+			for _, value := range values {
+				p.ExtraHeaders.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithLogger logs the method, url, status and latency of every request at debug level.
+// The plex token is redacted from logged urls and errors.
+func WithLogger(l Logger) Option {
+	return func(p *Plex) {
+		p.Logger = l
+	}
+}
+
+// WithBeforeRequest registers a hook that runs before every request, e.g. to refresh
+// credentials or add tracing headers. It also runs for requests answered by WithCache.
+func WithBeforeRequest(hook RequestHook) Option {
+	return func(p *Plex) {
+		if hook != nil {
+			p.BeforeRequest = append(p.BeforeRequest, hook)
+		}
+	}
+}
+
+// WithAfterResponse registers a hook that runs after every request, e.g. to record metrics.
+// Responses served by WithCache are passed to it too, without a round trip to the server.
+func WithAfterResponse(hook ResponseHook) Option {
+	return func(p *Plex) {
+		if hook != nil {
+			p.AfterResponse = append(p.AfterResponse, hook)
+		}
+	}
+}
+
+// WithTokenProvider asks provider for the token of every request, including websocket
+// subscriptions, instead of using the token given to New.
+func WithTokenProvider(provider TokenProvider) Option {
+	return func(p *Plex) {
+		p.TokenProvider = provider
+	}
+}
+
+// DownloadTransportOptions tunes the transport used by DownloadClient. Zero values
+// fall back to the defaults used by New.
+type DownloadTransportOptions struct {
+	// MaxIdleConnsPerHost is the number of keep-alive connections kept open per server
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits concurrent connections per server, 0 means no limit
+	MaxConnsPerHost int
+	// IdleConnTimeout closes keep-alive connections that have been idle for this long
+	IdleConnTimeout time.Duration
+	// KeepAlive is the interval between TCP keep-alive probes
+	KeepAlive time.Duration
+	// DisableHTTP2 forces HTTP/1.1, which can be faster for a few large files on a LAN
+	DisableHTTP2 bool
+}
+
+// defaultDownloadTransportOptions favour many parallel transfers to the same server
+var defaultDownloadTransportOptions = DownloadTransportOptions{
+	MaxIdleConnsPerHost: 16,
+	IdleConnTimeout:     90 * time.Second,
+	KeepAlive:           30 * time.Second,
+}
+
+// newDownloadTransport builds a transport for downloads from opts, keeping the proxy
+// and TLS settings of base when it is an *http.Transport.
+func newDownloadTransport(base http.RoundTripper, opts DownloadTransportOptions) *http.Transport {
+	defaults := defaultDownloadTransportOptions
+
+	if opts.MaxIdleConnsPerHost <= 0 {
+		opts.MaxIdleConnsPerHost = defaults.MaxIdleConnsPerHost
+	}
+
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = defaults.IdleConnTimeout
+	}
+
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = defaults.KeepAlive
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if bt := innerTransport(base); bt != nil {
+		t.Proxy = bt.Proxy
+
+		if bt.TLSClientConfig != nil {
+			t.TLSClientConfig = bt.TLSClientConfig.Clone()
 		}
+	}
+
+	t.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: opts.KeepAlive,
+	}).DialContext
+	t.MaxIdleConns = 0
+	t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	t.MaxConnsPerHost = opts.MaxConnsPerHost
+	t.IdleConnTimeout = opts.IdleConnTimeout
+	t.ForceAttemptHTTP2 = !opts.DisableHTTP2
+
+	if opts.DisableHTTP2 {
+		// a non-nil empty map disables the automatic HTTP/2 upgrade
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
 
-		// Configure per-client websocket dialer so websocket connections honor
-		// the same TLS settings. Clone the default dialer if present.
-		if websocket.DefaultDialer != nil {
-			d := *websocket.DefaultDialer
-			if d.TLSClientConfig == nil {
-				d.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-			} else {
-				// clone existing TLS config and set InsecureSkipVerify safely
-				if cloned := d.TLSClientConfig.Clone(); cloned != nil {
-					cloned.InsecureSkipVerify = true
-					d.TLSClientConfig = cloned
-				} else {
-					d.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-				}
+	return t
+}
+
+// WithDownloadTransport replaces the transport of DownloadClient with one tuned by opts,
+// e.g. to allow more parallel downloads from the same server.
+func WithDownloadTransport(opts DownloadTransportOptions) Option {
+	return func(p *Plex) {
+		p.DownloadClient.Transport = newDownloadTransport(p.DownloadClient.Transport, opts)
+	}
+}
+
+// WithInsecureSkipVerify instructs the client to skip TLS certificate verification.
+// This is insecure and should be used only for testing or in trusted networks.
+func WithInsecureSkipVerify() Option {
+	return func(p *Plex) {
+		p.configureTLS(insecureTLSConfig)
+	}
+}
+
+// WithRootCAs verifies server certificates against pool instead of the system roots,
+// e.g. for a server using a certificate signed by a private CA.
+// Like WithInsecureSkipVerify, pass it after WithHTTPClient.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(p *Plex) {
+		p.configureTLS(func(cfg *tls.Config) *tls.Config {
+			if cfg == nil {
+				return &tls.Config{RootCAs: pool}
+			}
+
+			cloned := cfg.Clone()
+			cloned.RootCAs = pool
+
+			return cloned
+		})
+	}
+}
+
+// WithClientCertificate presents cert to servers that request a client certificate, e.g. a
+// reverse proxy in front of the server requiring mutual TLS.
+// Like WithInsecureSkipVerify, pass it after WithHTTPClient.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(p *Plex) {
+		p.configureTLS(func(cfg *tls.Config) *tls.Config {
+			if cfg == nil {
+				return &tls.Config{Certificates: []tls.Certificate{cert}}
 			}
+
+			cloned := cfg.Clone()
+			// copy the certificates, appending could write to the array shared with cfg
+			cloned.Certificates = append(append([]tls.Certificate{}, cfg.Certificates...), cert)
+
+			return cloned
+		})
+	}
+}
+
+// configureTLS replaces the TLS configuration of both HTTP clients and of websockets with
+// the one returned by update, which must not modify the configuration it is given
+func (p *Plex) configureTLS(update func(cfg *tls.Config) *tls.Config) {
+	p.HTTPClient.Transport = updateTransport(p.HTTPClient.Transport, func(t *http.Transport) {
+		t.TLSClientConfig = update(t.TLSClientConfig)
+	})
+
+	p.DownloadClient.Transport = updateTransport(p.DownloadClient.Transport, func(t *http.Transport) {
+		t.TLSClientConfig = update(t.TLSClientConfig)
+	})
+
+	// Configure per-client websocket dialer so websocket connections honor
+	// the same TLS settings. Clone the default dialer if present.
+	if p.WebsocketDialer != nil {
+		d := *p.WebsocketDialer
+		d.TLSClientConfig = update(d.TLSClientConfig)
+		p.WebsocketDialer = &d
+	} else if websocket.DefaultDialer != nil {
+		d := *websocket.DefaultDialer
+		d.TLSClientConfig = update(d.TLSClientConfig)
+		p.WebsocketDialer = &d
+	} else {
+		p.WebsocketDialer = &websocket.Dialer{TLSClientConfig: update(nil)}
+	}
+}
+
+// WithProxy sends requests through the proxy at proxyURL instead of the one set by the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables, which are honored by default.
+// A nil url disables proxying. Like WithInsecureSkipVerify, pass it after WithHTTPClient.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(p *Plex) {
+		proxy := http.ProxyURL(proxyURL)
+
+		p.HTTPClient.Transport = updateTransport(p.HTTPClient.Transport, func(t *http.Transport) {
+			t.Proxy = proxy
+		})
+
+		p.DownloadClient.Transport = updateTransport(p.DownloadClient.Transport, func(t *http.Transport) {
+			t.Proxy = proxy
+		})
+
+		if p.WebsocketDialer != nil {
+			d := *p.WebsocketDialer
+			d.Proxy = proxy
 			p.WebsocketDialer = &d
-		} else {
-			p.WebsocketDialer = &websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
 		}
 	}
 }
 
+// DialContextFunc dials a connection to the server, see net.Dialer.DialContext
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithDialer opens every connection, including websockets, with dial. It lets the client
+// reach the server over a unix socket, an SSH tunnel or a userspace network stack.
+// Like WithInsecureSkipVerify, pass it after WithHTTPClient.
+func WithDialer(dial DialContextFunc) Option {
+	return func(p *Plex) {
+		if dial == nil {
+			return
+		}
+
+		p.HTTPClient.Transport = updateTransport(p.HTTPClient.Transport, func(t *http.Transport) {
+			t.DialContext = dial
+		})
+
+		p.DownloadClient.Transport = updateTransport(p.DownloadClient.Transport, func(t *http.Transport) {
+			t.DialContext = dial
+		})
+
+		// start from the dialer websockets would use, with the TLS and proxy of the transport
+		d := websocket.Dialer{}
+
+		if wd := p.websocketDialer(); wd != nil {
+			d = *wd
+		}
+
+		d.NetDialContext = dial
+		p.WebsocketDialer = &d
+	}
+}
+
+// TransportWrapper is implemented by round trippers wrapping the transport of a client,
+// such as the one installed by metrics.WithCollector. Options like WithProxy or WithDialer
+// then modify the *http.Transport underneath and keep the wrapper, and websockets use its
+// proxy and TLS settings.
+type TransportWrapper interface {
+	http.RoundTripper
+	// Unwrap returns the wrapped round tripper
+	Unwrap() http.RoundTripper
+	// Wrap returns a copy of the wrapper around next
+	Wrap(next http.RoundTripper) http.RoundTripper
+}
+
+// updateTransport returns rt with a copy of its *http.Transport changed by update, keeping
+// the wrappers around it. rt is returned as is when there is no *http.Transport to change.
+func updateTransport(rt http.RoundTripper, update func(t *http.Transport)) http.RoundTripper {
+	if w, ok := rt.(TransportWrapper); ok {
+		return w.Wrap(updateTransport(w.Unwrap(), update))
+	}
+
+	t, ok := cloneTransport(rt)
+
+	if !ok {
+		return rt
+	}
+
+	update(t)
+
+	return t
+}
+
+// innerTransport returns the *http.Transport of rt, unwrapping TransportWrappers, or nil
+func innerTransport(rt http.RoundTripper) *http.Transport {
+	for {
+		switch t := rt.(type) {
+		case *http.Transport:
+			return t
+		case TransportWrapper:
+			rt = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// cloneTransport returns a copy of rt that options can modify without touching shared
+// transports. A nil rt starts from http.DefaultTransport so environment proxies keep
+// working, ok is false when rt is not an *http.Transport.
+func cloneTransport(rt http.RoundTripper) (t *http.Transport, ok bool) {
+	if rt == nil {
+		return http.DefaultTransport.(*http.Transport).Clone(), true
+	}
+
+	if t, ok = rt.(*http.Transport); !ok || t == nil {
+		return nil, false
+	}
+
+	return t.Clone(), true
+}
+
+// insecureTLSConfig returns a copy of cfg with certificate verification disabled,
+// keeping any other settings such as root CAs or client certificates.
+func insecureTLSConfig(cfg *tls.Config) *tls.Config {
+	if cfg == nil {
+		return &tls.Config{InsecureSkipVerify: true}
+	}
+
+	cloned := cfg.Clone()
+	cloned.InsecureSkipVerify = true
+
+	return cloned
+}
+
 // New creates a new plex instance that is required to
 // to make requests to your Plex Media Server
 func New(baseURL, token string, opts ...Option) (*Plex, error) {
@@ -114,7 +466,9 @@ func New(baseURL, token string, opts ...Option) (*Plex, error) {
 		Timeout: 3 * time.Second,
 	}
 
-	p.DownloadClient = http.Client{}
+	p.DownloadClient = http.Client{
+		Transport: newDownloadTransport(nil, defaultDownloadTransportOptions),
+	}
 
 	// Honor environment variable to enable insecure TLS behavior when set to
 	// SKIP_TLS_VERIFICATION=1 or SKIP_TLS_VERIFICATION=true (case-insensitive).
@@ -168,8 +522,8 @@ func New(baseURL, token string, opts ...Option) (*Plex, error) {
 }
 
 // SignIn creates a plex instance using a user name and password instead of an auth
-// token.
-func SignIn(username, password string) (*Plex, error) {
+// token. Options are applied to the returned instance.
+func SignIn(username, password string, opts ...Option) (*Plex, error) {
 	id, err := uuid.NewRandom()
 
 	if err != nil {
@@ -183,7 +537,13 @@ func SignIn(username, password string) (*Plex, error) {
 		},
 	}
 
-	query := plexURL + "/api/v2/users/signin"
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&p)
+		}
+	}
+
+	query := p.plexTV() + "/api/v2/users/signin"
 
 	// Encode login in the specific format they require
 	body := url.Values{}
@@ -205,7 +565,7 @@ func SignIn(username, password string) (*Plex, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusCreated {
-		return &Plex{}, errors.New(resp.Status)
+		return &Plex{}, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	var signInResponse SignInResponse
@@ -225,121 +585,144 @@ func (p *Plex) Search(title string) (SearchResults, error) {
 		return SearchResults{}, fmt.Errorf(ErrorCommon, ErrorTitleRequired)
 	}
 
-	title = url.QueryEscape(title)
-	query := p.URL + "/search?query=" + title
+	return p.search(title, SearchOptions{})
+}
 
-	var results SearchResults
+// search runs a search scoped by the type and section of opts
+func (p *Plex) search(title string, opts SearchOptions) (SearchResults, error) {
+	params := url.Values{}
+	params.Set("query", title)
 
-	resp, err := p.get(query, p.Headers)
+	if opts.Type != "" {
+		id := opts.Type.ID()
 
-	if err != nil {
-		return SearchResults{}, err
-	}
+		if id == 0 {
+			return SearchResults{}, fmt.Errorf(ErrorCommon, "unknown media type "+string(opts.Type))
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return SearchResults{}, fmt.Errorf(ErrorServer, resp.Status)
+		params.Set("type", strconv.Itoa(id))
 	}
 
-	defer safeClose(resp.Body)
-
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return SearchResults{}, err
+	if opts.SectionID != "" {
+		params.Set("sectionId", opts.SectionID)
 	}
 
-	return results, nil
+	query := p.ServerURL() + "/search?" + params.Encode()
+
+	return getContainer[SearchMediaContainer](p, query)
 }
 
-// GetMetadata can get some media info
+// GetMetadata can get some media info. key may be a comma-separated list of rating keys,
+// e.g. "1,2,3", to fetch several items in one request.
 func (p *Plex) GetMetadata(key string) (MediaMetadata, error) {
 	if key == "" {
 		return MediaMetadata{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
 	}
 
-	var results MediaMetadata
+	query := fmt.Sprintf("%s/library/metadata/%s", p.ServerURL(), key)
 
-	query := fmt.Sprintf("%s/library/metadata/%s", p.URL, key)
-
-	newHeaders := p.Headers
-
-	resp, err := p.get(query, newHeaders)
+	return getContainer[MediaContainer](p, query)
+}
 
-	if err != nil {
-		return results, err
+// GetMetadataWithOptions is GetMetadata that also requests the optional data selected in opts
+func (p *Plex) GetMetadataWithOptions(key string, opts MetadataOptions) (MediaMetadata, error) {
+	if key == "" {
+		return MediaMetadata{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return results, fmt.Errorf(ErrorServer, resp.Status)
+	query := fmt.Sprintf("%s/library/metadata/%s", p.ServerURL(), key)
+
+	if params := opts.values().Encode(); params != "" {
+		query += "?" + params
 	}
 
-	defer safeClose(resp.Body)
+	return getContainer[MediaContainer](p, query)
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return results, err
+// values returns the query parameters for the selected options
+func (o MetadataOptions) values() url.Values {
+	v := url.Values{}
+
+	flags := []struct {
+		name string
+		set  bool
+	}{
+		{"includeGuids", o.IncludeGuids},
+		{"includeMarkers", o.IncludeMarkers},
+		{"includeChapters", o.IncludeChapters},
+		{"includeExtras", o.IncludeExtras},
+		{"includeReviews", o.IncludeReviews},
+		{"includeRelated", o.IncludeRelated},
+		{"checkFiles", o.CheckFiles},
+	}
+
+	for _, flag := range flags {
+		if flag.set {
+			v.Set(flag.name, "1")
+		}
 	}
 
-	return results, nil
+	return v
 }
 
-// GetMetadataChildren can get a show's season titles. My use-case would be getting the season titles after using Search()
-func (p *Plex) GetMetadataChildren(key string) (MetadataChildren, error) {
-	if key == "" {
-		return MetadataChildren{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+// DefaultMetadataBatchSize is the number of rating keys GetMetadataBatch requests at once
+const DefaultMetadataBatchSize = 50
+
+// GetMetadataBatch fetches the metadata of many items, requesting them batchSize keys at a time
+// to keep urls reasonably short. A batchSize of 0 uses DefaultMetadataBatchSize.
+// Items are returned in the order plex returns them, unknown keys are omitted.
+func (p *Plex) GetMetadataBatch(keys []string, batchSize int) ([]Metadata, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultMetadataBatchSize
 	}
 
-	query := fmt.Sprintf("%s/library/metadata/%s/children", p.URL, key)
+	var results []Metadata
 
-	newHeaders := p.Headers
-
-	resp, err := p.get(query, newHeaders)
+	for start := 0; start < len(keys); start += batchSize {
+		end := start + batchSize
 
-	if err != nil {
-		return MetadataChildren{}, err
-	}
+		if end > len(keys) {
+			end = len(keys)
+		}
 
-	// Unauthorized
-	if resp.StatusCode == http.StatusUnauthorized {
-		return MetadataChildren{}, errors.New(ErrorNotAuthorized)
-	}
+		for _, key := range keys[start:end] {
+			if key == "" {
+				return nil, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+			}
+		}
 
-	defer safeClose(resp.Body)
+		metadata, err := p.GetMetadata(strings.Join(keys[start:end], ","))
 
-	var results MetadataChildren
+		if err != nil {
+			return nil, err
+		}
 
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return MetadataChildren{}, err
+		results = append(results, metadata.MediaContainer.Metadata...)
 	}
 
 	return results, nil
 }
 
-// GetEpisodes returns episodes of a season of a show
-func (p *Plex) GetEpisodes(key string) (SearchResultsEpisode, error) {
+// GetMetadataChildren can get a show's season titles. My use-case would be getting the season titles after using Search()
+func (p *Plex) GetMetadataChildren(key string) (MetadataChildren, error) {
 	if key == "" {
-		return SearchResultsEpisode{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+		return MetadataChildren{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
 	}
 
-	query := fmt.Sprintf("%s/library/metadata/%s/children", p.URL, key)
+	query := fmt.Sprintf("%s/library/metadata/%s/children", p.ServerURL(), key)
 
-	resp, err := p.get(query, p.Headers)
-
-	if err != nil {
-		return SearchResultsEpisode{}, err
-	}
+	return getContainer[MediaContainer](p, query)
+}
 
-	// Unauthorized
-	if resp.StatusCode == http.StatusUnauthorized {
-		return SearchResultsEpisode{}, errors.New(ErrorNotAuthorized)
+// GetEpisodes returns episodes of a season of a show
+func (p *Plex) GetEpisodes(key string) (SearchResultsEpisode, error) {
+	if key == "" {
+		return SearchResultsEpisode{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
 	}
 
-	defer safeClose(resp.Body)
-
-	var results SearchResultsEpisode
-
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return SearchResultsEpisode{}, err
-	}
+	query := fmt.Sprintf("%s/library/metadata/%s/children", p.ServerURL(), key)
 
-	return results, nil
+	return getContainer[MediaContainer](p, query)
 }
 
 // GetEpisode returns a single episode of a show.
@@ -348,161 +731,197 @@ func (p *Plex) GetEpisode(key string) (SearchResultsEpisode, error) {
 		return SearchResultsEpisode{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
 	}
 
-	query := fmt.Sprintf("%s/library/metadata/%s", p.URL, key)
-
-	resp, err := p.get(query, p.Headers)
+	query := fmt.Sprintf("%s/library/metadata/%s", p.ServerURL(), key)
 
-	if err != nil {
-		return SearchResultsEpisode{}, err
-	}
+	return getContainer[MediaContainer](p, query)
+}
 
-	// Unauthorized
-	if resp.StatusCode == http.StatusUnauthorized {
-		return SearchResultsEpisode{}, errors.New(ErrorNotAuthorized)
+// GetAllEpisodes returns every episode of a show across all of its seasons
+func (p *Plex) GetAllEpisodes(showKey string) (SearchResultsEpisode, error) {
+	if showKey == "" {
+		return SearchResultsEpisode{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
 	}
 
-	defer safeClose(resp.Body)
+	query := fmt.Sprintf("%s/library/metadata/%s/allLeaves", p.ServerURL(), showKey)
 
-	var results SearchResultsEpisode
+	return getContainer[MediaContainer](p, query)
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return SearchResultsEpisode{}, err
+// GetSeasons returns the seasons of a show with their episode and watched episode counts
+func (p *Plex) GetSeasons(showKey string) (Seasons, error) {
+	if showKey == "" {
+		return Seasons{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
 	}
 
-	return results, nil
-}
+	query := fmt.Sprintf("%s/library/metadata/%s/children", p.ServerURL(), showKey)
 
-// GetOnDeck gets the on-deck videos.
-func (p *Plex) GetOnDeck() (SearchResultsEpisode, error) {
-	query := fmt.Sprintf("%s/library/onDeck", p.URL)
+	return getContainer[SeasonContainer](p, query)
+}
 
-	resp, err := p.get(query, p.Headers)
+// GetNextUnwatched returns the episode of a show to watch next: an episode that was started
+// but not finished, otherwise the first unwatched episode after the last watched one.
+// Specials (season 0) are skipped. ErrorNoUnwatched is returned when the show is fully watched.
+func (p *Plex) GetNextUnwatched(showKey string) (Metadata, error) {
+	episodes, err := p.GetAllEpisodes(showKey)
 
 	if err != nil {
-		return SearchResultsEpisode{}, err
+		return Metadata{}, err
 	}
 
-	// Unauthorized
-	if resp.StatusCode == http.StatusUnauthorized {
-		return SearchResultsEpisode{}, errors.New(ErrorNotAuthorized)
+	var ordered []Metadata
+
+	for _, episode := range episodes.MediaContainer.Metadata {
+		if episode.ParentIndex > 0 {
+			ordered = append(ordered, episode)
+		}
 	}
 
-	defer safeClose(resp.Body)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].ParentIndex != ordered[j].ParentIndex {
+			return ordered[i].ParentIndex < ordered[j].ParentIndex
+		}
 
-	var results SearchResultsEpisode
+		return ordered[i].Index < ordered[j].Index
+	})
 
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return SearchResultsEpisode{}, err
-	}
+	inProgress := -1
+	lastWatched := -1
 
-	return results, nil
-}
+	for i, episode := range ordered {
+		if episode.ViewCount > 0 {
+			lastWatched = i
+		} else if episode.ViewOffset > 0 && (inProgress == -1 || episode.LastViewedAt > ordered[inProgress].LastViewedAt) {
+			inProgress = i
+		}
+	}
 
-// Download media associated with metadata
-func (p *Plex) Download(meta Metadata, path string, createFolders bool, skipIfExists bool) error {
+	if inProgress != -1 {
+		return ordered[inProgress], nil
+	}
 
-	if len(meta.Media) == 0 {
-		return fmt.Errorf("no media associated with metadata, skipping")
+	for _, episode := range ordered[lastWatched+1:] {
+		if episode.ViewCount == 0 {
+			return episode, nil
+		}
 	}
 
-	path = filepath.Join(path)
-	if createFolders {
+	return Metadata{}, errors.New(ErrorNoUnwatched)
+}
 
-		if meta.ParentTitle != "" && meta.GrandparentTitle != "" { // for tv shows and music
-			path = filepath.Join(path, meta.GrandparentTitle, meta.ParentTitle)
-		} else { // for movies
-			path = filepath.Join(path, meta.Title)
-		}
-		if err := os.MkdirAll(path, 0700); err != nil {
-			return err
-		}
+// GetOnDeck gets the on-deck videos.
+func (p *Plex) GetOnDeck() (SearchResultsEpisode, error) {
+	query := fmt.Sprintf("%s/library/onDeck", p.ServerURL())
+
+	return getContainer[MediaContainer](p, query)
+}
+
+// GetSectionOnDeck gets the on-deck videos of a single library section. Results are paged
+// with start and size, a size of 0 returns every item. Offset and TotalSize of the returned
+// container are set by plex when paging.
+func (p *Plex) GetSectionOnDeck(sectionKey string, start, size int) (SearchResultsEpisode, error) {
+	if sectionKey == "" {
+		return SearchResultsEpisode{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
 	}
 
-	for _, media := range meta.Media {
+	query := fmt.Sprintf("%s/library/sections/%s/onDeck", p.ServerURL(), sectionKey)
 
-		for _, part := range media.Part {
+	if size > 0 {
+		query += fmt.Sprintf("?X-Plex-Container-Start=%d&X-Plex-Container-Size=%d", start, size)
+	}
 
-			// get original filename from original path
-			split := strings.Split(part.File, "/")
-			file := split[len(split)-1]
+	return getContainer[MediaContainer](p, query)
+}
 
-			fp := filepath.Join(path, file)
+// Download media associated with metadata. Existing files are overwritten unless
+// skipIfExists is set, see DownloadWithOptions for more control.
+func (p *Plex) Download(meta Metadata, path string, createFolders bool, skipIfExists bool) error {
+	opts := DownloadOptions{CreateFolders: createFolders}
 
-			_, exists := os.Stat(fp)
+	if skipIfExists {
+		opts.IfExists = DownloadSkip
+	}
 
-			if exists == nil && skipIfExists {
-				return nil
-			}
+	return p.DownloadWithOptions(meta, path, opts)
+}
 
-			query := fmt.Sprintf("%s%s?download=1", p.URL, part.Key)
+// GetPlaylist gets all videos in a playlist.
+func (p *Plex) GetPlaylist(key int) (SearchResultsEpisode, error) {
+	query := fmt.Sprintf("%s/playlists/%d/items", p.ServerURL(), key)
 
-			resp, err := p.grab(query, p.Headers)
-			if err != nil {
-				return err
-			}
+	return getContainer[MediaContainer](p, query)
+}
 
-			// Unauthorized
-			if resp.StatusCode == http.StatusUnauthorized {
-				return errors.New(ErrorNotAuthorized)
-			}
+// GetThumbnail returns the response of a request to pms thumbnail
+// My ideal use case would be to proxy a request to pms without exposing the plex token
+func (p *Plex) GetThumbnail(key, thumbnailID string) (*http.Response, error) {
+	query := fmt.Sprintf("%s/library/metadata/%s/thumb/%s", p.ServerURL(), key, thumbnailID)
 
-			out, err := os.Create(fp)
-			if err != nil {
-				return err
-			}
-			defer safeClose(out)
+	return p.get(query, p.Headers)
+}
 
-			_, err = io.Copy(out, resp.Body)
+// GetArt returns the response of a request to pms background art, like GetThumbnail
+func (p *Plex) GetArt(key, artID string) (*http.Response, error) {
+	query := fmt.Sprintf("%s/library/metadata/%s/art/%s", p.ServerURL(), key, artID)
 
-			if err != nil {
-				return err
-			}
+	return p.get(query, p.Headers)
+}
 
-		}
-	}
+// ThumbnailURL returns the url of a thumbnail including the token, e.g. for an <img> tag
+func (p *Plex) ThumbnailURL(key, thumbnailID string) (string, error) {
+	return p.AuthenticatedURL(fmt.Sprintf("/library/metadata/%s/thumb/%s", key, thumbnailID))
+}
 
-	return nil
+// ArtURL returns the url of background art including the token, e.g. for an <img> tag
+func (p *Plex) ArtURL(key, artID string) (string, error) {
+	return p.AuthenticatedURL(fmt.Sprintf("/library/metadata/%s/art/%s", key, artID))
 }
 
-// GetPlaylist gets all videos in a playlist.
-func (p *Plex) GetPlaylist(key int) (SearchResultsEpisode, error) {
-	query := fmt.Sprintf("%s/playlists/%d/items", p.URL, key)
+// AuthenticatedURL returns the url of path on your server with the token added, e.g. for
+// the Thumb or Art of an item. Anyone with the url can use the token, only hand it to
+// clients you would give the token to.
+func (p *Plex) AuthenticatedURL(path string) (string, error) {
+	return p.AuthenticatedURLWithContext(context.Background(), path)
+}
 
-	resp, err := p.get(query, p.Headers)
+// AuthenticatedURLWithContext is like AuthenticatedURL, passing ctx to the TokenProvider
+func (p *Plex) AuthenticatedURLWithContext(ctx context.Context, path string) (string, error) {
+	token, err := p.currentToken(ctx)
 
 	if err != nil {
-		return SearchResultsEpisode{}, err
+		return "", err
 	}
 
-	// Unauthorized
-	if resp.StatusCode == http.StatusUnauthorized {
-		return SearchResultsEpisode{}, errors.New(ErrorNotAuthorized)
-	} else if resp.StatusCode != http.StatusOK {
-		return SearchResultsEpisode{}, fmt.Errorf(ErrorServer, resp.Status)
+	u, err := url.Parse(p.ServerURL() + path)
+
+	if err != nil {
+		return "", err
 	}
 
-	defer safeClose(resp.Body)
+	query := u.Query()
+	query.Set("X-Plex-Token", token)
+	u.RawQuery = query.Encode()
 
-	var results SearchResultsEpisode
+	return u.String(), nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return SearchResultsEpisode{}, err
+// currentToken returns the token to send, asking the TokenProvider when there is one
+func (p *Plex) currentToken(ctx context.Context) (string, error) {
+	if p.TokenProvider == nil {
+		return p.Token, nil
 	}
 
-	return results, nil
-}
+	token, err := p.TokenProvider.Token(ctx)
 
-// GetThumbnail returns the response of a request to pms thumbnail
-// My ideal use case would be to proxy a request to pms without exposing the plex token
-func (p *Plex) GetThumbnail(key, thumbnailID string) (*http.Response, error) {
-	query := fmt.Sprintf("%s/library/metadata/%s/thumb/%s", p.URL, key, thumbnailID)
+	if err != nil {
+		return "", fmt.Errorf("token provider: %w", err)
+	}
 
-	return p.get(query, p.Headers)
+	return token, nil
 }
 
 // Test your connection to your Plex Media Server
 func (p *Plex) Test() (bool, error) {
-	resp, err := p.get(plexURL+"/api/servers", p.Headers)
+	resp, err := p.get(p.plexTV()+"/api/servers", p.Headers)
 
 	if err != nil {
 		return false, err
@@ -511,9 +930,9 @@ func (p *Plex) Test() (bool, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return false, errors.New(ErrorNotAuthorized)
+		return false, p.responseError(resp, errors.New(ErrorNotAuthorized))
 	} else if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf(ErrorServerReplied, resp.StatusCode)
+		return false, p.responseError(resp, fmt.Errorf(ErrorServerReplied, resp.StatusCode))
 	}
 
 	return true, nil
@@ -526,7 +945,7 @@ func (p *Plex) KillTranscodeSession(sessionKey string) (bool, error) {
 		return false, errors.New(ErrorMissingSessionKey)
 	}
 
-	query := p.URL + "/video/:/transcode/universal/stop?session=" + sessionKey
+	query := p.ServerURL() + "/video/:/transcode/universal/stop?session=" + sessionKey
 
 	resp, err := p.get(query, p.Headers)
 
@@ -537,9 +956,9 @@ func (p *Plex) KillTranscodeSession(sessionKey string) (bool, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return false, errors.New(ErrorNotAuthorized)
+		return false, p.responseError(resp, errors.New(ErrorNotAuthorized))
 	} else if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf(ErrorServerReplied, resp.StatusCode)
+		return false, p.responseError(resp, fmt.Errorf(ErrorServerReplied, resp.StatusCode))
 	}
 
 	return true, nil
@@ -547,33 +966,14 @@ func (p *Plex) KillTranscodeSession(sessionKey string) (bool, error) {
 
 // GetTranscodeSessions retrieves a list of all active transcode sessions
 func (p *Plex) GetTranscodeSessions() (TranscodeSessionsResponse, error) {
-	var result TranscodeSessionsResponse
-
-	query := p.URL + "/transcode/sessions"
-
-	resp, err := p.get(query, p.Headers)
-
-	if err != nil {
-		return result, err
-	}
-
-	defer safeClose(resp.Body)
-
-	if resp.StatusCode == http.StatusUnauthorized {
-		return result, errors.New(ErrorNotAuthorized)
-	} else if resp.StatusCode != http.StatusOK {
-		return result, fmt.Errorf(ErrorServerReplied, resp.StatusCode)
-	}
-
-	return result, json.NewDecoder(resp.Body).Decode(&result)
-
+	return getContainer[TranscodeSessionsContainer](p, p.ServerURL()+"/transcode/sessions")
 }
 
-// GetPlexTokens not sure if it works
-func (p *Plex) GetPlexTokens(token string) (DevicesResponse, error) {
-	var result DevicesResponse
+// GetPlexTokens lists the devices signed in to your plex.tv account with their tokens
+func (p *Plex) GetPlexTokens(token string) ([]DevicesResponse, error) {
+	var result []DevicesResponse
 
-	query := plexURL + "/devices.json"
+	query := p.plexTV() + "/devices.json"
 
 	resp, err := p.get(query, p.Headers)
 
@@ -584,9 +984,9 @@ func (p *Plex) GetPlexTokens(token string) (DevicesResponse, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return result, errors.New(ErrorNotAuthorized)
+		return result, p.responseError(resp, errors.New(ErrorNotAuthorized))
 	} else if resp.StatusCode != http.StatusOK {
-		return result, fmt.Errorf(ErrorServerReplied, resp.StatusCode)
+		return result, p.responseError(resp, fmt.Errorf(ErrorServerReplied, resp.StatusCode))
 	}
 
 	return result, json.NewDecoder(resp.Body).Decode(&result)
@@ -596,7 +996,7 @@ func (p *Plex) GetPlexTokens(token string) (DevicesResponse, error) {
 func (p *Plex) DeletePlexToken(token string) (bool, error) {
 	var result bool
 
-	query := plexURL + "/devices/" + token + ".json"
+	query := p.plexTV() + "/devices/" + token + ".json"
 
 	resp, err := p.get(query, p.Headers)
 
@@ -607,9 +1007,9 @@ func (p *Plex) DeletePlexToken(token string) (bool, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return result, errors.New(ErrorNotAuthorized)
+		return result, p.responseError(resp, errors.New(ErrorNotAuthorized))
 	} else if resp.StatusCode != http.StatusOK {
-		return result, fmt.Errorf(ErrorServerReplied, resp.StatusCode)
+		return result, p.responseError(resp, fmt.Errorf(ErrorServerReplied, resp.StatusCode))
 	}
 
 	return result, json.NewDecoder(resp.Body).Decode(&result)
@@ -621,9 +1021,9 @@ func (p *Plex) GetFriends() ([]Friends, error) {
 	var plexFriendsResp friendsResponse
 
 	// Prefer the instance URL if set (testability / local servers). Fall back to plex.tv.
-	base := plexURL
-	if p.URL != "" {
-		base = p.URL
+	base := p.plexTV()
+	if p.ServerURL() != "" {
+		base = p.ServerURL()
 	}
 
 	query := base + "/api/users"
@@ -641,9 +1041,9 @@ func (p *Plex) GetFriends() ([]Friends, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return []Friends{}, errors.New(ErrorNotAuthorized)
+		return []Friends{}, p.responseError(resp, errors.New(ErrorNotAuthorized))
 	} else if resp.StatusCode != http.StatusOK {
-		return []Friends{}, fmt.Errorf(ErrorServerReplied, resp.StatusCode)
+		return []Friends{}, p.responseError(resp, fmt.Errorf(ErrorServerReplied, resp.StatusCode))
 	}
 
 	// Stream-decode the XML response to avoid buffering the entire body into memory.
@@ -662,7 +1062,7 @@ func (p *Plex) GetFriends() ([]Friends, error) {
 // RemoveFriend from your friend's list which stops access to your Plex server
 func (p *Plex) RemoveFriend(id string) (bool, error) {
 
-	query := plexURL + "/api/friends/" + id
+	query := p.plexTV() + "/api/friends/" + id
 
 	resp, err := p.delete(query, p.Headers)
 
@@ -673,7 +1073,7 @@ func (p *Plex) RemoveFriend(id string) (bool, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
-		return false, errors.New(resp.Status)
+		return false, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	result := new(resultResponse)
@@ -686,14 +1086,16 @@ func (p *Plex) RemoveFriend(id string) (bool, error) {
 }
 
 // InviteFriend to access your Plex server. Add restrictions to media or give them full access.
-func (p *Plex) InviteFriend(params InviteFriendParams) error {
+// The returned SharedServer's PendingInvite identifies the invite until it is accepted.
+func (p *Plex) InviteFriend(params InviteFriendParams) (SharedServer, error) {
+	var result SharedServer
 
 	label := url.QueryEscape(params.Label)
 
 	// Prefer the instance URL if set (testability / local servers). Fall back to plex.tv.
-	base := plexURL
-	if p.URL != "" {
-		base = p.URL
+	base := p.plexTV()
+	if p.ServerURL() != "" {
+		base = p.ServerURL()
 	}
 
 	query := fmt.Sprintf("%s/api/v2/shared_servers", base)
@@ -704,11 +1106,24 @@ func (p *Plex) InviteFriend(params InviteFriendParams) error {
 	requestBody.InvitedEmail = params.UsernameOrEmail
 	requestBody.LibrarySectionIDs = params.LibraryIDs
 
-	settings := inviteFriendSettings{}
+	settings := inviteFriendSettings{
+		AllowCameraUpload: boolToOneOrZero(params.AllowCameraUpload),
+		AllowChannels:     boolToOneOrZero(params.AllowChannels),
+		AllowSync:         boolToOneOrZero(params.AllowSync),
+		FilterMovies:      params.FilterMovies,
+		FilterMusic:       params.FilterMusic,
+		FilterPhotos:      params.FilterPhotos,
+		FilterTelevision:  params.FilterTelevision,
+	}
 
 	if label != "" {
-		settings.FilterMovies = fmt.Sprintf("label=%s", label)
-		settings.FilterTelevision = fmt.Sprintf("label=%s", label)
+		if settings.FilterMovies == "" {
+			settings.FilterMovies = fmt.Sprintf("label=%s", label)
+		}
+
+		if settings.FilterTelevision == "" {
+			settings.FilterTelevision = fmt.Sprintf("label=%s", label)
+		}
 	}
 
 	requestBody.Settings = settings
@@ -716,28 +1131,24 @@ func (p *Plex) InviteFriend(params InviteFriendParams) error {
 	jsonBody, jsonErr := json.Marshal(requestBody)
 
 	if jsonErr != nil {
-		return jsonErr
+		return result, jsonErr
 	}
 
 	resp, err := p.post(query, jsonBody, p.Headers)
 
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusCreated {
-		return errors.New(resp.Status)
+		return result, p.responseError(resp, errors.New(resp.Status))
 	}
 
-	result := new(inviteFriendResponse)
-
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return err
-	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
 
-	return nil
+	return result, err
 }
 
 // UpdateFriendAccess limit your friends access to your plex server
@@ -755,7 +1166,7 @@ func (p *Plex) UpdateFriendAccess(userID string, params UpdateFriendParams) (boo
 		params.AllowChannels = "0"
 	}
 
-	query := fmt.Sprintf("%s/api/friends/%s", plexURL, userID)
+	query := fmt.Sprintf("%s/api/friends/%s", p.plexTV(), userID)
 
 	parsedQuery, parseErr := url.Parse(query)
 
@@ -786,7 +1197,7 @@ func (p *Plex) UpdateFriendAccess(userID string, params UpdateFriendParams) (boo
 	safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return false, errors.New(resp.Status)
+		return false, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	return true, nil
@@ -794,7 +1205,7 @@ func (p *Plex) UpdateFriendAccess(userID string, params UpdateFriendParams) (boo
 
 // RemoveFriendAccessToLibrary you can individually revoke access to a library on your server. Such as movies, tv shows, music, etc
 func (p *Plex) RemoveFriendAccessToLibrary(userID, machineID, serverID string) (bool, error) {
-	query := fmt.Sprintf("%s/api/servers/%s/shared_servers/%s", plexURL, machineID, serverID)
+	query := fmt.Sprintf("%s/api/servers/%s/shared_servers/%s", p.plexTV(), machineID, serverID)
 
 	resp, err := p.delete(query, p.Headers)
 
@@ -805,7 +1216,7 @@ func (p *Plex) RemoveFriendAccessToLibrary(userID, machineID, serverID string) (
 	safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return false, errors.New(resp.Status)
+		return false, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	return true, nil
@@ -814,7 +1225,7 @@ func (p *Plex) RemoveFriendAccessToLibrary(userID, machineID, serverID string) (
 // GetInvitedFriends get all invited friends with request still pending
 func (p *Plex) GetInvitedFriends() ([]InvitedFriend, error) {
 
-	query := plexURL + "/api/invites/requested"
+	query := p.plexTV() + "/api/invites/requested"
 	newHeaders := p.Headers
 	newHeaders.Accept = applicationXml
 
@@ -824,9 +1235,9 @@ func (p *Plex) GetInvitedFriends() ([]InvitedFriend, error) {
 	}
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return []InvitedFriend{}, errors.New(ErrorNotAuthorized)
+		return []InvitedFriend{}, p.responseError(resp, errors.New(ErrorNotAuthorized))
 	} else if resp.StatusCode != http.StatusOK {
-		return []InvitedFriend{}, fmt.Errorf(ErrorServerReplied, resp.StatusCode)
+		return []InvitedFriend{}, p.responseError(resp, fmt.Errorf(ErrorServerReplied, resp.StatusCode))
 	}
 
 	var invitedFriendsResp invitedFriendsResponse
@@ -841,7 +1252,7 @@ func (p *Plex) GetInvitedFriends() ([]InvitedFriend, error) {
 
 // RemoveInvitedFriend cancel pending friend invite
 func (p *Plex) RemoveInvitedFriend(inviteID string, isFriend, isServer, isHome bool) (bool, error) {
-	query := plexURL + "/api/invites/requested/" + url.QueryEscape(inviteID)
+	query := p.plexTV() + "/api/invites/requested/" + url.QueryEscape(inviteID)
 
 	parsedQuery, parseErr := url.Parse(query)
 	if parseErr != nil {
@@ -864,7 +1275,7 @@ func (p *Plex) RemoveInvitedFriend(inviteID string, isFriend, isServer, isHome b
 
 	defer safeClose(resp.Body)
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
-		return false, errors.New(resp.Status)
+		return false, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	result := new(resultResponse)
@@ -875,12 +1286,18 @@ func (p *Plex) RemoveInvitedFriend(inviteID string, isFriend, isServer, isHome b
 	return result.Response.Code == 0, nil
 }
 
+// CancelInvite cancels a pending invite, as listed by GetInvitedFriends or returned by
+// SharedServer.PendingInvite
+func (p *Plex) CancelInvite(invite InvitedFriend) (bool, error) {
+	return p.RemoveInvitedFriend(invite.ID, invite.IsFriend, invite.IsServer, invite.IsHome)
+}
+
 // CheckUsernameOrEmail will check if the username is a Plex user or will verify an email is valid
 func (p *Plex) CheckUsernameOrEmail(usernameOrEmail string) (bool, error) {
 
 	usernameOrEmail = url.QueryEscape(usernameOrEmail)
 
-	query := fmt.Sprintf("%s/api/users/validate?invited_email=%s", plexURL, usernameOrEmail)
+	query := fmt.Sprintf("%s/api/users/validate?invited_email=%s", p.plexTV(), usernameOrEmail)
 
 	resp, err := p.post(query, nil, p.Headers)
 
@@ -891,7 +1308,7 @@ func (p *Plex) CheckUsernameOrEmail(usernameOrEmail string) (bool, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
-		return false, errors.New(resp.Status)
+		return false, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	result := new(resultResponse)
@@ -905,7 +1322,7 @@ func (p *Plex) CheckUsernameOrEmail(usernameOrEmail string) (bool, error) {
 
 // StopPlayback acts as a remote controller and sends the 'stop' command
 func (p *Plex) StopPlayback(machineID string) error {
-	query := p.URL + "/player/playback/stop"
+	query := p.ServerURL() + "/player/playback/stop"
 
 	newHeaders := p.Headers
 
@@ -927,7 +1344,7 @@ func (p *Plex) StopPlayback(machineID string) error {
 
 // GetDevices returns a list of your Plex devices (servers, players, controllers, etc)
 func (p *Plex) GetDevices() ([]PMSDevices, error) {
-	query := plexURL + "/api/resources?includeHttps=1"
+	query := p.plexTV() + "/api/resources?includeHttps=1"
 
 	resp, err := p.get(query, p.Headers)
 
@@ -940,7 +1357,7 @@ func (p *Plex) GetDevices() ([]PMSDevices, error) {
 	result := new(resourcesResponse)
 
 	if resp.StatusCode != http.StatusOK {
-		return []PMSDevices{}, errors.New(resp.Status)
+		return []PMSDevices{}, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	if err := xml.NewDecoder(resp.Body).Decode(result); err != nil {
@@ -981,7 +1398,7 @@ func (p *Plex) GetServers() ([]PMSDevices, error) {
 
 // GetServersInfo returns info about all of your Plex servers
 func (p *Plex) GetServersInfo() (ServerInfo, error) {
-	query := plexURL + "/api/servers"
+	query := p.plexTV() + "/api/servers"
 
 	resp, err := p.get(query, p.Headers)
 
@@ -992,7 +1409,7 @@ func (p *Plex) GetServersInfo() (ServerInfo, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return ServerInfo{}, errors.New(resp.Status)
+		return ServerInfo{}, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	result := ServerInfo{}
@@ -1033,10 +1450,50 @@ func (p *Plex) GetMachineID() (string, error) {
 	return machineID, nil
 }
 
+// ServerIdentity identifies the server at the url of the client
+type ServerIdentity struct {
+	MachineIdentifier string `json:"machineIdentifier"`
+	Version           string `json:"version"`
+	Claimed           bool   `json:"claimed"`
+}
+
+// GetIdentity returns the machine identifier and version of the server. The endpoint
+// does not require a token, which makes it a cheap health check.
+func (p *Plex) GetIdentity() (ServerIdentity, error) {
+	identity, err := getContainer[ServerIdentity](p, p.ServerURL()+"/identity")
+
+	return identity.MediaContainer, err
+}
+
+// GetCapabilities returns the features and settings the server advertises at its root
+func (p *Plex) GetCapabilities() (BaseAPIResponse, error) {
+	resp, err := p.get(p.ServerURL()+"/", p.Headers)
+
+	if err != nil {
+		return BaseAPIResponse{}, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return BaseAPIResponse{}, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return BaseAPIResponse{}, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	var result BaseAPIResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return BaseAPIResponse{}, err
+	}
+
+	return result, nil
+}
+
 // GetSections of your plex server. This is useful when inviting a user
 // as you can restrict the invited user to a library (i.e. Movie's, TV Shows)
 func (p *Plex) GetSections(machineID string) ([]ServerSections, error) {
-	query := fmt.Sprintf("%s/api/servers/%s", plexURL, machineID)
+	query := fmt.Sprintf("%s/api/servers/%s", p.plexTV(), machineID)
 
 	newHeaders := p.Headers
 
@@ -1073,7 +1530,7 @@ func (p *Plex) GetSections(machineID string) ([]ServerSections, error) {
 // GetLibraries of your Plex server. My ideal use-case would be
 // to get library count to determine label index
 func (p *Plex) GetLibraries() (LibrarySections, error) {
-	query := fmt.Sprintf("%s/library/sections", p.URL)
+	query := fmt.Sprintf("%s/library/sections", p.ServerURL())
 
 	resp, err := p.get(query, p.Headers)
 
@@ -1084,7 +1541,7 @@ func (p *Plex) GetLibraries() (LibrarySections, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return LibrarySections{}, errors.New(resp.Status)
+		return LibrarySections{}, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	var result LibrarySections
@@ -1098,36 +1555,77 @@ func (p *Plex) GetLibraries() (LibrarySections, error) {
 	return result, nil
 }
 
+// DefaultLibraryCountConcurrency is the number of sections GetLibrariesWithCounts counts at once
+const DefaultLibraryCountConcurrency = 4
+
 // GetLibrariesWithCounts gets libraries and populates the Count field with actual item counts
 func (p *Plex) GetLibrariesWithCounts() (LibrarySections, error) {
-	// First get the basic library information
+	return p.GetLibrariesWithCountsConcurrently(DefaultLibraryCountConcurrency)
+}
+
+// GetLibrariesWithCountsConcurrently is GetLibrariesWithCounts counting up to limit sections
+// at once. Sections whose count could not be fetched have a Count of -1.
+func (p *Plex) GetLibrariesWithCountsConcurrently(limit int) (LibrarySections, error) {
 	libraries, err := p.GetLibraries()
 	if err != nil {
 		return LibrarySections{}, err
 	}
 
-	// For each library, get the actual count by querying its content
+	if limit <= 0 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+
 	for i := range libraries.MediaContainer.Directory {
 		dir := &libraries.MediaContainer.Directory[i]
 
-		// Query the library content to get the actual count
-		content, err := p.GetLibraryContent(dir.Key, "")
-		if err != nil {
-			// If we can't get the content, set count to -1 to indicate error
-			dir.Count = -1
-			continue
-		}
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-		// Set the actual count from the MediaContainer size
-		dir.Count = content.MediaContainer.Size
+			dir.Count = p.countLibraryItems(dir.Key)
+		}()
 	}
 
+	wg.Wait()
+
 	return libraries, nil
 }
 
-// GetLibraryContent retrieve the content inside a library
+// countLibraryItems returns the number of items of a section, or -1 when it can't be fetched.
+// Requesting an empty page makes plex only report the total in the container.
+func (p *Plex) countLibraryItems(sectionKey string) int {
+	query := fmt.Sprintf("%s/library/sections/%s/all?X-Plex-Container-Start=0&X-Plex-Container-Size=0", p.ServerURL(), sectionKey)
+
+	content, err := getContainer[MediaContainer](p, query)
+
+	if err != nil {
+		return -1
+	}
+
+	if content.MediaContainer.TotalSize > 0 {
+		return content.MediaContainer.TotalSize
+	}
+
+	// servers ignoring the paging parameters return every item
+	return content.MediaContainer.Size
+}
+
+// GetLibraryContentFiltered retrieves the content inside a library matching filter
+func (p *Plex) GetLibraryContentFiltered(sectionKey string, filter *LibraryFilter) (SearchResults, error) {
+	return p.GetLibraryContent(sectionKey, filter.String())
+}
+
+// GetLibraryContent retrieve the content inside a library. filter is a raw query string
+// such as "?type=1", use GetLibraryContentFiltered to build it with a LibraryFilter.
 func (p *Plex) GetLibraryContent(sectionKey string, filter string) (SearchResults, error) {
-	query := fmt.Sprintf("%s/library/sections/%s/all%s", p.URL, sectionKey, filter)
+	query := fmt.Sprintf("%s/library/sections/%s/all%s", p.ServerURL(), sectionKey, filter)
 
 	resp, err := p.get(query, p.Headers)
 
@@ -1140,7 +1638,7 @@ func (p *Plex) GetLibraryContent(sectionKey string, filter string) (SearchResult
 	}
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return SearchResults{}, errors.New(ErrorNotAuthorized)
+		return SearchResults{}, p.responseError(resp, errors.New(ErrorNotAuthorized))
 	}
 
 	if resp.StatusCode == http.StatusBadRequest {
@@ -1162,30 +1660,30 @@ func (p *Plex) GetLibraryContent(sectionKey string, filter string) (SearchResult
 func (p *Plex) CreateLibrary(params CreateLibraryParams) error {
 	// all params are required
 	if params.Name == "" {
-		return errors.New("name is required")
+		return &LibraryParamsError{Field: "name", Reason: "is required"}
 	}
 
 	if params.Location == "" {
-		return errors.New("location is required")
+		return &LibraryParamsError{Field: "location", Reason: "is required"}
 	}
 
 	if params.LibraryType == "" {
-		return errors.New("libraryType is required")
+		return &LibraryParamsError{Field: "libraryType", Reason: "is required"}
 	}
 
 	if params.Agent == "" {
-		return errors.New("agent is required")
+		return &LibraryParamsError{Field: "agent", Reason: "is required"}
 	}
 
 	if params.Scanner == "" {
-		return errors.New("scanner is required")
+		return &LibraryParamsError{Field: "scanner", Reason: "is required"}
 	}
 
 	if params.Language == "" {
 		params.Language = "en"
 	}
 
-	query := p.URL + "/library/sections"
+	query := p.ServerURL() + "/library/sections"
 
 	parsedQuery, err := url.Parse(query)
 
@@ -1215,7 +1713,7 @@ func (p *Plex) CreateLibrary(params CreateLibraryParams) error {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusCreated {
-		return errors.New(resp.Status)
+		return p.responseError(resp, errors.New(resp.Status))
 	}
 
 	return nil
@@ -1223,7 +1721,7 @@ func (p *Plex) CreateLibrary(params CreateLibraryParams) error {
 
 // DeleteLibrary removes the library from your Plex server via library key (or id)
 func (p *Plex) DeleteLibrary(key string) error {
-	query := fmt.Sprintf("%s/library/sections/%s", p.URL, key)
+	query := fmt.Sprintf("%s/library/sections/%s", p.ServerURL(), key)
 
 	resp, err := p.delete(query, p.Headers)
 
@@ -1234,7 +1732,266 @@ func (p *Plex) DeleteLibrary(key string) error {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return errors.New(resp.Status)
+		return p.responseError(resp, errors.New(resp.Status))
+	}
+
+	return nil
+}
+
+// UpdateLibrary renames a library section, switches its agent, scanner or language, or changes its settings
+func (p *Plex) UpdateLibrary(key string, params UpdateLibraryParams) error {
+	if key == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	vals := url.Values{}
+
+	if params.Name != "" {
+		vals.Set("name", params.Name)
+	}
+
+	if params.Agent != "" {
+		vals.Set("agent", params.Agent)
+	}
+
+	if params.Scanner != "" {
+		vals.Set("scanner", params.Scanner)
+	}
+
+	if params.Language != "" {
+		vals.Set("language", params.Language)
+	}
+
+	for name, value := range params.Prefs {
+		vals.Set("prefs["+name+"]", value)
+	}
+
+	return p.updateLibrarySection(key, vals)
+}
+
+// AddLibraryLocation adds a folder to an existing library section
+func (p *Plex) AddLibraryLocation(key, location string) error {
+	if location == "" {
+		return errors.New("location is required")
+	}
+
+	return p.setLibraryLocations(key, func(locations []string) ([]string, error) {
+		for _, existing := range locations {
+			if existing == location {
+				return nil, fmt.Errorf("library section %s already contains %s", key, location)
+			}
+		}
+
+		return append(locations, location), nil
+	})
+}
+
+// RemoveLibraryLocation removes a folder from an existing library section. A section
+// needs at least one location, use DeleteLibrary to remove the last one.
+func (p *Plex) RemoveLibraryLocation(key, location string) error {
+	return p.setLibraryLocations(key, func(locations []string) ([]string, error) {
+		kept := make([]string, 0, len(locations))
+
+		for _, existing := range locations {
+			if existing != location {
+				kept = append(kept, existing)
+			}
+		}
+
+		if len(kept) == len(locations) {
+			return nil, fmt.Errorf("library section %s does not contain %s", key, location)
+		}
+
+		if len(kept) == 0 {
+			return nil, errors.New("can not remove the last location of a library section")
+		}
+
+		return kept, nil
+	})
+}
+
+// setLibraryLocations replaces the locations of a section with the result of update
+func (p *Plex) setLibraryLocations(key string, update func(locations []string) ([]string, error)) error {
+	if key == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	section, err := p.getLibrarySection(key)
+
+	if err != nil {
+		return err
+	}
+
+	locations := make([]string, 0, len(section.Location))
+
+	for _, location := range section.Location {
+		locations = append(locations, location.Path)
+	}
+
+	locations, err = update(locations)
+
+	if err != nil {
+		return err
+	}
+
+	vals := url.Values{}
+	vals.Set("agent", section.Agent)
+	vals["location"] = locations
+
+	return p.updateLibrarySection(key, vals)
+}
+
+// Library section settings that can be changed with SetLibraryPrefs. Which ones are
+// available depends on the section type, GetLibraryPrefs lists them all.
+const (
+	LibraryPrefCollectionMode      = "collectionMode"
+	LibraryPrefEpisodeSort         = "episodeSort"
+	LibraryPrefShowOrdering        = "showOrdering"
+	LibraryPrefFlattenSeasons      = "flattenSeasons"
+	LibraryPrefEnableBIFGeneration = "enableBIFGeneration"
+	LibraryPrefCinemaTrailers      = "enableCinemaTrailers"
+	LibraryPrefProviderContent     = "augmentWithProviderContent"
+)
+
+// GetLibraryPrefs returns the settings of a library section with their current values
+func (p *Plex) GetLibraryPrefs(key string) (LibraryPrefs, error) {
+	if key == "" {
+		return LibraryPrefs{}, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/prefs", p.ServerURL(), key)
+
+	return getContainer[LibraryPrefsContainer](p, query)
+}
+
+// Get returns the setting with id
+func (c LibraryPrefsContainer) Get(id string) (Setting, bool) {
+	for _, setting := range c.Setting {
+		if setting.ID == id {
+			return setting, true
+		}
+	}
+
+	return Setting{}, false
+}
+
+// SetLibraryPrefs changes settings of a library section, prefs maps setting ids to values,
+// e.g. {LibraryPrefShowOrdering: "dvd"}
+func (p *Plex) SetLibraryPrefs(key string, prefs map[string]string) error {
+	if key == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	vals := url.Values{}
+
+	for id, value := range prefs {
+		vals.Set(id, value)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/prefs?%s", p.ServerURL(), key, vals.Encode())
+
+	resp, err := p.put(query, nil, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, errors.New(resp.Status))
+	}
+
+	return nil
+}
+
+// LibraryVisibility controls where the content of a library section shows up in plex apps
+type LibraryVisibility int
+
+// Visibility levels of a library section, stored in its "hidden" setting
+const (
+	LibraryVisible                 LibraryVisibility = 0
+	LibraryHiddenFromHome          LibraryVisibility = 1
+	LibraryHiddenFromHomeAndSearch LibraryVisibility = 2
+)
+
+// libraryPrefHidden is the section setting holding its LibraryVisibility
+const libraryPrefHidden = "hidden"
+
+// GetLibraryVisibility returns whether a section is included in the home screen and global search
+func (p *Plex) GetLibraryVisibility(key string) (LibraryVisibility, error) {
+	prefs, err := p.GetLibraryPrefs(key)
+
+	if err != nil {
+		return LibraryVisible, err
+	}
+
+	setting, ok := prefs.MediaContainer.Get(libraryPrefHidden)
+
+	if !ok {
+		return LibraryVisible, fmt.Errorf("library section %s has no visibility setting", key)
+	}
+
+	value, err := setting.Value.Int64()
+
+	if err != nil {
+		return LibraryVisible, err
+	}
+
+	return LibraryVisibility(value), nil
+}
+
+// SetLibraryVisibility includes or excludes a section from the home screen and global search
+func (p *Plex) SetLibraryVisibility(key string, visibility LibraryVisibility) error {
+	if visibility < LibraryVisible || visibility > LibraryHiddenFromHomeAndSearch {
+		return fmt.Errorf("invalid library visibility %d", visibility)
+	}
+
+	return p.SetLibraryPrefs(key, map[string]string{libraryPrefHidden: strconv.Itoa(int(visibility))})
+}
+
+// getLibrarySection returns the library section with key
+func (p *Plex) getLibrarySection(key string) (Directory, error) {
+	libraries, err := p.GetLibraries()
+
+	if err != nil {
+		return Directory{}, err
+	}
+
+	for _, section := range libraries.MediaContainer.Directory {
+		if section.Key == key {
+			return section, nil
+		}
+	}
+
+	return Directory{}, fmt.Errorf("library section %s not found", key)
+}
+
+// updateLibrarySection sends vals to the library section. Plex rejects section updates
+// without an agent, so the current agent is added when vals does not change it.
+func (p *Plex) updateLibrarySection(key string, vals url.Values) error {
+	if vals.Get("agent") == "" {
+		section, err := p.getLibrarySection(key)
+
+		if err != nil {
+			return err
+		}
+
+		vals.Set("agent", section.Agent)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s?%s", p.ServerURL(), key, vals.Encode())
+
+	resp, err := p.put(query, nil, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, errors.New(resp.Status))
 	}
 
 	return nil
@@ -1242,7 +1999,32 @@ func (p *Plex) DeleteLibrary(key string) error {
 
 // DeleteMediaByID removes the media from your Plex server via media key (or id)
 func (p *Plex) DeleteMediaByID(id string) error {
-	query := fmt.Sprintf("%s/library/metadata/%s", p.URL, id)
+	query := fmt.Sprintf("%s/library/metadata/%s", p.ServerURL(), id)
+
+	resp, err := p.delete(query, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, errors.New(resp.Status))
+	}
+
+	return nil
+}
+
+// DeleteMediaPart removes a single version of an item, e.g. the 720p copy of a movie also
+// available in 4K, deleting its files. mediaID is the ID of one of the item's Media.
+// Use DeleteMediaByID to remove the item with all its versions.
+func (p *Plex) DeleteMediaPart(metadataID, mediaID string) error {
+	if metadataID == "" || mediaID == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/metadata/%s/media/%s", p.ServerURL(), metadataID, mediaID)
 
 	resp, err := p.delete(query, p.Headers)
 
@@ -1253,7 +2035,7 @@ func (p *Plex) DeleteMediaByID(id string) error {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return errors.New(resp.Status)
+		return p.responseError(resp, errors.New(resp.Status))
 	}
 
 	return nil
@@ -1266,7 +2048,7 @@ func (p *Plex) GetLibraryLabels(sectionKey, sectionIndex string) (LibraryLabels,
 		sectionIndex = "1"
 	}
 
-	query := fmt.Sprintf("%s/library/sections/%s/labels?type=%s", p.URL, sectionKey, sectionIndex)
+	query := fmt.Sprintf("%s/library/sections/%s/labels?type=%s", p.ServerURL(), sectionKey, sectionIndex)
 
 	resp, err := p.get(query, p.Headers)
 
@@ -1277,7 +2059,7 @@ func (p *Plex) GetLibraryLabels(sectionKey, sectionIndex string) (LibraryLabels,
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return LibraryLabels{}, errors.New(resp.Status)
+		return LibraryLabels{}, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	var result LibraryLabels
@@ -1292,12 +2074,12 @@ func (p *Plex) GetLibraryLabels(sectionKey, sectionIndex string) (LibraryLabels,
 }
 
 // AddLabelToMedia restrict access to certain media. Requires a Plex Pass.
-// mediaType is the media type (1), id is the ratingKey or media id, label is your label, locked is unknown
+// mediaType is the media type (1) or its name, e.g. MediaTypeMovie.String(), id is the ratingKey or media id, label is your label, locked is unknown
 // 1. A reference to the plex media types: https://github.com/Arcanemagus/plex-api/wiki/MediaTypes
 // XXX: Currently plex is capitalizing the first letter
 func (p *Plex) AddLabelToMedia(mediaType, sectionID, id, label, locked string) (bool, error) {
 
-	query := fmt.Sprintf("%s/library/sections/%s/all", p.URL, sectionID)
+	query := fmt.Sprintf("%s/library/sections/%s/all", p.ServerURL(), sectionID)
 
 	parsedQuery, parseErr := url.Parse(query)
 
@@ -1307,7 +2089,7 @@ func (p *Plex) AddLabelToMedia(mediaType, sectionID, id, label, locked string) (
 
 	vals := parsedQuery.Query()
 
-	vals.Add("type", mediaType)
+	vals.Add("type", GetMediaTypeID(mediaType))
 	vals.Add("id", id)
 	vals.Add("label[0].tag.tag", label)
 	// vals.Add("label.locked", locked)
@@ -1330,7 +2112,7 @@ func (p *Plex) AddLabelToMedia(mediaType, sectionID, id, label, locked string) (
 // RemoveLabelFromMedia to remove a label from a piece of media Requires a Plex Pass.
 func (p *Plex) RemoveLabelFromMedia(mediaType, sectionID, id, label, locked string) (bool, error) {
 
-	query := fmt.Sprintf("%s/library/sections/%s/all", p.URL, sectionID)
+	query := fmt.Sprintf("%s/library/sections/%s/all", p.ServerURL(), sectionID)
 
 	parsedQuery, parseErr := url.Parse(query)
 
@@ -1340,7 +2122,7 @@ func (p *Plex) RemoveLabelFromMedia(mediaType, sectionID, id, label, locked stri
 
 	vals := parsedQuery.Query()
 
-	vals.Add("type", mediaType)
+	vals.Add("type", GetMediaTypeID(mediaType))
 	vals.Add("id", id)
 	vals.Add("label[].tag.tag-", label)
 	vals.Add("label.locked", locked)
@@ -1364,7 +2146,7 @@ func (p *Plex) RemoveLabelFromMedia(mediaType, sectionID, id, label, locked stri
 func (p *Plex) GetSessions() (CurrentSessions, error) {
 	newHeaders := p.Headers
 
-	query := fmt.Sprintf("%s/status/sessions", p.URL)
+	query := fmt.Sprintf("%s/status/sessions", p.ServerURL())
 
 	resp, err := p.get(query, newHeaders)
 
@@ -1375,7 +2157,7 @@ func (p *Plex) GetSessions() (CurrentSessions, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return CurrentSessions{}, errors.New(resp.Status)
+		return CurrentSessions{}, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	var result CurrentSessions
@@ -1396,7 +2178,7 @@ func (p *Plex) TerminateSession(sessionID string, reason string) error {
 	sessionID = url.QueryEscape(sessionID)
 	reason = url.QueryEscape(reason)
 
-	query := fmt.Sprintf("%s/status/sessions/terminate?sessionId=%s&reason=%s", p.URL, sessionID, reason)
+	query := fmt.Sprintf("%s/status/sessions/terminate?sessionId=%s&reason=%s", p.ServerURL(), sessionID, reason)
 
 	newHeaders := p.Headers
 	newHeaders.Accept = applicationXml
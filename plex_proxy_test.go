@@ -0,0 +1,108 @@
+package plex
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+// Test that WithProxy routes api requests through the proxy
+func TestWithProxy(t *testing.T) {
+	var proxied string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatalf("failed to parse proxy url: %v", err)
+	}
+
+	p, err := New("http://plex.invalid:32400", "token", WithProxy(proxyURL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("GetLibraries() error = %v", err)
+	}
+
+	if proxied != "http://plex.invalid:32400/library/sections" {
+		t.Errorf("expected the request to go through the proxy, got %q", proxied)
+	}
+
+	dt, ok := p.DownloadClient.Transport.(*http.Transport)
+	if !ok || dt.Proxy == nil {
+		t.Fatalf("expected the download transport to use the proxy")
+	}
+
+	if u, _ := dt.Proxy(&http.Request{URL: &url.URL{Scheme: "http", Host: "plex.invalid"}}); u == nil || u.String() != proxy.URL {
+		t.Errorf("expected download proxy %s, got %v", proxy.URL, u)
+	}
+}
+
+// Test that options replacing the transport keep honoring environment proxies
+func TestWithInsecureSkipVerifyKeepsEnvironmentProxy(t *testing.T) {
+	p, err := New("https://example.local", "token", WithInsecureSkipVerify())
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	ht, ok := p.HTTPClient.Transport.(*http.Transport)
+	if !ok || ht.Proxy == nil {
+		t.Errorf("expected HTTPClient.Transport to keep the environment proxy")
+	}
+}
+
+// Test that WithDialer reaches the server over a unix socket
+func TestWithDialer(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "plex.sock")
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets are not supported: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Directory":[{"key":"1","title":"Movies"}]}}`))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	var dialed []string
+
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+
+		return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+	}
+
+	p, err := New("http://plex", "token", WithDialer(dial))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	libraries, err := p.GetLibraries()
+	if err != nil {
+		t.Fatalf("GetLibraries() error = %v", err)
+	}
+
+	if len(libraries.MediaContainer.Directory) != 1 || len(dialed) != 1 || dialed[0] != "plex:80" {
+		t.Errorf("unexpected libraries %+v, dialed %v", libraries.MediaContainer.Directory, dialed)
+	}
+
+	if p.WebsocketDialer == nil || p.WebsocketDialer.NetDialContext == nil {
+		t.Errorf("expected websockets to use the dialer")
+	}
+}
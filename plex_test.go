@@ -2,12 +2,14 @@ package plex
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
+	"time"
 )
 
 var (
@@ -140,7 +142,7 @@ func TestInviteFriendResponse(t *testing.T) {
 		</MediaContainer>
 	`)
 
-	result := new(inviteFriendResponse)
+	result := new(SharedServer)
 
 	if err := xml.Unmarshal(testData, result); err != nil {
 		t.Error(err.Error())
@@ -174,8 +176,98 @@ func TestPlex_RemoveInvitedFriend(t *testing.T) {
 		return
 	}
 	success, err := plexConn.RemoveInvitedFriend("email-id-dne@gmail.com", false, true, false)
-	if err != nil && err.Error() != "404 Not Found" {
+	var reqErr *RequestError
+	if err != nil && !(errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound) {
 		// expect a 404
 		t.Errorf("success: %v, error: %v", success, err)
 	}
 }
+
+func TestRequestOptions(t *testing.T) {
+	var got http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: time.Second}
+
+	p, err := New(server.URL, "token",
+		WithPlexTVURL(server.URL),
+		WithHTTPClient(client),
+		WithUserAgent("My App", "2.0", ""),
+		WithHeaders(http.Header{"X-Custom": []string{"a"}}),
+		WithHeaders(http.Header{"X-Plex-Device-Name": []string{"living room"}}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if p.HTTPClient.Timeout != time.Second {
+		t.Errorf("expected custom http client timeout, got %v", p.HTTPClient.Timeout)
+	}
+
+	if _, err := p.Test(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := map[string]string{
+		"X-Plex-Product":     "My App",
+		"X-Plex-Version":     "2.0",
+		"X-Plex-Device":      defaultHeaders().Device,
+		"X-Custom":           "a",
+		"X-Plex-Device-Name": "living room",
+	}
+
+	for key, want := range checks {
+		if v := got.Get(key); v != want {
+			t.Errorf("header %s = %q; want %q", key, v, want)
+		}
+	}
+}
+
+func TestRequestHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Plex-Token") != "refreshed" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var order []string
+	var status int
+
+	p, err := New(server.URL, "stale",
+		WithPlexTVURL(server.URL),
+		WithBeforeRequest(func(req *http.Request) {
+			order = append(order, "before")
+			req.Header.Set("X-Plex-Token", "refreshed")
+		}),
+		WithAfterResponse(func(resp *http.Response, err error) {
+			order = append(order, "after")
+			if err == nil {
+				status = resp.StatusCode
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := p.Test(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status != http.StatusOK {
+		t.Errorf("expected after hook to see status 200, got %d", status)
+	}
+
+	if len(order) != 2 || order[0] != "before" || order[1] != "after" {
+		t.Errorf("unexpected hook order: %v", order)
+	}
+}
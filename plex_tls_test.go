@@ -1,9 +1,21 @@
 package plex
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 // Test that WithInsecureSkipVerify applies InsecureSkipVerify to both HTTP clients
@@ -56,3 +68,193 @@ func TestEnvVarEnablesSkipTLSVerification(t *testing.T) {
 		t.Fatalf("expected HTTPClient.Transport TLSClientConfig.InsecureSkipVerify to be true due to env var")
 	}
 }
+
+// Test that wss connections trust the root CAs configured on the HTTP client
+func TestWebsocketDialerUsesHTTPClientTLSConfig(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	rootCAs := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	p, err := New(srv.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+	p.HTTPClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}
+
+	connected := make(chan struct{}, 1)
+	errs := make(chan error, 1)
+
+	events := NewNotificationEvents()
+	events.OnConnect(func() { connected <- struct{}{} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p.SubscribeToNotificationsWithContext(ctx, events, func(err error) { errs <- err })
+
+	select {
+	case <-connected:
+	case err := <-errs:
+		t.Fatalf("expected wss connection to succeed, got %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for wss connection")
+	}
+}
+
+// Test that WithInsecureSkipVerify keeps an existing TLS configuration
+func TestWithInsecureSkipVerifyKeepsTLSConfig(t *testing.T) {
+	p := &Plex{}
+	p.HTTPClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{ServerName: "plex.local"}}
+
+	WithInsecureSkipVerify()(p)
+
+	ht := p.HTTPClient.Transport.(*http.Transport)
+	if !ht.TLSClientConfig.InsecureSkipVerify || ht.TLSClientConfig.ServerName != "plex.local" {
+		t.Fatalf("expected InsecureSkipVerify on top of the existing config, got %+v", ht.TLSClientConfig)
+	}
+}
+
+func TestDownloadTransport(t *testing.T) {
+	p, err := New("https://example.local", "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	dt, ok := p.DownloadClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected DownloadClient.Transport to be *http.Transport, got %T", p.DownloadClient.Transport)
+	}
+
+	if dt.MaxIdleConnsPerHost != defaultDownloadTransportOptions.MaxIdleConnsPerHost || !dt.ForceAttemptHTTP2 {
+		t.Fatalf("expected default download transport settings, got MaxIdleConnsPerHost=%d ForceAttemptHTTP2=%v", dt.MaxIdleConnsPerHost, dt.ForceAttemptHTTP2)
+	}
+
+	p, err = New("https://example.local", "token",
+		WithInsecureSkipVerify(),
+		WithDownloadTransport(DownloadTransportOptions{MaxConnsPerHost: 4, DisableHTTP2: true}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	dt = p.DownloadClient.Transport.(*http.Transport)
+
+	if dt.MaxConnsPerHost != 4 || dt.ForceAttemptHTTP2 {
+		t.Errorf("expected tuned download transport, got MaxConnsPerHost=%d ForceAttemptHTTP2=%v", dt.MaxConnsPerHost, dt.ForceAttemptHTTP2)
+	}
+
+	if dt.MaxIdleConnsPerHost != defaultDownloadTransportOptions.MaxIdleConnsPerHost {
+		t.Errorf("expected zero MaxIdleConnsPerHost to use the default, got %d", dt.MaxIdleConnsPerHost)
+	}
+
+	if dt.TLSClientConfig == nil || !dt.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("expected WithDownloadTransport to keep the insecure TLS config")
+	}
+}
+
+// Test that WithRootCAs trusts a server signed by a private CA
+func TestWithRootCAs(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	defer srv.Close()
+
+	untrusted, err := New(srv.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := untrusted.GetLibraries(); err == nil {
+		t.Fatalf("expected the certificate to be rejected without the root CA")
+	}
+
+	rootCAs := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	p, err := New(srv.URL, "token", WithRootCAs(rootCAs))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("GetLibraries() error = %v", err)
+	}
+
+	if p.WebsocketDialer == nil || p.WebsocketDialer.TLSClientConfig.RootCAs != rootCAs {
+		t.Errorf("expected websockets to trust the root CA")
+	}
+}
+
+// Test that WithClientCertificate authenticates to a server requiring mutual TLS
+func TestWithClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-plex-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	var presented string
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented = r.TLS.PeerCertificates[0].Subject.CommonName
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	rootCAs := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	anonymous, err := New(srv.URL, "token", WithRootCAs(rootCAs))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := anonymous.GetLibraries(); err == nil {
+		t.Fatalf("expected the server to reject a client without certificate")
+	}
+
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+
+	p, err := New(srv.URL, "token", WithRootCAs(rootCAs), WithClientCertificate(cert))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("GetLibraries() error = %v", err)
+	}
+
+	if presented != "go-plex-client" {
+		t.Errorf("expected the client certificate to be presented, got %q", presented)
+	}
+}
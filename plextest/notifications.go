@@ -0,0 +1,182 @@
+// Package plextest provides fakes of a plex media server for testing code that uses
+// the plex package, in the spirit of net/http/httptest.
+//
+//	server := plextest.NewNotificationServer()
+//	defer server.Close()
+//
+//	client, _ := plex.New(server.URL, "token")
+//	go client.SubscribeToNotificationsWithContext(ctx, events, nil)
+//
+//	_ = server.WaitForConnections(1, time.Second)
+//	_ = server.Emit(plex.NotificationContainer{Type: "playing", ...})
+package plextest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/timothystewart6/go-plex-client"
+)
+
+// NotificationServer is a fake notification websocket endpoint that sends the
+// notifications a test scripts with Emit to every connected subscriber
+type NotificationServer struct {
+	// URL of the server, pass it to plex.New
+	URL string
+
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu          sync.Mutex
+	conns       map[*websocket.Conn]bool
+	connections int
+	filters     []string
+	changed     chan struct{}
+}
+
+// NewNotificationServer starts a NotificationServer, Close stops it
+func NewNotificationServer() *NotificationServer {
+	s := &NotificationServer{
+		conns:   map[*websocket.Conn]bool{},
+		changed: make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/:/websockets/notifications", s.serve)
+
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+
+	return s
+}
+
+// serve upgrades a subscription and keeps reading from it so pings are answered
+func (s *NotificationServer) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.conns[conn] = true
+	s.connections++
+
+	s.filters = nil
+	if filters := r.URL.Query().Get("filters"); filters != "" {
+		s.filters = strings.Split(filters, ",")
+	}
+
+	s.notifyLocked()
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.notifyLocked()
+		s.mu.Unlock()
+
+		_ = conn.Close()
+	}()
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// notifyLocked wakes up the callers of WaitForConnections, s.mu must be held
+func (s *NotificationServer) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// WaitForConnections waits until n subscriptions were made since the server started,
+// counting the ones that have ended, e.g. 2 to wait for a client to reconnect once
+func (s *NotificationServer) WaitForConnections(n int, timeout time.Duration) error {
+	deadline := time.After(timeout)
+
+	for {
+		s.mu.Lock()
+		connections, changed := s.connections, s.changed
+		s.mu.Unlock()
+
+		if connections >= n {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return errors.New("plextest: timed out waiting for a subscription")
+		}
+	}
+}
+
+// Filters returns the notification types the latest subscription asked for, nil for all
+func (s *NotificationServer) Filters() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filters
+}
+
+// Emit sends each notification, in order, to every connected subscriber
+func (s *NotificationServer) Emit(notifications ...plex.NotificationContainer) error {
+	for _, n := range notifications {
+		message, err := json.Marshal(plex.WebsocketNotification{NotificationContainer: n})
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.EmitRaw(message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EmitRaw sends message as is to every connected subscriber, e.g. to test malformed
+// notifications or types the plex package does not model
+func (s *NotificationServer) EmitRaw(message []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.conns) == 0 {
+		return errors.New("plextest: no subscriber connected")
+	}
+
+	for conn := range s.conns {
+		if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Disconnect drops every subscriber without a close handshake, as a server restart or
+// a network failure would
+func (s *NotificationServer) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		_ = conn.UnderlyingConn().Close()
+	}
+}
+
+// Close drops every subscriber and stops the server
+func (s *NotificationServer) Close() {
+	s.Disconnect()
+	s.server.Close()
+}
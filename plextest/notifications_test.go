@@ -0,0 +1,75 @@
+package plextest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timothystewart6/go-plex-client"
+)
+
+func TestNotificationServer(t *testing.T) {
+	server := NewNotificationServer()
+	defer server.Close()
+
+	client, err := plex.New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	states := make(chan string, 2)
+	disconnected := make(chan error, 1)
+
+	events := plex.NewNotificationEvents()
+	events.Filter("playing")
+	events.OnPlaying(func(n plex.NotificationContainer) {
+		for _, s := range n.PlaySessionStateNotification {
+			states <- s.State
+		}
+	})
+	events.OnDisconnect(func(err error) { disconnected <- err })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client.SubscribeToNotificationsWithContext(ctx, events, nil)
+
+	if err := server.WaitForConnections(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(server.Filters(), []string{"playing"}) {
+		t.Errorf("unexpected filters %q", server.Filters())
+	}
+
+	err = server.Emit(
+		plex.NotificationContainer{Type: "playing", PlaySessionStateNotification: []plex.PlaySessionStateNotification{{SessionKey: "1", State: "playing"}}},
+		plex.NotificationContainer{Type: "playing", PlaySessionStateNotification: []plex.PlaySessionStateNotification{{SessionKey: "1", State: "paused"}}},
+	)
+	if err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+
+	for _, want := range []string{"playing", "paused"} {
+		select {
+		case got := <-states:
+			if got != want {
+				t.Errorf("state = %q, want %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for the %q notification", want)
+		}
+	}
+
+	server.Disconnect()
+
+	select {
+	case err := <-disconnected:
+		if err == nil {
+			t.Errorf("expected a disconnect error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the disconnect")
+	}
+}
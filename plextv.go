@@ -3,12 +3,16 @@ package plex
 // I'll slowly migrate plex.tv related functions to this file
 
 import (
+	"bytes"
 	"encoding/json"
-	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
+	"path/filepath"
 	"strconv"
 )
 
@@ -38,8 +42,9 @@ type PinResponse struct {
 	}
 }
 
-// RequestPIN will retrieve a code (valid for 15 minutes) from plex.tv to link an app to your plex account
-func RequestPIN(requestHeaders headers) (PinResponse, error) {
+// RequestPIN will retrieve a code (valid for 15 minutes) from plex.tv to link an app to your plex account.
+// The request goes through a client configured with opts, e.g. WithHTTPClient or WithProxy.
+func RequestPIN(requestHeaders headers, opts ...Option) (PinResponse, error) {
 	endpoint := "/api/v2/pins.json"
 
 	// POST request and returns a 201 status code
@@ -54,11 +59,19 @@ func RequestPIN(requestHeaders headers) (PinResponse, error) {
 	// }
 	var pinInformation PinResponse
 
+	p := plexTVOptions(opts)
+
 	if requestHeaders.ClientIdentifier == "" {
 		requestHeaders = defaultHeaders()
+
+		if p.ClientIdentifier != "" {
+			requestHeaders.ClientIdentifier = p.ClientIdentifier
+		}
 	}
 
-	resp, err := post(plexURL+endpoint, nil, requestHeaders)
+	p.ClientIdentifier = requestHeaders.ClientIdentifier
+
+	resp, err := p.post(p.plexTV()+endpoint, nil, requestHeaders)
 
 	if err != nil {
 		return pinInformation, err
@@ -67,7 +80,7 @@ func RequestPIN(requestHeaders headers) (PinResponse, error) {
 	defer safeClose(resp.Body)
 
 	if resp.StatusCode != http.StatusCreated {
-		return pinInformation, errors.New(resp.Status)
+		return pinInformation, p.responseError(resp, errors.New(resp.Status))
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&pinInformation); err != nil {
@@ -79,19 +92,22 @@ func RequestPIN(requestHeaders headers) (PinResponse, error) {
 
 // CheckPIN will return information related to the pin such as the auth token if your code has been approved.
 // will return an error if code expired or still not linked
-// clientIdentifier must be the same when requesting a pin
-func CheckPIN(id int, clientIdentifier string) (PinResponse, error) {
+// clientIdentifier must be the same when requesting a pin, opts are applied as by RequestPIN
+func CheckPIN(id int, clientIdentifier string, opts ...Option) (PinResponse, error) {
 	endpoint := "/api/v2/pins/"
 
 	endpoint = endpoint + strconv.Itoa(id) + ".json"
 
 	headers := defaultHeaders()
+	p := plexTVOptions(opts)
 
 	if clientIdentifier != "" {
-		headers.ClientIdentifier = clientIdentifier
+		p.ClientIdentifier = clientIdentifier
+	} else if p.ClientIdentifier == "" {
+		p.ClientIdentifier = headers.ClientIdentifier
 	}
 
-	resp, err := get(plexURL+endpoint, headers)
+	resp, err := p.get(p.plexTV()+endpoint, headers)
 
 	if err != nil {
 		return PinResponse{}, err
@@ -132,7 +148,7 @@ func (p Plex) LinkAccount(code string) error {
 	headers.ContentType = "application/x-www-form-urlencoded"
 
 	// PUT request with 'code: <4-character-pin>' in the body
-	resp, err := p.put(plexURL+endpoint, []byte(body.Encode()), headers)
+	resp, err := p.put(p.plexTV()+endpoint, []byte(body.Encode()), headers)
 
 	if err != nil {
 		return err
@@ -182,7 +198,7 @@ func (p Plex) GetWebhooks() ([]string, error) {
 
 	endpoint := "/api/v2/user/webhooks"
 
-	resp, err := p.get(plexURL+endpoint, p.Headers)
+	resp, err := p.get(p.plexTV()+endpoint, p.Headers)
 
 	if err != nil {
 		return webhooks, err
@@ -248,7 +264,7 @@ func (p Plex) SetWebhooks(webhooks []string) error {
 
 	headers.ContentType = "application/x-www-form-urlencoded"
 
-	resp, err := p.post(plexURL+endpoint, []byte(body.Encode()), headers)
+	resp, err := p.post(p.plexTV()+endpoint, []byte(body.Encode()), headers)
 
 	if err != nil {
 		return err
@@ -265,11 +281,11 @@ func (p Plex) SetWebhooks(webhooks []string) error {
 
 // MyAccount gets account info (i.e. plex pass, servers, username, etc) from plex tv
 func (p Plex) MyAccount() (UserPlexTV, error) {
-	endpoint := "/users/account"
+	endpoint := "/api/v2/user"
 
 	var account UserPlexTV
 
-	resp, err := p.get(plexURL+endpoint, p.Headers)
+	resp, err := p.get(p.plexTV()+endpoint, p.Headers)
 
 	if err != nil {
 		return account, err
@@ -280,12 +296,199 @@ func (p Plex) MyAccount() (UserPlexTV, error) {
 	if resp.StatusCode == http.StatusUnprocessableEntity {
 		return account, errors.New(ErrorInvalidToken)
 	} else if resp.StatusCode != http.StatusOK {
-		return account, errors.New(resp.Status)
+		return account, p.responseError(resp, errors.New(resp.Status))
 	}
 
-	if err := xml.NewDecoder(resp.Body).Decode(&account); err != nil {
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
 		return account, err
 	}
 
 	return account, err
 }
+
+// SetAvatar uploads image as the avatar of your plex.tv account and returns the updated
+// account. filename is only used to detect the image type, e.g. "avatar.png".
+func (p *Plex) SetAvatar(filename string, image io.Reader) (UserPlexTV, error) {
+	var account UserPlexTV
+
+	var body bytes.Buffer
+
+	form := multipart.NewWriter(&body)
+
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="avatar"; filename=%q`, filepath.Base(filename)))
+	partHeader.Set("Content-Type", uploadContentType(filename))
+
+	part, err := form.CreatePart(partHeader)
+
+	if err != nil {
+		return account, err
+	}
+
+	if _, err := io.Copy(part, image); err != nil {
+		return account, err
+	}
+
+	if err := form.Close(); err != nil {
+		return account, err
+	}
+
+	h := p.Headers
+	h.ContentType = form.FormDataContentType()
+
+	resp, err := p.put(p.plexTV()+"/api/v2/user", body.Bytes(), h)
+
+	if err != nil {
+		return account, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return account, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return account, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&account)
+
+	return account, err
+}
+
+// Ping checks that plex.tv can be reached with your token and keeps it from expiring,
+// long running programs call it periodically
+func (p *Plex) Ping() error {
+	resp, err := p.get(p.plexTV()+"/api/v2/ping", p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
+
+// GeoIP is the location plex.tv associates with an ip address
+type GeoIP struct {
+	Code                       string `json:"code"`
+	ContinentCode              string `json:"continent_code"`
+	Country                    string `json:"country"`
+	City                       string `json:"city"`
+	TimeZone                   string `json:"time_zone"`
+	PostalCode                 string `json:"postal_code"`
+	Subdivisions               string `json:"subdivisions"`
+	Coordinates                string `json:"coordinates"`
+	EuropeanUnionMember        bool   `json:"european_union_member"`
+	InPrivacyRestrictedCountry bool   `json:"in_privacy_restricted_country"`
+	InPrivacyRestrictedRegion  bool   `json:"in_privacy_restricted_region"`
+}
+
+// GetGeoIP looks up the location of ipAddress, an empty ipAddress looks up your own public
+// address. Comparing results over time detects network changes.
+func (p *Plex) GetGeoIP(ipAddress string) (GeoIP, error) {
+	var geoIP GeoIP
+
+	query := p.plexTV() + "/api/v2/geoip"
+
+	if ipAddress != "" {
+		query += "?" + url.Values{"ip_address": {ipAddress}}.Encode()
+	}
+
+	resp, err := p.get(query, p.Headers)
+
+	if err != nil {
+		return geoIP, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return geoIP, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return geoIP, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&geoIP)
+
+	return geoIP, err
+}
+
+// Announcement is a notice from plex, e.g. about a new feature or a service outage
+type Announcement struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Content      string `json:"content"` // html
+	PlainContent string `json:"plainContent"`
+	URL          string `json:"url"`
+	ImageURL     string `json:"imageUrl"`
+	Read         bool   `json:"read"`
+	CreatedAt    int64  `json:"createdAt"`
+}
+
+// AnnouncementsContainer is the MediaContainer of GetAnnouncements
+type AnnouncementsContainer struct {
+	Size         int            `json:"size"`
+	Announcement []Announcement `json:"Announcement"`
+}
+
+// GetAnnouncements lists the announcements plex shows in its apps, newest first
+func (p *Plex) GetAnnouncements() ([]Announcement, error) {
+	results, err := getContainer[AnnouncementsContainer](p, p.plexTV()+"/api/announcements")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results.MediaContainer.Announcement, nil
+}
+
+// Feature is a feature flag of your plex.tv account
+type Feature struct {
+	ID   string `json:"id"`
+	UUID string `json:"uuid"`
+}
+
+// Features are the feature flags returned by GetFeatures
+type Features []Feature
+
+// Has is whether the feature with id is enabled, e.g. "hardware_transcoding"
+func (f Features) Has(id string) bool {
+	for _, feature := range f {
+		if feature.ID == id || feature.UUID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetFeatures lists the features enabled for your plex.tv account, including the ones
+// plex pass entitles you to
+func (p *Plex) GetFeatures() (Features, error) {
+	var features Features
+
+	resp, err := p.get(p.plexTV()+"/api/v2/features", p.Headers)
+
+	if err != nil {
+		return features, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return features, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return features, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&features)
+
+	return features, err
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -77,12 +79,7 @@ func TestRequestPIN(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			result, err := RequestPIN(tt.headers)
+			result, err := RequestPIN(tt.headers, WithPlexTVURL(server.URL))
 
 			if tt.expectError {
 				if err == nil {
@@ -183,12 +180,7 @@ func TestCheckPIN(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			result, err := CheckPIN(tt.id, tt.clientID)
+			result, err := CheckPIN(tt.id, tt.clientID, WithPlexTVURL(server.URL))
 
 			if tt.expectError {
 				if err == nil {
@@ -212,6 +204,39 @@ func TestCheckPIN(t *testing.T) {
 	}
 }
 
+// Test the pin requests go through a client configured with the options
+func TestPIN_Options(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.Header.Get("X-Plex-Client-Identifier")+" "+r.Header.Get("X-Trace"))
+
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+
+		_ = json.NewEncoder(w).Encode(PinResponse{ID: 7, Code: "ABCD", AuthToken: "token"})
+	}))
+	defer server.Close()
+
+	opts := []Option{WithPlexTVURL(server.URL), WithHeaders(http.Header{"X-Trace": {"abc"}}), WithClientIdentifier("app")}
+
+	pin, err := RequestPIN(headers{}, opts...)
+	if err != nil {
+		t.Fatalf("RequestPIN() error = %v", err)
+	}
+
+	if _, err := CheckPIN(pin.ID, "", opts...); err != nil {
+		t.Fatalf("CheckPIN() error = %v", err)
+	}
+
+	want := []string{"POST app abc", "GET app abc"}
+
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+}
+
 // Test LinkAccount function
 func TestPlex_LinkAccount(t *testing.T) {
 	tests := []struct {
@@ -270,12 +295,7 @@ func TestPlex_LinkAccount(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			plex := &Plex{Headers: defaultHeaders()}
+			plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
 			err := plex.LinkAccount(tt.code)
 
 			if tt.expectError {
@@ -426,12 +446,7 @@ func TestPlex_GetWebhooks(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			plex := &Plex{Headers: defaultHeaders()}
+			plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
 			result, err := plex.GetWebhooks()
 
 			if tt.expectError {
@@ -527,12 +542,7 @@ func TestPlex_AddWebhook(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			plex := &Plex{Headers: defaultHeaders()}
+			plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
 			err := plex.AddWebhook(tt.webhook)
 
 			if tt.expectError {
@@ -624,12 +634,7 @@ func TestPlex_SetWebhooks(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			plex := &Plex{Headers: defaultHeaders()}
+			plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
 			err := plex.SetWebhooks(tt.webhooks)
 
 			if tt.expectError {
@@ -658,11 +663,40 @@ func TestPlex_MyAccount(t *testing.T) {
 		{
 			name:       "successful account info",
 			statusCode: http.StatusOK,
-			response: `<?xml version="1.0" encoding="UTF-8"?>
-<user id="123" uuid="test-uuid" username="testuser" title="Test User" email="test@example.com">
-</user>`,
+			response: `{"id":123,"uuid":"test-uuid","username":"testuser","title":"Test User","email":"test@example.com","joinedAt":1424830400,"anonymous":null,
+				"subscription":{"active":true,"status":"Active","plan":"lifetime","features":["webhooks"]},
+				"profile":{"defaultAudioLanguage":"en","autoSelectSubtitle":1,"watchedIndicator":1},
+				"subscriptions":[{"id":1,"mode":"lifetime","renewsAt":null,"type":"plexpass","state":"active"}],
+				"pastSubscriptions":[{"id":2,"mode":"monthly","endsAt":1424830400,"type":"plexpass","state":"ended"}],
+				"roles":["plexpass"],"entitlements":["all"],
+				"home":true,"homeAdmin":true,"restricted":false,"protected":true,"mailingListStatus":"active",
+				"services":[{"identifier":"metadata","endpoint":"https://metadata.provider.plex.tv","token":"service-token","secret":null,"status":"online"}]}`,
 			expectError: false,
-			// Note: UserPlexTV struct may not have proper XML tags for parsing
+			expectedUser: UserPlexTV{
+				ID:           123,
+				UUID:         "test-uuid",
+				Username:     "testuser",
+				Title:        "Test User",
+				Email:        "test@example.com",
+				JoinedAt:     1424830400,
+				Subscription: PlexPassSubscription{Active: true, Status: "Active", Plan: "lifetime", Features: []string{"webhooks"}},
+				Profile:      UserProfile{DefaultAudioLanguage: "en", AutoSelectSubtitle: 1, WatchedIndicator: 1},
+				Subscriptions: []UserSubscription{
+					{ID: 1, Mode: "lifetime", Type: "plexpass", State: "active"},
+				},
+				PastSubscriptions: []UserSubscription{
+					{ID: 2, Mode: "monthly", EndsAt: 1424830400, Type: "plexpass", State: "ended"},
+				},
+				Roles:             []string{"plexpass"},
+				Entitlements:      []string{"all"},
+				Home:              true,
+				HomeAdmin:         true,
+				Protected:         true,
+				MailingListStatus: "active",
+				Services: []Services{
+					{Identifier: "metadata", Endpoint: "https://metadata.provider.plex.tv", Token: "service-token", Status: "online"},
+				},
+			},
 		},
 		{
 			name:         "invalid token",
@@ -677,9 +711,9 @@ func TestPlex_MyAccount(t *testing.T) {
 			errorMessage: "401 Unauthorized",
 		},
 		{
-			name:        "malformed XML",
+			name:        "malformed JSON",
 			statusCode:  http.StatusOK,
-			response:    `<?xml version="1.0" encoding="UTF-8"?><invalid-xml>`,
+			response:    `{"id":`,
 			expectError: true,
 		},
 	}
@@ -690,24 +724,19 @@ func TestPlex_MyAccount(t *testing.T) {
 				if r.Method != http.MethodGet {
 					t.Errorf("Expected GET method, got %s", r.Method)
 				}
-				if !strings.Contains(r.URL.Path, "/users/account") {
-					t.Errorf("Expected /users/account path, got %s", r.URL.Path)
+				if r.URL.Path != "/api/v2/user" {
+					t.Errorf("Expected /api/v2/user path, got %s", r.URL.Path)
 				}
 
 				w.WriteHeader(tt.statusCode)
 				if tt.response != "" {
-					w.Header().Set("Content-Type", "application/xml")
+					w.Header().Set("Content-Type", applicationJson)
 					_, _ = w.Write([]byte(tt.response))
 				}
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			plex := &Plex{Headers: defaultHeaders()}
+			plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
 			result, err := plex.MyAccount()
 
 			if tt.expectError {
@@ -721,10 +750,169 @@ func TestPlex_MyAccount(t *testing.T) {
 				if err != nil {
 					t.Errorf("Unexpected error: %v", err)
 				}
-				// Note: Due to UserPlexTV struct lacking XML tags, parsing may not work as expected
-				// This test primarily verifies the function doesn't crash and handles responses
-				_ = result // Avoid unused variable error
+				if !reflect.DeepEqual(result, tt.expectedUser) {
+					t.Errorf("MyAccount() = %+v, want %+v", result, tt.expectedUser)
+				}
 			}
 		})
 	}
 }
+
+func TestPlex_SetAvatar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v2/user" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		file, header, err := r.FormFile("avatar")
+		if err != nil {
+			t.Errorf("expected an avatar file: %v", err)
+			return
+		}
+		defer func() { _ = file.Close() }()
+
+		content, _ := io.ReadAll(file)
+
+		if header.Filename != "me.png" || header.Header.Get("Content-Type") != "image/png" || string(content) != "png data" {
+			t.Errorf("unexpected avatar %q %v %q", header.Filename, header.Header, content)
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"id":1,"username":"alice","thumb":"https://plex.tv/users/abc/avatar?c=2"}`))
+	}))
+	defer server.Close()
+
+	plex, err := New("", "token", WithPlexTVURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	account, err := plex.SetAvatar("/home/alice/me.png", strings.NewReader("png data"))
+	if err != nil {
+		t.Fatalf("SetAvatar() error = %v", err)
+	}
+
+	if account.Thumb != "https://plex.tv/users/abc/avatar?c=2" {
+		t.Errorf("unexpected account %+v", account)
+	}
+}
+
+func TestPlex_Ping(t *testing.T) {
+	status := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/ping" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	plex, err := New("", "token", WithPlexTVURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if err := plex.Ping(); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+
+	status = http.StatusUnauthorized
+
+	if err := plex.Ping(); err == nil {
+		t.Errorf("expected an error for an expired token")
+	}
+}
+
+func TestPlex_GetGeoIP(t *testing.T) {
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/geoip" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		gotQuery = r.URL.Query()
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"code":"DE","continent_code":"EU","country":"Germany","city":"Berlin","european_union_member":true,"time_zone":"Europe/Berlin","coordinates":"52.5, 13.4"}`))
+	}))
+	defer server.Close()
+
+	plex, err := New("", "token", WithPlexTVURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	geoIP, err := plex.GetGeoIP("203.0.113.7")
+	if err != nil {
+		t.Fatalf("GetGeoIP() error = %v", err)
+	}
+
+	if gotQuery.Get("ip_address") != "203.0.113.7" {
+		t.Errorf("unexpected query %v", gotQuery)
+	}
+
+	if geoIP.Code != "DE" || geoIP.City != "Berlin" || !geoIP.EuropeanUnionMember || geoIP.TimeZone != "Europe/Berlin" {
+		t.Errorf("unexpected geoip %+v", geoIP)
+	}
+
+	if _, err := plex.GetGeoIP(""); err != nil || gotQuery.Has("ip_address") {
+		t.Errorf("GetGeoIP() error = %v, query %v", err, gotQuery)
+	}
+}
+
+func TestPlex_GetAnnouncements(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/announcements" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Announcement":[{"id":"42","title":"Scheduled maintenance","content":"<p>Sunday</p>","plainContent":"Sunday","read":false,"createdAt":1700000000}]}}`))
+	}))
+	defer server.Close()
+
+	plex, err := New("", "token", WithPlexTVURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	announcements, err := plex.GetAnnouncements()
+	if err != nil {
+		t.Fatalf("GetAnnouncements() error = %v", err)
+	}
+
+	want := []Announcement{{ID: "42", Title: "Scheduled maintenance", Content: "<p>Sunday</p>", PlainContent: "Sunday", CreatedAt: 1700000000}}
+
+	if !reflect.DeepEqual(announcements, want) {
+		t.Errorf("GetAnnouncements() = %+v, want %+v", announcements, want)
+	}
+}
+
+func TestPlex_GetFeatures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/features" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`[{"id":"hardware_transcoding","uuid":"3a4f1c2e"},{"id":"lyrics","uuid":"9b7d2f10"}]`))
+	}))
+	defer server.Close()
+
+	plex, err := New("", "token", WithPlexTVURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	features, err := plex.GetFeatures()
+	if err != nil {
+		t.Fatalf("GetFeatures() error = %v", err)
+	}
+
+	if len(features) != 2 || !features.Has("hardware_transcoding") || !features.Has("9b7d2f10") || features.Has("sync") {
+		t.Errorf("unexpected features %+v", features)
+	}
+}
@@ -0,0 +1,176 @@
+package plex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Mapping states of RemoteAccessStatus
+const (
+	RemoteAccessMapped   = "mapped"
+	RemoteAccessUnmapped = "unmapped"
+	RemoteAccessFailed   = "failed"
+	RemoteAccessWaiting  = "waiting"
+)
+
+// RemoteAccessStatus is how your server is reachable from outside your network,
+// as reported by /myplex/account
+type RemoteAccessStatus struct {
+	Username       string        `json:"username"`
+	SignInState    string        `json:"signInState"`
+	MappingState   string        `json:"mappingState"` // one of the RemoteAccess* mapping states
+	MappingError   string        `json:"mappingError"`
+	PublicAddress  string        `json:"publicAddress"`
+	PublicPort     FlexibleInt64 `json:"publicPort"`
+	PrivateAddress string        `json:"privateAddress"`
+	PrivatePort    FlexibleInt64 `json:"privatePort"`
+}
+
+// Reachable is whether plex.tv could reach your server from outside your network
+func (r RemoteAccessStatus) Reachable() bool {
+	return r.MappingState == RemoteAccessMapped && r.MappingError == ""
+}
+
+// GetRemoteAccess returns the remote access status of your server
+func (p *Plex) GetRemoteAccess() (RemoteAccessStatus, error) {
+	var result struct {
+		MyPlex RemoteAccessStatus `json:"MyPlex"`
+	}
+
+	resp, err := p.get(p.ServerURL()+"/myplex/account", p.Headers)
+
+	if err != nil {
+		return result.MyPlex, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return result.MyPlex, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return result.MyPlex, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return result.MyPlex, err
+	}
+
+	return result.MyPlex, nil
+}
+
+// SetRemoteAccess enables or disables remote access to your server and asks plex.tv to
+// check it again, GetRemoteAccess reports the new mapping state once the check is done
+func (p *Plex) SetRemoteAccess(enabled bool) error {
+	value := "0"
+
+	if enabled {
+		value = "1"
+	}
+
+	if err := p.SetServerPrefs(map[string]string{ServerPrefPublishServer: value}); err != nil {
+		return err
+	}
+
+	return p.RefreshReachability()
+}
+
+// RefreshReachability asks plex.tv to check whether your server is reachable remotely
+func (p *Plex) RefreshReachability() error {
+	resp, err := p.put(p.ServerURL()+"/myplex/refreshReachability", nil, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
+
+// reachabilityPollInterval is how often CheckPortMapping checks whether plex.tv is done
+var reachabilityPollInterval = 2 * time.Second
+
+// reachabilityStaleChecks is how many times CheckPortMapping reads a state other than
+// waiting before trusting it. plex.tv keeps reporting the previous result for a moment
+// after a refresh, the new check is only certain once it was seen waiting.
+const reachabilityStaleChecks = 3
+
+// PortMapping is the result of CheckPortMapping
+type PortMapping struct {
+	// Reachable is whether plex.tv could connect to your server from outside your network
+	Reachable bool
+	// State is one of the RemoteAccess* mapping states, Error explains a failed mapping
+	State string
+	Error string
+	// Manual is whether the public port is set by hand instead of mapped with UPnP or NAT-PMP
+	Manual         bool
+	PublicAddress  string
+	PublicPort     int
+	PrivateAddress string
+	PrivatePort    int
+}
+
+// CheckPortMapping asks plex.tv to check whether your server is reachable remotely and
+// waits for the result, until ctx is done. Plex does not report the type of NAT your
+// server is behind, a failed automatic mapping usually means UPnP and NAT-PMP are off.
+// The result of a previous check is skipped, see reachabilityStaleChecks.
+func (p *Plex) CheckPortMapping(ctx context.Context) (PortMapping, error) {
+	var mapping PortMapping
+
+	prefs, err := p.GetServerPrefs()
+
+	if err != nil {
+		return mapping, err
+	}
+
+	if manual, ok := prefs.MediaContainer.Get(ServerPrefManualPortMapping); ok {
+		mapping.Manual = manual.Value.String() == "1" || manual.Value.String() == "true"
+	}
+
+	if err := p.RefreshReachability(); err != nil {
+		return mapping, err
+	}
+
+	ticker := time.NewTicker(reachabilityPollInterval)
+	defer ticker.Stop()
+
+	waited := false
+
+	for checks := 1; ; checks++ {
+		status, err := p.GetRemoteAccess()
+
+		if err != nil {
+			return mapping, err
+		}
+
+		if status.MappingState == RemoteAccessWaiting {
+			waited = true
+		} else if waited || checks >= reachabilityStaleChecks {
+			mapping.Reachable = status.Reachable()
+			mapping.State = status.MappingState
+			mapping.Error = status.MappingError
+			mapping.PublicAddress = status.PublicAddress
+			mapping.PublicPort = int(status.PublicPort)
+			mapping.PrivateAddress = status.PrivateAddress
+			mapping.PrivatePort = int(status.PrivatePort)
+
+			return mapping, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return mapping, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package plex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPlex_RemoteAccess(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+
+		if r.Method != http.MethodGet {
+			return
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MyPlex":{"username":"alice","signInState":"ok","mappingState":"mapped","mappingError":"",
+			"publicAddress":"203.0.113.7","publicPort":"32400","privateAddress":"192.168.1.2","privatePort":32400}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	status, err := p.GetRemoteAccess()
+	if err != nil {
+		t.Fatalf("GetRemoteAccess() error = %v", err)
+	}
+
+	if !status.Reachable() || status.PublicAddress != "203.0.113.7" || status.PublicPort != 32400 || status.PrivatePort != 32400 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	if err := p.SetRemoteAccess(false); err != nil {
+		t.Fatalf("SetRemoteAccess() error = %v", err)
+	}
+
+	want := []string{
+		"GET /myplex/account",
+		"PUT /:/prefs?PublishServerOnPlexOnlineKey=0",
+		"PUT /myplex/refreshReachability",
+	}
+
+	if len(requests) != len(want) {
+		t.Fatalf("unexpected requests %q", requests)
+	}
+
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
+
+func TestPlex_CheckPortMapping(t *testing.T) {
+	reachabilityPollInterval = time.Millisecond
+
+	checks := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+
+		switch r.URL.Path {
+		case "/:/prefs":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Setting":[{"id":"ManualPortMappingMode","type":"bool","value":"1"}]}}`))
+		case "/myplex/account":
+			if checks++; checks < 3 {
+				_, _ = w.Write([]byte(`{"MyPlex":{"mappingState":"waiting"}}`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"MyPlex":{"mappingState":"failed","mappingError":"unreachable","publicAddress":"203.0.113.7","publicPort":32400}}`))
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	mapping, err := p.CheckPortMapping(context.Background())
+	if err != nil {
+		t.Fatalf("CheckPortMapping() error = %v", err)
+	}
+
+	want := PortMapping{State: RemoteAccessFailed, Error: "unreachable", Manual: true, PublicAddress: "203.0.113.7", PublicPort: 32400}
+
+	if mapping != want || checks != 3 {
+		t.Errorf("CheckPortMapping() = %+v after %d checks, want %+v", mapping, checks, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	checks = -100
+
+	if _, err := p.CheckPortMapping(ctx); err != context.Canceled {
+		t.Errorf("expected a canceled check, got %v", err)
+	}
+}
+
+// Test the result plex.tv reports before it starts the new check is not returned
+func TestPlex_CheckPortMapping_StaleState(t *testing.T) {
+	reachabilityPollInterval = time.Millisecond
+
+	for name, states := range map[string][]string{
+		"old state first": {"mapped", "waiting", "failed"},
+		"never waiting":   {"mapped", "mapped", "failed", "mapped"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			checks := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", applicationJson)
+
+				switch r.URL.Path {
+				case "/:/prefs":
+					_, _ = w.Write([]byte(`{"MediaContainer":{"Setting":[]}}`))
+				case "/myplex/account":
+					state := states[min(checks, len(states)-1)]
+					checks++
+
+					_, _ = w.Write([]byte(`{"MyPlex":{"mappingState":"` + state + `"}}`))
+				}
+			}))
+			defer server.Close()
+
+			p, err := New(server.URL, "token")
+			if err != nil {
+				t.Fatalf("unexpected error from New: %v", err)
+			}
+
+			mapping, err := p.CheckPortMapping(context.Background())
+			if err != nil {
+				t.Fatalf("CheckPortMapping() error = %v", err)
+			}
+
+			if mapping.State != RemoteAccessFailed || checks != 3 {
+				t.Errorf("CheckPortMapping() state = %q after %d checks, want %q after 3", mapping.State, checks, RemoteAccessFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package plex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// videoResolutions are the resolutions plex reports and filters on, lowest first
+var videoResolutions = []string{"sd", "480", "576", "720", "1080", "4k"}
+
+// resolutionRank returns the position of a resolution in videoResolutions, -1 when unknown
+func resolutionRank(resolution string) int {
+	for i, r := range videoResolutions {
+		if strings.EqualFold(r, resolution) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// QualityReportOptions selects the items GetQualityReport reports. At least one of
+// BelowResolution, MinBitrate and Unanalyzed must be set.
+type QualityReportOptions struct {
+	Type            MediaType // items of a type other than the section's default, e.g. MediaTypeEpisode
+	BelowResolution string    // report items whose best version is below this resolution, e.g. "1080"
+	MinBitrate      int       // report items whose best version has a lower bitrate, in kbps
+	Unanalyzed      bool      // report items plex has not analyzed, lacking bitrate, codec or duration
+	PageSize        int       // defaults to 100
+}
+
+// QualityIssue is an item reported by GetQualityReport with the reasons it was reported
+type QualityIssue struct {
+	Item    Metadata
+	Reasons []string
+}
+
+// GetQualityReport lists the items of a section that are below a resolution or bitrate or
+// that lack analysis data. When only BelowResolution is set, the filtering is done by plex.
+func (p *Plex) GetQualityReport(sectionKey string, opts QualityReportOptions) ([]QualityIssue, error) {
+	if sectionKey == "" {
+		return nil, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	threshold := -1
+
+	if opts.BelowResolution != "" {
+		if threshold = resolutionRank(opts.BelowResolution); threshold == -1 {
+			return nil, fmt.Errorf(ErrorCommon, "unknown resolution "+opts.BelowResolution)
+		}
+	}
+
+	if threshold == -1 && opts.MinBitrate <= 0 && !opts.Unanalyzed {
+		return nil, fmt.Errorf(ErrorCommon, "a resolution, bitrate or unanalyzed criterion is required")
+	}
+
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultExportPageSize
+	}
+
+	filter := NewLibraryFilter()
+
+	if opts.Type != "" {
+		filter.Type(opts.Type)
+	}
+
+	if threshold > 0 && opts.MinBitrate <= 0 && !opts.Unanalyzed {
+		filter.Resolution(strings.Join(videoResolutions[:threshold], ","))
+	}
+
+	var issues []QualityIssue
+
+	err := p.walkSection(sectionKey, filter, opts.PageSize, func(items []Metadata, done, total int) error {
+		for _, item := range items {
+			if reasons := qualityReasons(item, threshold, opts); len(reasons) > 0 {
+				issues = append(issues, QualityIssue{Item: item, Reasons: reasons})
+			}
+		}
+
+		return nil
+	})
+
+	return issues, err
+}
+
+// qualityReasons returns why item falls below the criteria of opts, judging its best version
+func qualityReasons(item Metadata, threshold int, opts QualityReportOptions) []string {
+	bestResolution, bestBitrate, analyzed := -1, 0, false
+
+	for _, media := range item.Media {
+		if rank := resolutionRank(media.VideoResolution); rank > bestResolution {
+			bestResolution = rank
+		}
+
+		if media.Bitrate > bestBitrate {
+			bestBitrate = media.Bitrate
+		}
+
+		if media.Bitrate > 0 && media.Duration > 0 && (media.VideoCodec != "" || media.AudioCodec != "") {
+			analyzed = true
+		}
+	}
+
+	var reasons []string
+
+	if opts.Unanalyzed && !analyzed {
+		reasons = append(reasons, "not analyzed")
+	}
+
+	if threshold >= 0 && bestResolution >= 0 && bestResolution < threshold {
+		reasons = append(reasons, fmt.Sprintf("resolution %s below %s", videoResolutions[bestResolution], opts.BelowResolution))
+	}
+
+	if opts.MinBitrate > 0 && bestBitrate > 0 && bestBitrate < opts.MinBitrate {
+		reasons = append(reasons, fmt.Sprintf("bitrate %d kbps below %d kbps", bestBitrate, opts.MinBitrate))
+	}
+
+	return reasons
+}
@@ -0,0 +1,69 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPlex_GetQualityReport(t *testing.T) {
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"totalSize":4,"Metadata":[
+			{"ratingKey":"1","title":"Heat","Media":[{"videoResolution":"720","bitrate":3000,"duration":100,"videoCodec":"h264"},{"videoResolution":"1080","bitrate":9000,"duration":100,"videoCodec":"hevc"}]},
+			{"ratingKey":"2","title":"Thief","Media":[{"videoResolution":"sd","bitrate":1200,"duration":100,"videoCodec":"mpeg2video"}]},
+			{"ratingKey":"3","title":"Ronin","Media":[{"videoResolution":"4k"}]},
+			{"ratingKey":"4","title":"Collateral","Media":[{"videoResolution":"1080","bitrate":4500,"duration":100,"videoCodec":"h264"}]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	issues, err := p.GetQualityReport("1", QualityReportOptions{BelowResolution: "1080", MinBitrate: 5000, Unanalyzed: true})
+	if err != nil {
+		t.Fatalf("GetQualityReport() error = %v", err)
+	}
+
+	got := map[string][]string{}
+	for _, issue := range issues {
+		got[issue.Item.RatingKey] = issue.Reasons
+	}
+
+	want := map[string][]string{
+		"2": {"resolution sd below 1080", "bitrate 1200 kbps below 5000 kbps"},
+		"3": {"not analyzed"},
+		"4": {"bitrate 4500 kbps below 5000 kbps"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetQualityReport() = %v, want %v", got, want)
+	}
+
+	if gotQuery.Has("resolution") {
+		t.Errorf("expected no server side resolution filter with other criteria, got %v", gotQuery)
+	}
+
+	if _, err := p.GetQualityReport("1", QualityReportOptions{BelowResolution: "720", Type: MediaTypeEpisode}); err != nil {
+		t.Fatalf("GetQualityReport() error = %v", err)
+	}
+
+	if gotQuery.Get("resolution") != "sd,480,576" || gotQuery.Get("type") != "4" {
+		t.Errorf("unexpected filter %v", gotQuery)
+	}
+
+	for _, opts := range []QualityReportOptions{{}, {BelowResolution: "8k"}} {
+		if _, err := p.GetQualityReport("1", opts); err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+	}
+}
@@ -1,23 +1,65 @@
 package plex
 
-import "regexp"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultSearchLimit is the number of results SearchPlex keeps
+const DefaultSearchLimit = 4
+
+// SearchOptions configures SearchPlexWithOptions
+type SearchOptions struct {
+	// Limit is the maximum number of results to keep. 0 uses DefaultSearchLimit and a
+	// negative value keeps every result.
+	Limit int
+	// Type only returns results of a media type, e.g. MediaTypeMovie to leave out shows and actors
+	Type MediaType
+	// SectionID only returns results from a library section
+	SectionID string
+}
 
-// SearchPlex searches just like Search, but omits the last 4 results which are not relevant
+// SearchPlex searches just like Search, but only keeps the first DefaultSearchLimit results
+// as the following ones are usually not relevant
 func (p *Plex) SearchPlex(title string) (SearchResults, error) {
-	results, err := p.Search(title)
+	return p.SearchPlexWithOptions(title, SearchOptions{})
+}
+
+// SearchPlexWithOptions searches just like Search, scoped to the type and section of opts and
+// keeping up to opts.Limit results
+func (p *Plex) SearchPlexWithOptions(title string, opts SearchOptions) (SearchResults, error) {
+	if title == "" {
+		return SearchResults{}, fmt.Errorf(ErrorCommon, ErrorTitleRequired)
+	}
+
+	results, err := p.search(title, opts)
 
 	if err != nil {
 		return SearchResults{}, err
 	}
 
-	// Only limit to 4 results if there are more than 4
-	if len(results.MediaContainer.Metadata) > 4 {
-		results.MediaContainer.Metadata = results.MediaContainer.Metadata[:4]
+	if limit := opts.limit(); limit > 0 && len(results.MediaContainer.Metadata) > limit {
+		results.MediaContainer.Metadata = results.MediaContainer.Metadata[:limit]
 	}
 
 	return results, nil
 }
 
+// limit returns the number of results to keep, 0 to keep them all
+func (o SearchOptions) limit() int {
+	switch {
+	case o.Limit == 0:
+		return DefaultSearchLimit
+	case o.Limit < 0:
+		return 0
+	default:
+		return o.Limit
+	}
+}
+
 // ExtractKeyAndThumbFromURL extracts the rating key and thumbnail id from the url
 func (p *Plex) ExtractKeyAndThumbFromURL(_url string) (string, string) {
 	count := len(_url)
@@ -79,3 +121,67 @@ func (p *Plex) ExtractKeyFromRatingKeyRegex(key string) string {
 
 	return result[0]
 }
+
+// errGUIDFound stops walking a section once FindByGUID found its item
+var errGUIDFound = errors.New("guid found")
+
+// FindByGUID looks up the item of a section with guid, a plex guid such as
+// "plex://movie/5d776825880197001ec967c8" or an external one such as "tmdb://603" or
+// "imdb://tt0133093". ok is false when the section has no such item. Plex filters by guid
+// on the server, servers that can't are answered by listing the section.
+func (p *Plex) FindByGUID(sectionKey, guid string) (item Metadata, ok bool, err error) {
+	if sectionKey == "" || guid == "" {
+		return item, false, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	query := fmt.Sprintf("%s/library/sections/%s/all?includeGuids=1&guid=%s", p.ServerURL(), sectionKey, url.QueryEscape(guid))
+
+	results, err := getContainer[MediaContainer](p, query)
+
+	if err != nil {
+		return item, false, err
+	}
+
+	// servers ignoring the guid filter list the whole section, so every result is checked
+	for _, m := range results.MediaContainer.Metadata {
+		if hasGUID(m, guid) {
+			return m, true, nil
+		}
+	}
+
+	if strings.HasPrefix(guid, "plex://") {
+		return item, false, nil
+	}
+
+	err = p.walkSection(sectionKey, NewLibraryFilter().Set("includeGuids", "1"), defaultExportPageSize, func(items []Metadata, done, total int) error {
+		for _, m := range items {
+			if hasGUID(m, guid) {
+				item, ok = m, true
+				return errGUIDFound
+			}
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, errGUIDFound) {
+		err = nil
+	}
+
+	return item, ok, err
+}
+
+// hasGUID reports whether guid is the plex guid or one of the external guids of m
+func hasGUID(m Metadata, guid string) bool {
+	if strings.EqualFold(m.GUID, guid) {
+		return true
+	}
+
+	for _, alt := range m.AltGUIDs {
+		if strings.EqualFold(alt.ID, guid) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,11 @@
 package plex
 
-import "testing"
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
 
 func TestExtractKeyFromRatingKey(t *testing.T) {
 	keys := [][]string{
@@ -100,3 +105,123 @@ func BenchmarkExtractKeyFromRatingKeyRegex(b *testing.B) {
 		p.ExtractKeyFromRatingKeyRegex(ratingKey)
 	}
 }
+
+func TestPlex_SearchPlexWithOptions(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/search": `{"MediaContainer":{"size":6,"Metadata":[{"title":"1"},{"title":"2"},{"title":"3"},{"title":"4"},{"title":"5"},{"title":"6"}]}}`,
+	})
+
+	tests := []struct {
+		limit    int
+		expected int
+	}{
+		{0, DefaultSearchLimit},
+		{2, 2},
+		{10, 6},
+		{-1, 6},
+	}
+
+	for _, test := range tests {
+		results, err := p.SearchPlexWithOptions("heat", SearchOptions{Limit: test.limit})
+		if err != nil {
+			t.Fatalf("SearchPlexWithOptions() error = %v", err)
+		}
+
+		if got := len(results.MediaContainer.Metadata); got != test.expected {
+			t.Errorf("limit %d: got %d results, want %d", test.limit, got, test.expected)
+		}
+	}
+}
+
+func TestPlex_SearchScoped(t *testing.T) {
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := p.SearchPlexWithOptions("the thing", SearchOptions{Type: MediaTypeMovie, SectionID: "1"}); err != nil {
+		t.Fatalf("SearchPlexWithOptions() error = %v", err)
+	}
+
+	if gotQuery.Get("query") != "the thing" || gotQuery.Get("type") != "1" || gotQuery.Get("sectionId") != "1" {
+		t.Errorf("unexpected query %v", gotQuery)
+	}
+
+	if _, err := p.Search("the thing"); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if gotQuery.Has("type") || gotQuery.Has("sectionId") {
+		t.Errorf("expected an unscoped search, got %v", gotQuery)
+	}
+
+	if _, err := p.SearchPlexWithOptions("the thing", SearchOptions{Type: "hologram"}); err == nil {
+		t.Error("expected an error for an unknown media type")
+	}
+}
+
+func TestPlex_FindByGUID(t *testing.T) {
+	var queries []url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		queries = append(queries, q)
+
+		w.Header().Set("Content-Type", applicationJson)
+
+		// a server ignoring the guid filter
+		if q.Get("X-Plex-Container-Start") == "0" || q.Has("guid") {
+			_, _ = w.Write([]byte(`{"MediaContainer":{"totalSize":3,"Metadata":[
+				{"ratingKey":"1","title":"Heat","guid":"plex://movie/1","Guid":[{"id":"tmdb://949"}]},
+				{"ratingKey":"2","title":"The Matrix","guid":"plex://movie/2","Guid":[{"id":"tmdb://603"}]}
+			]}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"MediaContainer":{"totalSize":3,"Metadata":[{"ratingKey":"3","title":"Ronin","guid":"plex://movie/3","Guid":[{"id":"imdb://tt0122690"}]}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	item, ok, err := p.FindByGUID("1", "tmdb://603")
+	if err != nil || !ok || item.Title != "The Matrix" {
+		t.Errorf("FindByGUID(tmdb://603) = %v, %v, %v", item.Title, ok, err)
+	}
+
+	if queries[0].Get("guid") != "tmdb://603" {
+		t.Errorf("expected a server side guid filter, got %v", queries[0])
+	}
+
+	item, ok, err = p.FindByGUID("1", "imdb://tt0122690")
+	if err != nil || !ok || item.RatingKey != "3" {
+		t.Errorf("FindByGUID(imdb://tt0122690) = %v, %v, %v", item.Title, ok, err)
+	}
+
+	queries = nil
+
+	if _, ok, err := p.FindByGUID("1", "plex://movie/9"); err != nil || ok {
+		t.Errorf("FindByGUID(plex://movie/9) = %v, %v", ok, err)
+	}
+
+	if len(queries) != 1 {
+		t.Errorf("expected plex guids to only be filtered on the server, got %d requests", len(queries))
+	}
+
+	if _, ok, err := p.FindByGUID("1", "tvdb://1"); err != nil || ok {
+		t.Errorf("FindByGUID(tvdb://1) = %v, %v", ok, err)
+	}
+}
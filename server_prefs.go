@@ -0,0 +1,208 @@
+package plex
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Server settings that can be changed with SetServerPrefs, GetServerPrefs lists them all
+const (
+	ServerPrefFriendlyName           = "FriendlyName"
+	ServerPrefCustomConnections      = "customConnections"
+	ServerPrefLANNetworks            = "LanNetworksBandwidth"
+	ServerPrefAllowedNetworks        = "allowedNetworks"
+	ServerPrefSecureConnections      = "secureConnections"
+	ServerPrefRemoteStreamMaxBitrate = "WanPerStreamMaxUploadRate"
+	ServerPrefRemoteTotalMaxBitrate  = "WanTotalMaxUploadRate"
+	ServerPrefPublishServer          = "PublishServerOnPlexOnlineKey"
+	ServerPrefManualPortMapping      = "ManualPortMappingMode"
+	ServerPrefManualPort             = "ManualPortMappingPort"
+)
+
+// ServerPrefs is the response of GetServerPrefs
+type ServerPrefs = Container[LibraryPrefsContainer]
+
+// GetServerPrefs returns the settings of your server with their current values
+func (p *Plex) GetServerPrefs() (ServerPrefs, error) {
+	return getContainer[LibraryPrefsContainer](p, p.ServerURL()+"/:/prefs")
+}
+
+// SetServerPrefs changes settings of your server, prefs maps setting ids to values,
+// e.g. {ServerPrefFriendlyName: "Living Room"}
+func (p *Plex) SetServerPrefs(prefs map[string]string) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+
+	vals := url.Values{}
+
+	for id, value := range prefs {
+		vals.Set(id, value)
+	}
+
+	resp, err := p.put(p.ServerURL()+"/:/prefs?"+vals.Encode(), nil, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
+
+// SetFriendlyName renames your server, the name shown in plex apps
+func (p *Plex) SetFriendlyName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf(ErrorCommon, "a server name is required")
+	}
+
+	return p.SetServerPrefs(map[string]string{ServerPrefFriendlyName: name})
+}
+
+// SecureConnectionsMode is whether clients must connect to your server over https
+type SecureConnectionsMode int
+
+// Values of the secureConnections server setting
+const (
+	SecureConnectionsRequired  SecureConnectionsMode = 0
+	SecureConnectionsPreferred SecureConnectionsMode = 1
+	SecureConnectionsDisabled  SecureConnectionsMode = 2
+)
+
+// NetworkSettings are the network settings of your server most often scripted
+type NetworkSettings struct {
+	// CustomConnections are urls advertised in addition to the ones plex detects,
+	// e.g. "https://plex.example.com:443"
+	CustomConnections []string
+	// LANNetworks are the networks treated as local, e.g. "192.168.1.0/24"
+	LANNetworks []string
+	// AllowedNetworks can use the server without signing in
+	AllowedNetworks   []string
+	SecureConnections SecureConnectionsMode
+	// RemoteStreamMaxBitrate limits each remote stream, in kbps, 0 means unlimited
+	RemoteStreamMaxBitrate int
+	// RemoteTotalMaxBitrate limits all remote streams together, in kbps, 0 means unlimited
+	RemoteTotalMaxBitrate int
+}
+
+// GetNetworkSettings returns the network settings of your server
+func (p *Plex) GetNetworkSettings() (NetworkSettings, error) {
+	var settings NetworkSettings
+
+	prefs, err := p.GetServerPrefs()
+
+	if err != nil {
+		return settings, err
+	}
+
+	value := func(id string) string {
+		setting, _ := prefs.MediaContainer.Get(id)
+		return setting.Value.String()
+	}
+
+	number := func(id string) int {
+		n, _ := strconv.Atoi(value(id))
+		return n
+	}
+
+	settings.CustomConnections = splitPrefList(value(ServerPrefCustomConnections))
+	settings.LANNetworks = splitPrefList(value(ServerPrefLANNetworks))
+	settings.AllowedNetworks = splitPrefList(value(ServerPrefAllowedNetworks))
+	settings.SecureConnections = SecureConnectionsMode(number(ServerPrefSecureConnections))
+	settings.RemoteStreamMaxBitrate = number(ServerPrefRemoteStreamMaxBitrate)
+	settings.RemoteTotalMaxBitrate = number(ServerPrefRemoteTotalMaxBitrate)
+
+	return settings, nil
+}
+
+// SetCustomConnections replaces the urls your server advertises in addition to the
+// detected ones, nil clears them
+func (p *Plex) SetCustomConnections(urls []string) error {
+	for _, u := range urls {
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf(ErrorCommon, "invalid connection url "+u)
+		}
+	}
+
+	return p.SetServerPrefs(map[string]string{ServerPrefCustomConnections: strings.Join(urls, ",")})
+}
+
+// SetLANNetworks replaces the networks your server treats as local, nil lets plex detect them
+func (p *Plex) SetLANNetworks(networks []string) error {
+	if err := validateNetworks(networks); err != nil {
+		return err
+	}
+
+	return p.SetServerPrefs(map[string]string{ServerPrefLANNetworks: strings.Join(networks, ",")})
+}
+
+// SetAllowedNetworks replaces the networks that can use your server without signing in
+func (p *Plex) SetAllowedNetworks(networks []string) error {
+	if err := validateNetworks(networks); err != nil {
+		return err
+	}
+
+	return p.SetServerPrefs(map[string]string{ServerPrefAllowedNetworks: strings.Join(networks, ",")})
+}
+
+// SetSecureConnections sets whether clients must connect over https
+func (p *Plex) SetSecureConnections(mode SecureConnectionsMode) error {
+	if mode < SecureConnectionsRequired || mode > SecureConnectionsDisabled {
+		return fmt.Errorf(ErrorCommon, "invalid secure connections mode")
+	}
+
+	return p.SetServerPrefs(map[string]string{ServerPrefSecureConnections: strconv.Itoa(int(mode))})
+}
+
+// SetRemoteBitrateLimits limits the bitrate of remote streams in kbps, per stream and in
+// total. 0 removes a limit.
+func (p *Plex) SetRemoteBitrateLimits(perStream, total int) error {
+	if perStream < 0 || total < 0 {
+		return fmt.Errorf(ErrorCommon, "bitrate limits must not be negative")
+	}
+
+	return p.SetServerPrefs(map[string]string{
+		ServerPrefRemoteStreamMaxBitrate: strconv.Itoa(perStream),
+		ServerPrefRemoteTotalMaxBitrate:  strconv.Itoa(total),
+	})
+}
+
+// validateNetworks checks networks are ip addresses or cidr ranges
+func validateNetworks(networks []string) error {
+	for _, network := range networks {
+		if net.ParseIP(network) != nil {
+			continue
+		}
+
+		if _, _, err := net.ParseCIDR(network); err != nil {
+			return fmt.Errorf(ErrorCommon, "invalid network "+network)
+		}
+	}
+
+	return nil
+}
+
+// splitPrefList splits a comma separated setting value, ignoring empty entries
+func splitPrefList(value string) []string {
+	var list []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
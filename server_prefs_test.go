@@ -0,0 +1,124 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPlex_ServerPrefs(t *testing.T) {
+	var got url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/:/prefs" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		if r.Method == http.MethodPut {
+			got = r.URL.Query()
+			return
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Setting":[{"id":"FriendlyName","type":"text","default":"","value":"nas"}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	prefs, err := p.GetServerPrefs()
+	if err != nil {
+		t.Fatalf("GetServerPrefs() error = %v", err)
+	}
+
+	if name, ok := prefs.MediaContainer.Get(ServerPrefFriendlyName); !ok || name.Value != "nas" {
+		t.Errorf("unexpected prefs %+v", prefs.MediaContainer)
+	}
+
+	if err := p.SetFriendlyName("Living Room"); err != nil {
+		t.Fatalf("SetFriendlyName() error = %v", err)
+	}
+
+	if got.Get("FriendlyName") != "Living Room" {
+		t.Errorf("unexpected query %v", got)
+	}
+
+	if err := p.SetFriendlyName(" "); err == nil {
+		t.Errorf("expected an error for an empty name")
+	}
+}
+
+func TestPlex_NetworkSettings(t *testing.T) {
+	var got url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			got = r.URL.Query()
+			return
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":5,"Setting":[
+			{"id":"customConnections","type":"text","value":"https://plex.example.com:443, http://10.0.0.2:32400"},
+			{"id":"LanNetworksBandwidth","type":"text","value":""},
+			{"id":"allowedNetworks","type":"text","value":"192.168.1.0/24"},
+			{"id":"secureConnections","type":"int","value":"1"},
+			{"id":"WanPerStreamMaxUploadRate","type":"int","value":"8000"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	settings, err := p.GetNetworkSettings()
+	if err != nil {
+		t.Fatalf("GetNetworkSettings() error = %v", err)
+	}
+
+	want := NetworkSettings{
+		CustomConnections:      []string{"https://plex.example.com:443", "http://10.0.0.2:32400"},
+		AllowedNetworks:        []string{"192.168.1.0/24"},
+		SecureConnections:      SecureConnectionsPreferred,
+		RemoteStreamMaxBitrate: 8000,
+	}
+
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("GetNetworkSettings() = %+v, want %+v", settings, want)
+	}
+
+	if err := p.SetCustomConnections([]string{"https://plex.example.com:443"}); err != nil || got.Get(ServerPrefCustomConnections) != "https://plex.example.com:443" {
+		t.Errorf("SetCustomConnections() error = %v, query %v", err, got)
+	}
+
+	if err := p.SetLANNetworks([]string{"192.168.1.0/24", "10.0.0.5"}); err != nil || got.Get(ServerPrefLANNetworks) != "192.168.1.0/24,10.0.0.5" {
+		t.Errorf("SetLANNetworks() error = %v, query %v", err, got)
+	}
+
+	if err := p.SetSecureConnections(SecureConnectionsRequired); err != nil || got.Get(ServerPrefSecureConnections) != "0" {
+		t.Errorf("SetSecureConnections() error = %v, query %v", err, got)
+	}
+
+	if err := p.SetRemoteBitrateLimits(4000, 0); err != nil || got.Get(ServerPrefRemoteStreamMaxBitrate) != "4000" || got.Get(ServerPrefRemoteTotalMaxBitrate) != "0" {
+		t.Errorf("SetRemoteBitrateLimits() error = %v, query %v", err, got)
+	}
+
+	if err := p.SetCustomConnections([]string{"plex.example.com"}); err == nil {
+		t.Errorf("expected an error for a url without a scheme")
+	}
+
+	if err := p.SetLANNetworks([]string{"192.168.1.0/33"}); err == nil {
+		t.Errorf("expected an error for an invalid network")
+	}
+
+	if err := p.SetSecureConnections(3); err == nil {
+		t.Errorf("expected an error for an invalid mode")
+	}
+}
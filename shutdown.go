@@ -0,0 +1,36 @@
+package plex
+
+import (
+	"context"
+	"errors"
+)
+
+// Shutdowner is implemented by the components that keep running in the background,
+// such as a Subscription, a started HealthMonitor, a HistoryRecorder or WebhookEvents
+// dispatching asynchronously
+type Shutdowner interface {
+	// Close stops the component and waits for its goroutines and in-flight work
+	Close() error
+	// Shutdown is like Close but stops waiting when ctx is done, returning ctx.Err()
+	Shutdown(ctx context.Context) error
+}
+
+// ShutdownAll shuts the components down concurrently and joins their errors, e.g. when
+// the program receives SIGTERM
+func ShutdownAll(ctx context.Context, components ...Shutdowner) error {
+	errs := make(chan error, len(components))
+
+	for _, c := range components {
+		go func(c Shutdowner) {
+			errs <- c.Shutdown(ctx)
+		}(c)
+	}
+
+	var all []error
+
+	for range components {
+		all = append(all, <-errs)
+	}
+
+	return errors.Join(all...)
+}
@@ -0,0 +1,266 @@
+package plex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSubscription_Close(t *testing.T) {
+	srv, p := newWebsocketTestServer(t, func(conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer srv.Close()
+
+	var disconnected atomic.Bool
+
+	events := NewNotificationEvents()
+	events.OnDisconnect(func(err error) { disconnected.Store(true) })
+
+	sub, err := p.Subscribe(context.Background(), events, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if err := sub.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if !disconnected.Load() {
+		t.Error("expected Close to wait for the reader to disconnect")
+	}
+}
+
+func TestSubscription_ShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	// the server never reads, so it never acknowledges the close
+	srv, p := newWebsocketTestServer(t, func(conn *websocket.Conn) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{}`))
+		<-release
+	})
+	defer srv.Close()
+
+	received := make(chan struct{}, 1)
+
+	events := NewNotificationEvents()
+	events.OnRaw(func(message []byte) { received <- struct{}{} })
+
+	sub, err := p.Subscribe(context.Background(), events, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// let the reader wait for the next message
+	<-received
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := sub.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if _, err := (&Plex{URL: "http://127.0.0.1:1"}).Subscribe(context.Background(), NewNotificationEvents(), nil); err == nil {
+		t.Error("expected an error for an unreachable server")
+	}
+}
+
+func TestHealthMonitor_Shutdown(t *testing.T) {
+	var checks atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checks.Add(1)
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"machineIdentifier":"abc","version":"1.40.0"}}`))
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	m := NewHealthMonitor(ServerClient{Name: "home", Client: client})
+	m.Interval = time.Millisecond
+
+	m.Start()
+	m.Start()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for checks.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := ShutdownAll(context.Background(), m); err != nil {
+		t.Fatalf("ShutdownAll() error = %v", err)
+	}
+
+	stopped := checks.Load()
+	time.Sleep(20 * time.Millisecond)
+
+	if checks.Load() != stopped {
+		t.Error("expected no checks after shutdown")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Errorf("Close() of a stopped monitor error = %v", err)
+	}
+}
+
+func TestSubscription_ShutdownWaitsForPlayingSession(t *testing.T) {
+	fetching := make(chan struct{}, 1)
+	release := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/:/websockets/notifications", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"NotificationContainer":{"type":"playing",`+
+			`"PlaySessionStateNotification":[{"sessionKey":"5","ratingKey":"42","state":"playing"}]}}`))
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/status/sessions", func(w http.ResponseWriter, r *http.Request) {
+		fetching <- struct{}{}
+		<-release
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := &Plex{URL: server.URL, Token: "test-token", ClientIdentifier: "test-client", HTTPClient: http.Client{Timeout: 5 * time.Second}}
+
+	var calls atomic.Int32
+
+	events := NewNotificationEvents()
+	events.OnPlayingSession(p, func(event PlayingEvent) { calls.Add(1) })
+
+	sub, err := p.Subscribe(context.Background(), events, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	<-fetching
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := sub.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v while the callback runs", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+
+	if err := sub.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	// the notification arrived before the shutdown, it is dropped as the dispatcher was stopped
+	if n := calls.Load(); n != 0 {
+		t.Errorf("expected no callback after shutdown, got %d", n)
+	}
+
+	events.events["playing"](NotificationContainer{
+		PlaySessionStateNotification: []PlaySessionStateNotification{{SessionKey: "5", State: "paused"}},
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	if n := calls.Load(); n != 0 {
+		t.Errorf("expected notifications after Close to be dropped, got %d callbacks", n)
+	}
+}
+
+func TestPlayingSessions_BoundedQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &playingSessions{ctx: ctx, running: true}
+
+	for i := 0; i < maxPendingPlaying+10; i++ {
+		s.add([]PlaySessionStateNotification{{SessionKey: strconv.Itoa(i)}})
+	}
+
+	if len(s.pending) != maxPendingPlaying {
+		t.Fatalf("expected %d queued notifications, got %d", maxPendingPlaying, len(s.pending))
+	}
+
+	if first := s.pending[0].SessionKey; first != "10" {
+		t.Errorf("expected the oldest notifications to be dropped, first is %q", first)
+	}
+}
+
+func TestHistoryRecorder_Shutdown(t *testing.T) {
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[{"ratingKey":"42","type":"movie","title":"Heat"}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	recorder := NewHistoryRecorder(NewJSONFileHistoryStore(filepath.Join(t.TempDir(), "history.jsonl")))
+	recorder.Plex = p
+
+	recorder.RecordNotification(NotificationContainer{
+		PlaySessionStateNotification: []PlaySessionStateNotification{{SessionKey: "5", RatingKey: "42", State: "playing"}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := ShutdownAll(ctx, recorder); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v while the lookup runs", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+
+	if err := recorder.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	recorder.RecordNotification(NotificationContainer{
+		PlaySessionStateNotification: []PlaySessionStateNotification{{SessionKey: "6", RatingKey: "43", State: "playing"}},
+	})
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if _, ok := recorder.active["6"]; ok {
+		t.Error("expected events after Close to be ignored")
+	}
+}
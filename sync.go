@@ -0,0 +1,169 @@
+package plex
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GetSyncItems lists the content synced to the device with clientID for offline playback
+func (p *Plex) GetSyncItems(clientID string) (SyncItems, error) {
+	if clientID == "" {
+		return SyncItems{}, fmt.Errorf(ErrorCommon, "client id is required")
+	}
+
+	query := fmt.Sprintf("%s/devices/%s/sync_items", p.plexTV(), url.PathEscape(clientID))
+
+	resp, err := p.get(query, p.Headers)
+
+	if err != nil {
+		return SyncItems{}, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return SyncItems{}, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return SyncItems{}, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	var result SyncItems
+
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return SyncItems{}, err
+	}
+
+	return result, nil
+}
+
+// CreateSyncItem syncs an item of the server at p.URL to the device with clientID
+func (p *Plex) CreateSyncItem(clientID string, params SyncItemParams) error {
+	if clientID == "" {
+		return fmt.Errorf(ErrorCommon, "client id is required")
+	}
+
+	if params.Key == "" {
+		return fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	metadata, err := p.GetMetadata(params.Key)
+
+	if err != nil {
+		return err
+	}
+
+	if len(metadata.MediaContainer.Metadata) == 0 {
+		return fmt.Errorf(ErrorCommon, "no library item found for key "+params.Key)
+	}
+
+	item := metadata.MediaContainer.Metadata[0]
+
+	machineID, err := p.serverMachineID()
+
+	if err != nil {
+		return err
+	}
+
+	title := params.Title
+
+	if title == "" {
+		title = item.Title
+	}
+
+	vals := url.Values{}
+	vals.Set("SyncItem[title]", title)
+	vals.Set("SyncItem[rootTitle]", title)
+	vals.Set("SyncItem[metadataType]", item.Type)
+	vals.Set("SyncItem[contentType]", syncContentType(item.MediaType()))
+	vals.Set("SyncItem[machineIdentifier]", machineID)
+	vals.Set("SyncItem[Location][uri]", libraryItemURI(metadata.MediaContainer.LibrarySectionUUID, item.Key))
+	vals.Set("SyncItem[Policy][scope]", "all")
+	vals.Set("SyncItem[Policy][unwatched]", boolToOneOrZero(params.Unwatched))
+
+	if params.Limit > 0 {
+		vals.Set("SyncItem[Policy][scope]", "count")
+		vals.Set("SyncItem[Policy][value]", strconv.Itoa(params.Limit))
+	}
+
+	settings := map[string]string{
+		"videoQuality":    intParam(params.VideoQuality),
+		"videoResolution": params.VideoResolution,
+		"maxVideoBitrate": intParam(params.MaxVideoBitrate),
+		"musicBitrate":    intParam(params.MusicBitrate),
+		"photoResolution": params.PhotoResolution,
+	}
+
+	for name, value := range settings {
+		if value != "" {
+			vals.Set("SyncItem[MediaSettings]["+name+"]", value)
+		}
+	}
+
+	query := fmt.Sprintf("%s/devices/%s/sync_items?%s", p.plexTV(), url.PathEscape(clientID), vals.Encode())
+
+	resp, err := p.post(query, nil, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
+
+// DeleteSyncItem removes synced content from a device, the device deletes its copy on its next sync
+func (p *Plex) DeleteSyncItem(clientID string, id int) error {
+	if clientID == "" {
+		return fmt.Errorf(ErrorCommon, "client id is required")
+	}
+
+	query := fmt.Sprintf("%s/devices/%s/sync_items/%d", p.plexTV(), url.PathEscape(clientID), id)
+
+	resp, err := p.delete(query, p.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	return nil
+}
+
+// syncContentType returns the sync content type for a media type
+func syncContentType(mediaType MediaType) string {
+	switch mediaType {
+	case MediaTypeArtist, MediaTypeAlbum, MediaTypeTrack:
+		return "audio"
+	case MediaTypePhotoAlbum, MediaTypePhoto, MediaTypePicture:
+		return "photo"
+	default:
+		return "video"
+	}
+}
+
+// intParam formats a positive int as a query parameter value, or returns "" for unset values
+func intParam(v int) string {
+	if v <= 0 {
+		return ""
+	}
+
+	return strconv.Itoa(v)
+}
@@ -0,0 +1,79 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPlex_SyncItems(t *testing.T) {
+	var created url.Values
+	var deleted string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/identity":
+			w.Header().Set("Content-Type", applicationJson)
+			_, _ = w.Write([]byte(`{"MediaContainer":{"machineIdentifier":"abc123"}}`))
+		case r.URL.Path == "/library/metadata/11":
+			w.Header().Set("Content-Type", applicationJson)
+			_, _ = w.Write([]byte(`{"MediaContainer":{"librarySectionUUID":"uuid-3","Metadata":[{"ratingKey":"11","key":"/library/metadata/11","title":"Discovery","type":"album"}]}}`))
+		case r.URL.Path == "/devices/phone-1/sync_items" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`<SyncItems clientIdentifier="phone-1"><SyncItem id="5" title="Discovery" metadataType="album" contentType="audio" machineIdentifier="abc123"><Status state="complete" itemsCount="14" itemsCompleteCount="14" totalSize="120000000"/><Policy scope="all" unwatched="0"/><Location uri="library://uuid-3/item/%2Flibrary%2Fmetadata%2F11"/></SyncItem></SyncItems>`))
+		case r.URL.Path == "/devices/phone-1/sync_items" && r.Method == http.MethodPost:
+			created = r.URL.Query()
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.Path
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token", WithPlexTVURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	items, err := p.GetSyncItems("phone-1")
+	if err != nil {
+		t.Fatalf("GetSyncItems() error = %v", err)
+	}
+
+	if len(items.SyncItem) != 1 || items.SyncItem[0].Status.State != "complete" || items.SyncItem[0].Status.ItemsCount != 14 {
+		t.Fatalf("unexpected sync items: %+v", items)
+	}
+
+	if err := p.CreateSyncItem("phone-1", SyncItemParams{Key: "11", Unwatched: true, MusicBitrate: 192}); err != nil {
+		t.Fatalf("CreateSyncItem() error = %v", err)
+	}
+
+	want := map[string]string{
+		"SyncItem[title]":                       "Discovery",
+		"SyncItem[metadataType]":                "album",
+		"SyncItem[contentType]":                 "audio",
+		"SyncItem[machineIdentifier]":           "abc123",
+		"SyncItem[Location][uri]":               "library://uuid-3/item/%2Flibrary%2Fmetadata%2F11",
+		"SyncItem[Policy][unwatched]":           "1",
+		"SyncItem[MediaSettings][musicBitrate]": "192",
+	}
+
+	for key, value := range want {
+		if got := created.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	if created.Has("SyncItem[MediaSettings][videoQuality]") {
+		t.Errorf("unexpected unset media setting in %v", created)
+	}
+
+	if err := p.DeleteSyncItem("phone-1", 5); err != nil {
+		t.Fatalf("DeleteSyncItem() error = %v", err)
+	}
+
+	if deleted != "/devices/phone-1/sync_items/5" {
+		t.Errorf("deleted %q", deleted)
+	}
+}
@@ -0,0 +1,78 @@
+package plex
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TagField is a tag of an item that can be edited with AddTags and RemoveTags
+type TagField string
+
+// Tag fields of items
+const (
+	TagGenre      TagField = "genre"
+	TagCollection TagField = "collection"
+	TagDirector   TagField = "director"
+	TagWriter     TagField = "writer"
+	TagMood       TagField = "mood"
+	TagLabel      TagField = "label"
+)
+
+// AddTags adds tags to a tag field of an item, e.g. AddTags("1", "42", MediaTypeMovie, TagGenre, "Heist").
+// The field is locked so refreshing the item's metadata keeps the tags.
+func (p *Plex) AddTags(sectionID, ratingKey string, mediaType MediaType, field TagField, tags ...string) error {
+	vals, err := tagEditValues(ratingKey, mediaType, field, tags)
+
+	if err != nil {
+		return err
+	}
+
+	for i, tag := range tags {
+		vals.Set(fmt.Sprintf("%s[%d].tag.tag", field, i), tag)
+	}
+
+	return p.editItem(sectionID, ratingKey, vals)
+}
+
+// RemoveTags removes tags from a tag field of an item, the field is locked like with AddTags
+func (p *Plex) RemoveTags(sectionID, ratingKey string, mediaType MediaType, field TagField, tags ...string) error {
+	vals, err := tagEditValues(ratingKey, mediaType, field, tags)
+
+	if err != nil {
+		return err
+	}
+
+	// plex splits the list on commas, so commas in a tag are escaped
+	escaped := make([]string, len(tags))
+
+	for i, tag := range tags {
+		escaped[i] = url.QueryEscape(tag)
+	}
+
+	vals.Set(string(field)+"[].tag.tag-", strings.Join(escaped, ","))
+
+	return p.editItem(sectionID, ratingKey, vals)
+}
+
+// tagEditValues validates a tag edit and returns the parameters common to adding and removing
+func tagEditValues(ratingKey string, mediaType MediaType, field TagField, tags []string) (url.Values, error) {
+	if ratingKey == "" {
+		return nil, fmt.Errorf(ErrorCommon, ErrorKeyIsRequired)
+	}
+
+	if field == "" || len(tags) == 0 {
+		return nil, fmt.Errorf(ErrorCommon, "a tag field and tags are required")
+	}
+
+	vals := url.Values{}
+
+	if id := mediaType.ID(); id != 0 {
+		vals.Set("type", strconv.Itoa(id))
+	}
+
+	vals.Set(string(field)+".locked", "1")
+
+	return vals, nil
+}
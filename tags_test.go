@@ -0,0 +1,54 @@
+package plex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPlex_AddRemoveTags(t *testing.T) {
+	var got url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/library/sections/1/all" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		got = r.URL.Query()
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if err := p.AddTags("1", "42", MediaTypeMovie, TagGenre, "Heist", "Crime"); err != nil {
+		t.Fatalf("AddTags() error = %v", err)
+	}
+
+	want := url.Values{
+		"id":               {"42"},
+		"type":             {"1"},
+		"genre[0].tag.tag": {"Heist"},
+		"genre[1].tag.tag": {"Crime"},
+		"genre.locked":     {"1"},
+	}
+
+	if got.Encode() != want.Encode() {
+		t.Errorf("AddTags() query = %v, want %v", got, want)
+	}
+
+	if err := p.RemoveTags("1", "42", MediaTypeMovie, TagCollection, "Heat, Extended", "Mann"); err != nil {
+		t.Fatalf("RemoveTags() error = %v", err)
+	}
+
+	if got.Get("collection[].tag.tag-") != "Heat%2C+Extended,Mann" || got.Get("collection.locked") != "1" {
+		t.Errorf("RemoveTags() query = %v", got)
+	}
+
+	if err := p.AddTags("1", "42", MediaTypeMovie, TagMood); err == nil {
+		t.Errorf("expected an error without tags")
+	}
+}
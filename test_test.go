@@ -1,15 +1,20 @@
 package plex
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // Test helper functions
@@ -29,7 +34,7 @@ func newJSONTestServer(code int, body interface{}) (*httptest.Server, *Plex) {
 	}
 
 	httpClient := http.Client{Transport: transport}
-	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
 
 	return server, plex
 }
@@ -48,7 +53,7 @@ func newXMLTestServer(code int, body string) (*httptest.Server, *Plex) {
 	}
 
 	httpClient := http.Client{Transport: transport}
-	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
 
 	return server, plex
 }
@@ -362,6 +367,59 @@ func TestPlex_GetPlaylist(t *testing.T) {
 	}
 }
 
+// Test GetArt and the image url helpers
+func TestPlex_GetArt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library/metadata/123/art/456" {
+			t.Errorf("GetArt() wrong path = %v", r.URL.Path)
+		}
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte("fake image data"))
+	}))
+	defer server.Close()
+
+	plex, err := New(server.URL, "test-token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	resp, err := plex.GetArt("123", "456")
+	if err != nil {
+		t.Fatalf("GetArt() error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		t.Errorf("GetArt() status = %v, want 200", resp.StatusCode)
+	}
+
+	if got, err := plex.ArtURL("123", "456"); err != nil || got != server.URL+"/library/metadata/123/art/456?X-Plex-Token=test-token" {
+		t.Errorf("ArtURL() = %v, %v", got, err)
+	}
+
+	if got, err := plex.ThumbnailURL("123", "456"); err != nil || got != server.URL+"/library/metadata/123/thumb/456?X-Plex-Token=test-token" {
+		t.Errorf("ThumbnailURL() = %v, %v", got, err)
+	}
+
+	if got, err := plex.AuthenticatedURL("/photo?width=100"); err != nil || got != server.URL+"/photo?X-Plex-Token=test-token&width=100" {
+		t.Errorf("AuthenticatedURL() = %v, %v", got, err)
+	}
+
+	// the token of the provider is used when there is one
+	plex.Token = ""
+	plex.TokenProvider = TokenProviderFunc(func(ctx context.Context) (string, error) { return "rotated", nil })
+
+	if got, err := plex.ThumbnailURL("123", "456"); err != nil || got != server.URL+"/library/metadata/123/thumb/456?X-Plex-Token=rotated" {
+		t.Errorf("ThumbnailURL() = %v, %v", got, err)
+	}
+
+	plex.TokenProvider = TokenProviderFunc(func(ctx context.Context) (string, error) { return "", errors.New("vault sealed") })
+
+	if _, err := plex.ArtURL("123", "456"); err == nil {
+		t.Error("expected the token provider error")
+	}
+}
+
 // Test GetThumbnail function
 func TestPlex_GetThumbnail(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -455,42 +513,36 @@ func TestPlex_KillTranscodeSession(t *testing.T) {
 
 // Test GetTranscodeSessions function
 func TestPlex_GetTranscodeSessions(t *testing.T) {
-	transcodeResponse := TranscodeSessionsResponse{
-		Children: []struct {
-			ElementType      string  `json:"_elementType"`
-			AudioChannels    int     `json:"audioChannels"`
-			AudioCodec       string  `json:"audioCodec"`
-			AudioDecision    string  `json:"audioDecision"`
-			SubtitleDecision string  `json:"subtitleDecision"`
-			Container        string  `json:"container"`
-			Context          string  `json:"context"`
-			Duration         int     `json:"duration"`
-			Height           int     `json:"height"`
-			Key              string  `json:"key"`
-			Progress         float64 `json:"progress"`
-			Protocol         string  `json:"protocol"`
-			Remaining        int     `json:"remaining"`
-			Speed            float64 `json:"speed"`
-			Throttled        bool    `json:"throttled"`
-			VideoCodec       string  `json:"videoCodec"`
-			VideoDecision    string  `json:"videoDecision"`
-			Width            int     `json:"width"`
-		}{
-			{Key: "session1", Progress: 50.0, VideoCodec: "h264"},
-		},
-	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/transcode/sessions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
 
-	server, plex := newJSONTestServer(200, transcodeResponse)
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"TranscodeSession":[{"key":"session1","throttled":true,"speed":2.5,"progress":50.0,"videoCodec":"h264","transcodeHwRequested":true,"transcodeHwEncoding":"vaapi","transcodeHwFullPipeline":true,"width":1920,"height":1080}]}}`))
+	}))
 	defer server.Close()
 
+	plex, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
 	result, err := plex.GetTranscodeSessions()
 	if err != nil {
-		t.Errorf("GetTranscodeSessions() error = %v", err)
-		return
+		t.Fatalf("GetTranscodeSessions() error = %v", err)
+	}
+
+	sessions := result.MediaContainer.TranscodeSession
+
+	if len(sessions) != 1 {
+		t.Fatalf("GetTranscodeSessions() session count = %v, want 1", len(sessions))
 	}
 
-	if len(result.Children) != 1 {
-		t.Errorf("GetTranscodeSessions() children count = %v, want 1", len(result.Children))
+	session := sessions[0]
+
+	if session.Key != "session1" || !session.Throttled || session.Speed != 2.5 || session.TranscodeHwEncoding != "vaapi" || !session.TranscodeHwFullPipeline || session.Width != 1920 {
+		t.Errorf("unexpected session %+v", session)
 	}
 }
 
@@ -637,6 +689,67 @@ func TestPlex_GetLibrariesWithCounts(t *testing.T) {
 	}
 }
 
+// Test GetLibrariesWithCountsConcurrently only requests container totals
+func TestPlex_GetLibrariesWithCountsConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	var running, maxRunning int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", applicationJson)
+
+		if r.URL.Path == "/library/sections" {
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Directory":[{"key":"1"},{"key":"2"},{"key":"3"},{"key":"4"}]}}`))
+			return
+		}
+
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if r.URL.Query().Get("X-Plex-Container-Size") != "0" {
+			t.Errorf("expected an empty page request, got %s", r.URL.RawQuery)
+		}
+
+		if r.URL.Path == "/library/sections/4/all" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/library/sections/"), "/all")
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0,"totalSize":` + key + `00}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	result, err := plex.GetLibrariesWithCountsConcurrently(2)
+	if err != nil {
+		t.Fatalf("GetLibrariesWithCountsConcurrently() error = %v", err)
+	}
+
+	var counts []int
+	for _, dir := range result.MediaContainer.Directory {
+		counts = append(counts, dir.Count)
+	}
+
+	if !reflect.DeepEqual(counts, []int{100, 200, 300, -1}) {
+		t.Errorf("counts = %v, want [100 200 300 -1]", counts)
+	}
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxRunning)
+	}
+}
+
 // Test GetLibrariesWithCounts error handling
 // Duplicate TestPlex_GetLibrariesWithCounts_ErrorHandling removed to fix redeclaration error.
 
@@ -895,17 +1008,43 @@ func TestPlex_DeleteMediaByID(t *testing.T) {
 	}
 }
 
+// Test DeleteMediaPart function
+func TestPlex_DeleteMediaPart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("DeleteMediaPart() method = %v, want DELETE", r.Method)
+		}
+
+		if r.URL.Path != "/library/metadata/123/media/456" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	if err := plex.DeleteMediaPart("123", "456"); err != nil {
+		t.Errorf("DeleteMediaPart() error = %v", err)
+	}
+
+	if err := plex.DeleteMediaPart("123", "789"); err == nil {
+		t.Error("DeleteMediaPart() expected an error for an unknown media")
+	}
+
+	if err := plex.DeleteMediaPart("123", ""); err == nil {
+		t.Error("DeleteMediaPart() expected an error for an empty media id")
+	}
+}
+
 // Test GetLibraryLabels function
 func TestPlex_GetLibraryLabels(t *testing.T) {
 	labelsResponse := LibraryLabels{
 		ElementType: "Directory",
 		Title1:      "Labels",
-		Children: []struct {
-			ElementType string `json:"_elementType"`
-			FastKey     string `json:"fastKey"`
-			Key         string `json:"key"`
-			Title       string `json:"title"`
-		}{
+		Children: []LibraryLabel{
 			{Title: "Action", Key: "action"},
 			{Title: "Comedy", Key: "comedy"},
 		},
@@ -1122,12 +1261,7 @@ func TestPlex_SignIn(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override global plexURL for this test
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
-	plex, err := SignIn("testuser", "testpass")
+	plex, err := SignIn("testuser", "testpass", WithPlexTVURL(server.URL))
 	if err != nil {
 		t.Errorf("SignIn() error = %v", err)
 	}
@@ -1143,8 +1277,7 @@ func TestPlex_SignIn(t *testing.T) {
 	}))
 	defer serverError.Close()
 
-	plexURL = serverError.URL
-	_, err = SignIn("baduser", "badpass")
+	_, err = SignIn("baduser", "badpass", WithPlexTVURL(serverError.URL))
 	if err == nil {
 		t.Errorf("SignIn() expected error for unauthorized")
 	}
@@ -1156,8 +1289,7 @@ func TestPlex_SignIn(t *testing.T) {
 	}))
 	defer serverBadJSON.Close()
 
-	plexURL = serverBadJSON.URL
-	_, err = SignIn("user", "pass")
+	_, err = SignIn("user", "pass", WithPlexTVURL(serverBadJSON.URL))
 	if err == nil {
 		t.Errorf("SignIn() expected error for invalid JSON")
 	}
@@ -1175,10 +1307,6 @@ func TestPlex_Test(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	transport := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(server.URL)
@@ -1187,7 +1315,7 @@ func TestPlex_Test(t *testing.T) {
 
 	httpClient := http.Client{Transport: transport}
 	headers := defaultHeaders()
-	plex := &Plex{URL: server.URL, Token: "test-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: httpClient, Headers: headers}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: httpClient, Headers: headers}
 
 	result, err := plex.Test()
 	if err != nil {
@@ -1204,14 +1332,13 @@ func TestPlex_Test(t *testing.T) {
 	}))
 	defer serverUnauth.Close()
 
-	plexURL = serverUnauth.URL
 	transportUnauth := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(serverUnauth.URL)
 		},
 	}
 
-	plexUnauth := &Plex{URL: serverUnauth.URL, Token: "invalid-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: http.Client{Transport: transportUnauth}, Headers: headers}
+	plexUnauth := &Plex{URL: serverUnauth.URL, PlexTVURL: serverUnauth.URL, Token: "invalid-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: http.Client{Transport: transportUnauth}, Headers: headers}
 
 	_, err = plexUnauth.Test()
 	if err == nil {
@@ -1225,14 +1352,13 @@ func TestPlex_Test(t *testing.T) {
 	}))
 	defer serverError.Close()
 
-	plexURL = serverError.URL
 	transportError := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(serverError.URL)
 		},
 	}
 
-	plexError := &Plex{URL: serverError.URL, Token: "test-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: http.Client{Transport: transportError}, Headers: headers}
+	plexError := &Plex{URL: serverError.URL, PlexTVURL: serverError.URL, Token: "test-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: http.Client{Transport: transportError}, Headers: headers}
 
 	_, err = plexError.Test()
 	if err == nil {
@@ -1242,12 +1368,18 @@ func TestPlex_Test(t *testing.T) {
 
 // Test GetPlexTokens function
 func TestPlex_GetPlexTokens(t *testing.T) {
-	devicesResponse := DevicesResponse{
-		ID:         1,
-		LastSeenAt: "2023-01-02T00:00:00Z",
-		Name:       "Test Device",
-		Product:    "Plex Media Server",
-		Version:    "1.0.0",
+	devicesResponse := []DevicesResponse{
+		{
+			ID:               1,
+			LastSeenAt:       "2023-01-02T00:00:00Z",
+			Name:             "Test Device",
+			Product:          "Plex Media Server",
+			Version:          "1.0.0",
+			ClientIdentifier: "abc",
+			Token:            "device-token",
+			Connections:      []Connection{{URI: "http://192.168.1.2:32400"}},
+		},
+		{ID: 2, Name: "Phone", Product: "Plex for iOS"},
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1260,10 +1392,6 @@ func TestPlex_GetPlexTokens(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	transport := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(server.URL)
@@ -1272,15 +1400,19 @@ func TestPlex_GetPlexTokens(t *testing.T) {
 
 	httpClient := http.Client{Transport: transport}
 	headers := defaultHeaders()
-	plex := &Plex{URL: server.URL, Token: "test-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: httpClient, Headers: headers}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: httpClient, Headers: headers}
 
 	result, err := plex.GetPlexTokens("test-token")
 	if err != nil {
 		t.Errorf("GetPlexTokens() error = %v", err)
 	}
 
-	if result.Name != "Test Device" {
-		t.Errorf("GetPlexTokens() device name = %v, want Test Device", result.Name)
+	if len(result) != 2 {
+		t.Fatalf("GetPlexTokens() device count = %v, want 2", len(result))
+	}
+
+	if result[0].Name != "Test Device" || result[0].Token != "device-token" || result[0].ClientIdentifier != "abc" || len(result[0].Connections) != 1 {
+		t.Errorf("GetPlexTokens() unexpected device %+v", result[0])
 	}
 
 	// Test unauthorized response
@@ -1290,14 +1422,13 @@ func TestPlex_GetPlexTokens(t *testing.T) {
 	}))
 	defer serverUnauth.Close()
 
-	plexURL = serverUnauth.URL
 	transportUnauth := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(serverUnauth.URL)
 		},
 	}
 
-	plexUnauth := &Plex{URL: serverUnauth.URL, Token: "invalid-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: http.Client{Transport: transportUnauth}, Headers: headers}
+	plexUnauth := &Plex{URL: serverUnauth.URL, PlexTVURL: serverUnauth.URL, Token: "invalid-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: http.Client{Transport: transportUnauth}, Headers: headers}
 
 	_, err = plexUnauth.GetPlexTokens("invalid-token")
 	if err == nil {
@@ -1317,10 +1448,6 @@ func TestPlex_DeletePlexToken(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	transport := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(server.URL)
@@ -1329,7 +1456,7 @@ func TestPlex_DeletePlexToken(t *testing.T) {
 
 	httpClient := http.Client{Transport: transport}
 	headers := defaultHeaders()
-	plex := &Plex{URL: server.URL, Token: "test-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: httpClient, Headers: headers}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: httpClient, Headers: headers}
 
 	result, err := plex.DeletePlexToken("test-token")
 	if err != nil {
@@ -1347,14 +1474,13 @@ func TestPlex_DeletePlexToken(t *testing.T) {
 	}))
 	defer serverUnauth.Close()
 
-	plexURL = serverUnauth.URL
 	transportUnauth := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(serverUnauth.URL)
 		},
 	}
 
-	plexUnauth := &Plex{URL: serverUnauth.URL, Token: "invalid-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: http.Client{Transport: transportUnauth}, Headers: headers}
+	plexUnauth := &Plex{URL: serverUnauth.URL, PlexTVURL: serverUnauth.URL, Token: "invalid-token", ClientIdentifier: headers.ClientIdentifier, HTTPClient: http.Client{Transport: transportUnauth}, Headers: headers}
 
 	_, err = plexUnauth.DeletePlexToken("test-token")
 	if err == nil {
@@ -1587,12 +1713,7 @@ func TestPlex_RemoveInvitedFriend_Coverage(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			plex := &Plex{Headers: defaultHeaders()}
+			plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
 			result, err := plex.RemoveInvitedFriend(tt.inviteID, tt.isFriend, tt.isServer, tt.isHome)
 
 			if tt.expectError {
@@ -1630,7 +1751,7 @@ func TestPlex_InviteFriend(t *testing.T) {
 				Label:           "Movies",
 			},
 			statusCode:  http.StatusCreated,
-			response:    inviteFriendResponse{ID: 123, OwnerID: 456},
+			response:    SharedServer{ID: 123, OwnerID: 456},
 			expectError: false,
 		},
 		{
@@ -1642,7 +1763,7 @@ func TestPlex_InviteFriend(t *testing.T) {
 				Label:           "",
 			},
 			statusCode:  http.StatusCreated,
-			response:    inviteFriendResponse{ID: 789, OwnerID: 456},
+			response:    SharedServer{ID: 789, OwnerID: 456},
 			expectError: false,
 		},
 		{
@@ -1676,13 +1797,8 @@ func TestPlex_InviteFriend(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override plexURL for testing
-			originalURL := plexURL
-			plexURL = server.URL
-			defer func() { plexURL = originalURL }()
-
-			plex := &Plex{Headers: defaultHeaders()}
-			err := plex.InviteFriend(tt.params)
+			plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
+			invite, err := plex.InviteFriend(tt.params)
 
 			if tt.expectError {
 				if err == nil {
@@ -1695,12 +1811,107 @@ func TestPlex_InviteFriend(t *testing.T) {
 				if err != nil {
 					t.Errorf("Unexpected error: %v", err)
 				}
+
+				if want := tt.response.(SharedServer).ID; invite.ID != want {
+					t.Errorf("Expected invite %d, got %d", want, invite.ID)
+				}
 			}
 		})
 	}
 }
 
-// Test inviteFriendResponse UnmarshalJSON - currently at 0% coverage
+func TestPlex_InviteFriend_Settings(t *testing.T) {
+	var got inviteFriendBody
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("unexpected body: %v", err)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"42","invitedEmail":"friend@example.com","invited":{"id":7,"status":"pending"}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
+
+	invite, err := plex.InviteFriend(InviteFriendParams{
+		UsernameOrEmail: "friend@example.com",
+		MachineID:       "abc",
+		Label:           "kids",
+		LibraryIDs:      []int{1},
+		AllowSync:       true,
+		AllowChannels:   true,
+		FilterMovies:    "contentRating=G",
+		FilterPhotos:    "label=family",
+	})
+	if err != nil {
+		t.Fatalf("InviteFriend() error = %v", err)
+	}
+
+	want := inviteFriendSettings{
+		AllowCameraUpload: "0",
+		AllowChannels:     "1",
+		AllowSync:         "1",
+		FilterMovies:      "contentRating=G",
+		FilterPhotos:      "label=family",
+		FilterTelevision:  "label=kids",
+	}
+
+	if got.Settings != want {
+		t.Errorf("settings = %+v, want %+v", got.Settings, want)
+	}
+
+	if invite.ID != 42 || invite.Invited.Status != "pending" {
+		t.Errorf("unexpected invite %+v", invite)
+	}
+}
+
+func TestSharedServer_PendingInvite(t *testing.T) {
+	var cancelled string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			cancelled = r.URL.Path + "?" + r.URL.RawQuery
+			_, _ = w.Write([]byte(`<Response code="0" status="Invite deleted"/>`))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":42,"name":"nas","invitedId":null,"invitedEmail":"new@example.com","invitedAt":1700000000,"accepted":false,"numLibraries":2,"invited":{"status":"pending"}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{PlexTVURL: server.URL, Headers: defaultHeaders()}
+
+	shared, err := plex.InviteFriend(InviteFriendParams{UsernameOrEmail: "new@example.com", MachineID: "abc", LibraryIDs: []int{1, 2}})
+	if err != nil {
+		t.Fatalf("InviteFriend() error = %v", err)
+	}
+
+	invite, ok := shared.PendingInvite()
+	if !ok {
+		t.Fatalf("expected a pending invite for %+v", shared)
+	}
+
+	if invite.ID != "new@example.com" || !invite.IsServer || invite.Server.Name != "nas" || invite.Server.NumLibraries != "2" || invite.CreatedAtTime().Unix() != 1700000000 {
+		t.Errorf("unexpected invite %+v", invite)
+	}
+
+	if ok, err := plex.CancelInvite(invite); err != nil || !ok {
+		t.Fatalf("CancelInvite() = %v, %v", ok, err)
+	}
+
+	if cancelled != "/api/invites/requested/new@example.com?friend=0&home=0&server=1" {
+		t.Errorf("unexpected cancel request %q", cancelled)
+	}
+
+	if _, ok := (SharedServer{Accepted: true}).PendingInvite(); ok {
+		t.Errorf("expected no pending invite once accepted")
+	}
+}
+
+// Test SharedServer UnmarshalJSON - currently at 0% coverage
 func TestInviteFriendResponse_UnmarshalJSON(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1744,7 +1955,7 @@ func TestInviteFriendResponse_UnmarshalJSON(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var resp inviteFriendResponse
+			var resp SharedServer
 			err := json.Unmarshal([]byte(tt.json), &resp)
 
 			if tt.expectError {
@@ -1773,11 +1984,6 @@ func TestPlex_GetFriends(t *testing.T) {
 	server, plex := newXMLTestServer(200, xmlResponse)
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	friends, err := plex.GetFriends()
 	if err != nil {
 		t.Errorf("GetFriends() error = %v", err)
@@ -1792,7 +1998,6 @@ func TestPlex_GetFriends(t *testing.T) {
 	server401, plex401 := newXMLTestServer(401, "")
 	defer server401.Close()
 
-	plexURL = server401.URL
 	_, err = plex401.GetFriends()
 	if err == nil {
 		t.Errorf("GetFriends() expected error for 401")
@@ -1817,11 +2022,6 @@ func TestPlex_RemoveFriend(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	transport := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(server.URL)
@@ -1829,7 +2029,7 @@ func TestPlex_RemoveFriend(t *testing.T) {
 	}
 
 	httpClient := http.Client{Transport: transport}
-	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
 
 	result, err := plex.RemoveFriend("123")
 	if err != nil {
@@ -1858,9 +2058,8 @@ func TestPlex_RemoveFriend(t *testing.T) {
 	}
 
 	httpClientError := http.Client{Transport: transportError}
-	plexError := &Plex{URL: serverError.URL, Token: "test-token", HTTPClient: httpClientError, Headers: defaultHeaders()}
+	plexError := &Plex{URL: serverError.URL, PlexTVURL: serverError.URL, Token: "test-token", HTTPClient: httpClientError, Headers: defaultHeaders()}
 
-	plexURL = serverError.URL
 	result, err = plexError.RemoveFriend("123")
 	if err != nil {
 		t.Errorf("RemoveFriend() error = %v", err)
@@ -1902,11 +2101,6 @@ func TestPlex_UpdateFriendAccess(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	transport := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(server.URL)
@@ -1914,7 +2108,7 @@ func TestPlex_UpdateFriendAccess(t *testing.T) {
 	}
 
 	httpClient := http.Client{Transport: transport}
-	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
 
 	params := UpdateFriendParams{
 		AllowSync:         "1",
@@ -1955,9 +2149,8 @@ func TestPlex_UpdateFriendAccess(t *testing.T) {
 	}
 
 	httpClient500 := http.Client{Transport: transport500}
-	plex500 := &Plex{URL: server500.URL, Token: "test-token", HTTPClient: httpClient500, Headers: defaultHeaders()}
+	plex500 := &Plex{URL: server500.URL, PlexTVURL: server500.URL, Token: "test-token", HTTPClient: httpClient500, Headers: defaultHeaders()}
 
-	plexURL = server500.URL
 	_, err = plex500.UpdateFriendAccess("123", params)
 	if err == nil {
 		t.Errorf("UpdateFriendAccess() expected error for 500")
@@ -1977,11 +2170,6 @@ func TestPlex_RemoveFriendAccessToLibrary(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	transport := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(server.URL)
@@ -1989,7 +2177,7 @@ func TestPlex_RemoveFriendAccessToLibrary(t *testing.T) {
 	}
 
 	httpClient := http.Client{Transport: transport}
-	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
 
 	result, err := plex.RemoveFriendAccessToLibrary("user123", "machine123", "server456")
 	if err != nil {
@@ -2014,11 +2202,6 @@ func TestPlex_GetInvitedFriends(t *testing.T) {
 	server, plex := newXMLTestServer(200, xmlResponse)
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	invites, err := plex.GetInvitedFriends()
 	if err != nil {
 		t.Errorf("GetInvitedFriends() error = %v", err)
@@ -2033,7 +2216,6 @@ func TestPlex_GetInvitedFriends(t *testing.T) {
 	server401, plex401 := newXMLTestServer(401, "")
 	defer server401.Close()
 
-	plexURL = server401.URL
 	_, err = plex401.GetInvitedFriends()
 	if err == nil {
 		t.Errorf("GetInvitedFriends() expected error for 401")
@@ -2061,11 +2243,6 @@ func TestPlex_CheckUsernameOrEmail(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	transport := &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
 			return url.Parse(server.URL)
@@ -2073,7 +2250,7 @@ func TestPlex_CheckUsernameOrEmail(t *testing.T) {
 	}
 
 	httpClient := http.Client{Transport: transport}
-	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
+	plex := &Plex{URL: server.URL, PlexTVURL: server.URL, Token: "test-token", HTTPClient: httpClient, Headers: defaultHeaders()}
 
 	result, err := plex.CheckUsernameOrEmail("test@example.com")
 	if err != nil {
@@ -2102,9 +2279,8 @@ func TestPlex_CheckUsernameOrEmail(t *testing.T) {
 	}
 
 	httpClientInvalid := http.Client{Transport: transportInvalid}
-	plexInvalid := &Plex{URL: serverInvalid.URL, Token: "test-token", HTTPClient: httpClientInvalid, Headers: defaultHeaders()}
+	plexInvalid := &Plex{URL: serverInvalid.URL, PlexTVURL: serverInvalid.URL, Token: "test-token", HTTPClient: httpClientInvalid, Headers: defaultHeaders()}
 
-	plexURL = serverInvalid.URL
 	result, err = plexInvalid.CheckUsernameOrEmail("invalid@example.com")
 	if err != nil {
 		t.Errorf("CheckUsernameOrEmail() error = %v", err)
@@ -2180,11 +2356,6 @@ func TestPlex_GetDevices(t *testing.T) {
 	server, plex := newXMLTestServer(200, xmlResponse)
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	devices, err := plex.GetDevices()
 	if err != nil {
 		t.Errorf("GetDevices() error = %v", err)
@@ -2199,7 +2370,6 @@ func TestPlex_GetDevices(t *testing.T) {
 	server500, plex500 := newXMLTestServer(500, "")
 	defer server500.Close()
 
-	plexURL = server500.URL
 	_, err = plex500.GetDevices()
 	if err == nil {
 		t.Errorf("GetDevices() expected error for 500")
@@ -2218,11 +2388,6 @@ func TestPlex_GetServers(t *testing.T) {
 	server, plex := newXMLTestServer(200, xmlResponse)
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	servers, err := plex.GetServers()
 	if err != nil {
 		t.Errorf("GetServers() error = %v", err)
@@ -2251,11 +2416,6 @@ func TestPlex_GetSections(t *testing.T) {
 	server, plex := newXMLTestServer(200, xmlResponse)
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	sections, err := plex.GetSections("target123")
 	if err != nil {
 		t.Errorf("GetSections() error = %v", err)
@@ -2288,11 +2448,6 @@ func TestPlex_GetServersInfo_XML(t *testing.T) {
 	server, plex := newXMLTestServer(200, xmlResponse)
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	info, err := plex.GetServersInfo()
 	if err != nil {
 		t.Errorf("GetServersInfo() error = %v", err)
@@ -2319,11 +2474,6 @@ func TestPlex_GetMachineID(t *testing.T) {
 	server, plex := newXMLTestServer(200, xmlResponse)
 	defer server.Close()
 
-	// Override plexURL for testing
-	originalPlexURL := plexURL
-	plexURL = server.URL
-	defer func() { plexURL = originalPlexURL }()
-
 	plex.Token = "test-token"
 
 	machineID, err := plex.GetMachineID()
@@ -2451,3 +2601,441 @@ func TestTimelineEventHandler(t *testing.T) {
 		t.Error("Timeline event handler was not set")
 	}
 }
+
+// Test UpdateLibrary function
+func TestPlex_UpdateLibrary(t *testing.T) {
+	var got url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/library/sections":
+			w.Header().Set("Content-Type", applicationJson)
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Directory":[{"key":"3","agent":"tv.plex.agents.movie","title":"Movies"}]}}`))
+		case r.Method == "PUT" && r.URL.Path == "/library/sections/3":
+			got = r.URL.Query()
+			w.WriteHeader(200)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	err := plex.UpdateLibrary("3", UpdateLibraryParams{
+		Name:  "Films",
+		Prefs: map[string]string{"enableBIFGeneration": "0"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateLibrary() error = %v", err)
+	}
+
+	if got.Get("name") != "Films" || got.Get("prefs[enableBIFGeneration]") != "0" {
+		t.Errorf("UpdateLibrary() query = %v", got)
+	}
+
+	if got.Get("agent") != "tv.plex.agents.movie" {
+		t.Errorf("UpdateLibrary() should keep the current agent, got %q", got.Get("agent"))
+	}
+
+	if err := plex.UpdateLibrary("99", UpdateLibraryParams{Name: "Missing"}); err == nil {
+		t.Error("UpdateLibrary() expected error for unknown section")
+	}
+}
+
+// Test AddLibraryLocation and RemoveLibraryLocation functions
+func TestPlex_LibraryLocations(t *testing.T) {
+	var got []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Header().Set("Content-Type", applicationJson)
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Directory":[{"key":"3","agent":"tv.plex.agents.movie","Location":[{"id":1,"path":"/mnt/old"},{"id":2,"path":"/mnt/movies"}]}]}}`))
+		case "PUT":
+			got = r.URL.Query()["location"]
+			w.WriteHeader(200)
+		}
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	if err := plex.AddLibraryLocation("3", "/mnt/new"); err != nil {
+		t.Fatalf("AddLibraryLocation() error = %v", err)
+	}
+
+	if strings.Join(got, ",") != "/mnt/old,/mnt/movies,/mnt/new" {
+		t.Errorf("AddLibraryLocation() locations = %v", got)
+	}
+
+	if err := plex.RemoveLibraryLocation("3", "/mnt/old"); err != nil {
+		t.Fatalf("RemoveLibraryLocation() error = %v", err)
+	}
+
+	if strings.Join(got, ",") != "/mnt/movies" {
+		t.Errorf("RemoveLibraryLocation() locations = %v", got)
+	}
+
+	if err := plex.AddLibraryLocation("3", "/mnt/movies"); err == nil {
+		t.Error("AddLibraryLocation() expected error for an existing location")
+	}
+
+	if err := plex.RemoveLibraryLocation("3", "/mnt/missing"); err == nil {
+		t.Error("RemoveLibraryLocation() expected error for an unknown location")
+	}
+}
+
+// Test GetLibraryPrefs and SetLibraryPrefs functions
+func TestPlex_LibraryPrefs(t *testing.T) {
+	var got url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library/sections/2/prefs" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		if r.Method == "PUT" {
+			got = r.URL.Query()
+			w.WriteHeader(200)
+			return
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":2,"Setting":[
+			{"id":"showOrdering","type":"text","default":"","value":"aired","enumValues":"aired:Aired|dvd:DVD"},
+			{"id":"flattenSeasons","type":"int","default":0,"value":1}
+		]}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	prefs, err := plex.GetLibraryPrefs("2")
+	if err != nil {
+		t.Fatalf("GetLibraryPrefs() error = %v", err)
+	}
+
+	ordering, ok := prefs.MediaContainer.Get(LibraryPrefShowOrdering)
+	if !ok || ordering.Value.String() != "aired" {
+		t.Errorf("GetLibraryPrefs() showOrdering = %+v", ordering)
+	}
+
+	if flatten, _ := prefs.MediaContainer.Get(LibraryPrefFlattenSeasons); flatten.Value.String() != "1" {
+		t.Errorf("GetLibraryPrefs() flattenSeasons = %+v", flatten)
+	}
+
+	if err := plex.SetLibraryPrefs("2", map[string]string{LibraryPrefShowOrdering: "dvd"}); err != nil {
+		t.Fatalf("SetLibraryPrefs() error = %v", err)
+	}
+
+	if got.Get("showOrdering") != "dvd" {
+		t.Errorf("SetLibraryPrefs() query = %v", got)
+	}
+}
+
+// Test GetLibraryVisibility and SetLibraryVisibility functions
+func TestPlex_LibraryVisibility(t *testing.T) {
+	var got string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			got = r.URL.Query().Get("hidden")
+			w.WriteHeader(200)
+			return
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":1,"Setting":[{"id":"hidden","type":"int","default":0,"value":1}]}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	visibility, err := plex.GetLibraryVisibility("1")
+	if err != nil {
+		t.Fatalf("GetLibraryVisibility() error = %v", err)
+	}
+
+	if visibility != LibraryHiddenFromHome {
+		t.Errorf("GetLibraryVisibility() = %v, want %v", visibility, LibraryHiddenFromHome)
+	}
+
+	if err := plex.SetLibraryVisibility("1", LibraryHiddenFromHomeAndSearch); err != nil {
+		t.Fatalf("SetLibraryVisibility() error = %v", err)
+	}
+
+	if got != "2" {
+		t.Errorf("SetLibraryVisibility() hidden = %q, want 2", got)
+	}
+
+	if err := plex.SetLibraryVisibility("1", LibraryVisibility(5)); err == nil {
+		t.Error("SetLibraryVisibility() expected error for invalid visibility")
+	}
+}
+
+// Test GetAllEpisodes function
+func TestPlex_GetAllEpisodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library/metadata/42/allLeaves" {
+			t.Errorf("GetAllEpisodes() path = %v", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":3,"Metadata":[
+			{"ratingKey":"1","parentIndex":1,"index":1},
+			{"ratingKey":"2","parentIndex":1,"index":2},
+			{"ratingKey":"3","parentIndex":2,"index":1}
+		]}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	episodes, err := plex.GetAllEpisodes("42")
+	if err != nil {
+		t.Fatalf("GetAllEpisodes() error = %v", err)
+	}
+
+	if len(episodes.MediaContainer.Metadata) != 3 {
+		t.Errorf("GetAllEpisodes() episode count = %d, want 3", len(episodes.MediaContainer.Metadata))
+	}
+
+	if _, err := plex.GetAllEpisodes(""); err == nil {
+		t.Error("GetAllEpisodes() expected error for empty key")
+	}
+}
+
+// Test GetSeasons function
+func TestPlex_GetSeasons(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library/metadata/42/children" {
+			t.Errorf("GetSeasons() path = %v", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":2,"Metadata":[
+			{"ratingKey":"100","title":"Season 1","index":1,"leafCount":10,"viewedLeafCount":10},
+			{"ratingKey":"101","title":"Season 2","index":2,"leafCount":8,"viewedLeafCount":3}
+		]}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	seasons, err := plex.GetSeasons("42")
+	if err != nil {
+		t.Fatalf("GetSeasons() error = %v", err)
+	}
+
+	if len(seasons.MediaContainer.Metadata) != 2 {
+		t.Fatalf("GetSeasons() season count = %d, want 2", len(seasons.MediaContainer.Metadata))
+	}
+
+	first, second := seasons.MediaContainer.Metadata[0], seasons.MediaContainer.Metadata[1]
+
+	if !first.Watched() || second.Watched() {
+		t.Errorf("GetSeasons() watched = %v, %v", first.Watched(), second.Watched())
+	}
+
+	if second.Index != 2 || second.UnwatchedLeafCount() != 5 {
+		t.Errorf("GetSeasons() second season = %+v", second)
+	}
+}
+
+// Test GetNextUnwatched function
+func TestPlex_GetNextUnwatched(t *testing.T) {
+	tests := []struct {
+		name     string
+		episodes string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "after last watched",
+			episodes: `[{"ratingKey":"3","parentIndex":2,"index":1},{"ratingKey":"1","parentIndex":1,"index":1,"viewCount":1},{"ratingKey":"2","parentIndex":1,"index":2,"viewCount":1}]`,
+			want:     "3",
+		},
+		{
+			name:     "in progress",
+			episodes: `[{"ratingKey":"1","parentIndex":1,"index":1,"viewCount":1},{"ratingKey":"2","parentIndex":1,"index":2,"viewOffset":60000,"lastViewedAt":100},{"ratingKey":"3","parentIndex":1,"index":3}]`,
+			want:     "2",
+		},
+		{
+			name:     "skips specials and earlier gaps",
+			episodes: `[{"ratingKey":"0","parentIndex":0,"index":1},{"ratingKey":"1","parentIndex":1,"index":1},{"ratingKey":"2","parentIndex":1,"index":2,"viewCount":2},{"ratingKey":"3","parentIndex":1,"index":3}]`,
+			want:     "3",
+		},
+		{
+			name:     "nothing watched",
+			episodes: `[{"ratingKey":"2","parentIndex":1,"index":2},{"ratingKey":"1","parentIndex":1,"index":1}]`,
+			want:     "1",
+		},
+		{
+			name:     "fully watched",
+			episodes: `[{"ratingKey":"1","parentIndex":1,"index":1,"viewCount":1}]`,
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", applicationJson)
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":` + test.episodes + `}}`))
+		}))
+
+		plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+		episode, err := plex.GetNextUnwatched("42")
+
+		server.Close()
+
+		if test.wantErr {
+			if err == nil || err.Error() != ErrorNoUnwatched {
+				t.Errorf("%s: GetNextUnwatched() error = %v, want %s", test.name, err, ErrorNoUnwatched)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: GetNextUnwatched() error = %v", test.name, err)
+			continue
+		}
+
+		if episode.RatingKey != test.want {
+			t.Errorf("%s: GetNextUnwatched() = %s, want %s", test.name, episode.RatingKey, test.want)
+		}
+	}
+}
+
+// Test GetSectionOnDeck function
+func TestPlex_GetSectionOnDeck(t *testing.T) {
+	var gotPath, gotStart, gotSize string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotStart = r.URL.Query().Get("X-Plex-Container-Start")
+		gotSize = r.URL.Query().Get("X-Plex-Container-Size")
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"offset":10,"size":1,"totalSize":11,"Metadata":[{"ratingKey":"5","type":"episode"}]}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	result, err := plex.GetSectionOnDeck("2", 10, 5)
+	if err != nil {
+		t.Fatalf("GetSectionOnDeck() error = %v", err)
+	}
+
+	if gotPath != "/library/sections/2/onDeck" || gotStart != "10" || gotSize != "5" {
+		t.Errorf("unexpected request path=%s start=%s size=%s", gotPath, gotStart, gotSize)
+	}
+
+	if result.MediaContainer.TotalSize != 11 || result.MediaContainer.Offset != 10 || len(result.MediaContainer.Metadata) != 1 {
+		t.Errorf("unexpected result: %+v", result.MediaContainer)
+	}
+
+	if _, err := plex.GetSectionOnDeck("2", 0, 0); err != nil {
+		t.Fatalf("GetSectionOnDeck() error = %v", err)
+	}
+
+	if gotSize != "" {
+		t.Errorf("expected no paging parameters without a size, got size=%s", gotSize)
+	}
+
+	if _, err := plex.GetSectionOnDeck("", 0, 0); err == nil || err.Error() != fmt.Sprintf(ErrorCommon, ErrorKeyIsRequired) {
+		t.Errorf("expected %s, got %v", fmt.Sprintf(ErrorCommon, ErrorKeyIsRequired), err)
+	}
+}
+
+// Test GetMetadataBatch function
+func TestPlex_GetMetadataBatch(t *testing.T) {
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := strings.TrimPrefix(r.URL.Path, "/library/metadata/")
+		requested = append(requested, keys)
+
+		var items []string
+		for _, key := range strings.Split(keys, ",") {
+			items = append(items, `{"ratingKey":"`+key+`"}`)
+		}
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[` + strings.Join(items, ",") + `]}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	items, err := plex.GetMetadataBatch([]string{"1", "2", "3", "4", "5"}, 2)
+	if err != nil {
+		t.Fatalf("GetMetadataBatch() error = %v", err)
+	}
+
+	if want := []string{"1,2", "3,4", "5"}; !reflect.DeepEqual(requested, want) {
+		t.Errorf("requested %v, want %v", requested, want)
+	}
+
+	if len(items) != 5 || items[4].RatingKey != "5" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+
+	if _, err := plex.GetMetadataBatch([]string{"1", ""}, 0); err == nil {
+		t.Error("expected an error for an empty key")
+	}
+}
+
+// Test GetMetadataWithOptions function
+func TestPlex_GetMetadataWithOptions(t *testing.T) {
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[{"ratingKey":"7",
+			"Guid":[{"id":"imdb://tt0111161"}],
+			"Marker":[{"id":1,"type":"intro","startTimeOffset":1000,"endTimeOffset":60000}],
+			"Chapter":[{"id":2,"index":1,"tag":"Opening","startTimeOffset":0,"endTimeOffset":300000}],
+			"Extras":{"size":1,"Metadata":[{"ratingKey":"8","type":"clip"}]},
+			"Media":[{"Part":[{"file":"/movies/a.mkv","exists":true,"accessible":true}]}]}]}}`))
+	}))
+	defer server.Close()
+
+	plex := &Plex{URL: server.URL, Token: "test-token", HTTPClient: http.Client{}, Headers: defaultHeaders()}
+
+	result, err := plex.GetMetadataWithOptions("7", MetadataOptions{IncludeGuids: true, IncludeMarkers: true, CheckFiles: true})
+	if err != nil {
+		t.Fatalf("GetMetadataWithOptions() error = %v", err)
+	}
+
+	for _, name := range []string{"includeGuids", "includeMarkers", "checkFiles"} {
+		if gotQuery.Get(name) != "1" {
+			t.Errorf("expected %s=1 in %v", name, gotQuery)
+		}
+	}
+
+	if gotQuery.Has("includeChapters") {
+		t.Errorf("unexpected includeChapters in %v", gotQuery)
+	}
+
+	item := result.MediaContainer.Metadata[0]
+
+	if len(item.Markers) != 1 || item.Markers[0].Type != "intro" || item.Markers[0].EndTimeOffset != 60000 {
+		t.Errorf("unexpected markers: %+v", item.Markers)
+	}
+
+	if len(item.Chapters) != 1 || item.Extras.Size != 1 || len(item.AltGUIDs) != 1 {
+		t.Errorf("unexpected metadata: %+v", item)
+	}
+
+	if part := item.Media[0].Part[0]; !part.Exists || !part.Accessible {
+		t.Errorf("expected part to exist and be accessible: %+v", part)
+	}
+
+	if _, err := plex.GetMetadataWithOptions("", MetadataOptions{}); err == nil {
+		t.Error("expected an error for an empty key")
+	}
+}
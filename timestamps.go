@@ -0,0 +1,80 @@
+package plex
+
+import (
+	"strconv"
+	"time"
+)
+
+// unixTime converts a unix timestamp in seconds as returned by plex to a time.Time.
+// Zero is returned as the zero time so callers can use IsZero for unset fields.
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(sec, 0)
+}
+
+// unixTimeString is unixTime for timestamps that plex encodes as strings
+func unixTimeString(sec string) time.Time {
+	v, err := strconv.ParseInt(sec, 10, 64)
+
+	if err != nil {
+		return time.Time{}
+	}
+
+	return unixTime(v)
+}
+
+// AddedAtTime returns AddedAt as a time.Time
+func (m Metadata) AddedAtTime() time.Time { return unixTime(int64(m.AddedAt)) }
+
+// UpdatedAtTime returns UpdatedAt as a time.Time
+func (m Metadata) UpdatedAtTime() time.Time { return unixTime(int64(m.UpdatedAt)) }
+
+// LastViewedAtTime returns LastViewedAt as a time.Time, the zero time if it was never watched
+func (m Metadata) LastViewedAtTime() time.Time { return unixTime(int64(m.LastViewedAt)) }
+
+// OriginallyAvailableAtTime parses the release date of the media, the zero time if it is unknown
+func (m Metadata) OriginallyAvailableAtTime() time.Time {
+	t, err := time.Parse("2006-01-02", m.OriginallyAvailableAt)
+
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// CreatedAtTime returns CreatedAt as a time.Time
+func (d Directory) CreatedAtTime() time.Time { return unixTime(int64(d.CreatedAt)) }
+
+// UpdatedAtTime returns UpdatedAt as a time.Time
+func (d Directory) UpdatedAtTime() time.Time { return unixTime(int64(d.UpdatedAt)) }
+
+// AddedAtTime returns AddedAt as a time.Time
+func (m WebhookMetadata) AddedAtTime() time.Time { return unixTime(int64(m.AddedAt)) }
+
+// UpdatedAtTime returns UpdatedAt as a time.Time
+func (m WebhookMetadata) UpdatedAtTime() time.Time { return unixTime(int64(m.UpdatedAt)) }
+
+// UpdatedAtTime returns UpdatedAt as a time.Time
+func (t TimelineEntry) UpdatedAtTime() time.Time { return unixTime(t.UpdatedAt) }
+
+// LastSeenAtTime returns LastSeenAt as a time.Time
+func (d DevicesResponse) LastSeenAtTime() time.Time { return unixTimeString(d.LastSeenAt) }
+
+// CreatedAtTime returns CreatedAt as a time.Time
+func (i InvitedFriend) CreatedAtTime() time.Time { return unixTimeString(i.CreatedAt) }
+
+// CreatedAtTime returns CreatedAt as a time.Time
+func (d PMSDevices) CreatedAtTime() time.Time { return unixTimeString(d.CreatedAt) }
+
+// LastSeenAtTime returns LastSeenAt as a time.Time
+func (d PMSDevices) LastSeenAtTime() time.Time { return unixTimeString(d.LastSeenAt) }
+
+// AddedAtTime returns AddedAt as a time.Time
+func (s Season) AddedAtTime() time.Time { return unixTime(s.AddedAt) }
+
+// LastViewedAtTime returns LastViewedAt as a time.Time, the zero time if it was never watched
+func (s Season) LastViewedAtTime() time.Time { return unixTime(s.LastViewedAt) }
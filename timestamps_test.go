@@ -0,0 +1,51 @@
+package plex
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMetadataTimestamps(t *testing.T) {
+	var m Metadata
+
+	payload := `{"addedAt":1600000000,"updatedAt":1600000100,"originallyAvailableAt":"2020-09-13"}`
+
+	if err := json.Unmarshal([]byte(payload), &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := m.AddedAtTime(); !got.Equal(time.Unix(1600000000, 0)) {
+		t.Errorf("AddedAtTime() = %v", got)
+	}
+
+	if got := m.UpdatedAtTime(); !got.Equal(time.Unix(1600000100, 0)) {
+		t.Errorf("UpdatedAtTime() = %v", got)
+	}
+
+	if got := m.LastViewedAtTime(); !got.IsZero() {
+		t.Errorf("expected unset LastViewedAtTime() to be zero, got %v", got)
+	}
+
+	if got := m.OriginallyAvailableAtTime(); !got.Equal(time.Date(2020, 9, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("OriginallyAvailableAtTime() = %v", got)
+	}
+}
+
+func TestUnixTimeString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"1600000000", time.Unix(1600000000, 0)},
+		{"0", time.Time{}},
+		{"", time.Time{}},
+		{"yesterday", time.Time{}},
+	}
+
+	for _, test := range tests {
+		if got := unixTimeString(test.input); !got.Equal(test.expected) {
+			t.Errorf("unixTimeString(%q) = %v; want %v", test.input, got, test.expected)
+		}
+	}
+}
@@ -3,13 +3,20 @@ package plex
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
+
+	"go.uber.org/zap"
 )
 
+const redacted = "REDACTED"
+
 // safeClose safely closes an io.Closer and handles the error
 func safeClose(closer io.Closer) {
 	if closer != nil {
@@ -62,7 +69,7 @@ func (p *Plex) grab(query string, h headers) (*http.Response, error) {
 		req.Header.Add("X-Plex-Target-Identifier", h.TargetClientIdentifier)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := p.do(&client, req)
 
 	if err != nil {
 		return &http.Response{}, err
@@ -97,7 +104,7 @@ func (p *Plex) get(query string, h headers) (*http.Response, error) {
 		req.Header.Add("X-Plex-Target-Identifier", h.TargetClientIdentifier)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := p.do(&client, req)
 
 	if err != nil {
 		return &http.Response{}, err
@@ -166,7 +173,7 @@ func (p *Plex) delete(query string, h headers) (*http.Response, error) {
 		req.Header.Add("X-Plex-Target-Identifier", h.TargetClientIdentifier)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := p.do(&client, req)
 
 	if err != nil {
 		return &http.Response{}, err
@@ -203,7 +210,7 @@ func (p *Plex) post(query string, body []byte, h headers) (*http.Response, error
 		req.Header.Add("X-Plex-Target-Identifier", h.TargetClientIdentifier)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := p.do(&client, req)
 
 	if err != nil {
 		return &http.Response{}, err
@@ -275,7 +282,7 @@ func (p *Plex) put(query string, body []byte, h headers) (*http.Response, error)
 		req.Header.Add("X-Plex-Target-Identifier", h.TargetClientIdentifier)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := p.do(&client, req)
 
 	if err != nil {
 		return &http.Response{}, err
@@ -284,6 +291,194 @@ func (p *Plex) put(query string, body []byte, h headers) (*http.Response, error)
 	return resp, nil
 }
 
+// do sends req with client, adding the extra headers, running the request hooks
+// and logging the exchange at debug level when a logger is configured with WithLogger.
+// Cacheable requests are answered from the response cache when enabled with WithCache
+// and revalidated with If-None-Match when enabled with WithConditionalRequests.
+func (p *Plex) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	p.setExtraHeaders(req)
+
+	if p.TokenProvider != nil {
+		token, err := p.TokenProvider.Token(req.Context())
+
+		if err != nil {
+			return nil, &RequestError{Method: req.Method, URL: redactURL(req.URL), Err: fmt.Errorf("token provider: %w", err)}
+		}
+
+		req.Header.Set("X-Plex-Token", token)
+	}
+
+	// the hooks also run for responses served from the cache, so they see every request
+	for _, hook := range p.BeforeRequest {
+		hook(req)
+	}
+
+	cacheable := p.cache != nil && p.cache.cacheable(req)
+
+	if cacheable {
+		if resp, ok := p.cache.lookup(req); ok {
+			for _, hook := range p.AfterResponse {
+				hook(resp, nil)
+			}
+
+			if p.Logger != nil {
+				p.Logger.Debug("plex request served from cache", zap.String("method", req.Method), zap.String("url", redactURL(req.URL)))
+			}
+
+			return resp, nil
+		}
+	}
+
+	if p.etags != nil {
+		p.etags.prepare(req)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+
+	if err != nil && p.failover != nil {
+		if retried, ok := p.failover.retry(p, client, req, err); ok {
+			resp, err = retried, nil
+		}
+	}
+
+	latency := time.Since(start)
+
+	if err != nil {
+		err = &RequestError{Method: req.Method, URL: redactURL(req.URL), Err: err}
+	}
+
+	for _, hook := range p.AfterResponse {
+		hook(resp, err)
+	}
+
+	if err == nil && p.etags != nil {
+		resp, err = p.etags.update(req, resp)
+	}
+
+	if err == nil && p.cache != nil {
+		if cacheable {
+			err = p.cache.store(req, resp)
+		} else if req.Method != http.MethodGet && resp.StatusCode < http.StatusBadRequest {
+			p.cache.invalidate()
+		}
+	}
+
+	if p.Logger == nil {
+		return resp, err
+	}
+
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("url", redactURL(req.URL)),
+		zap.Duration("latency", latency),
+	}
+
+	if err != nil {
+		fields = append(fields, zap.String("error", p.redact(req, err.Error())))
+		p.Logger.Debug("plex request failed", fields...)
+
+		return resp, err
+	}
+
+	fields = append(fields, zap.Int("status", resp.StatusCode))
+	p.Logger.Debug("plex request", fields...)
+
+	return resp, nil
+}
+
+// redactURL returns u as a string with the X-Plex-Token query parameter masked.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+
+	if query.Get("X-Plex-Token") == "" {
+		return u.String()
+	}
+
+	query.Set("X-Plex-Token", redacted)
+
+	masked := *u
+	masked.RawQuery = query.Encode()
+
+	return masked.String()
+}
+
+// responseError wraps err, returned for the error status of resp, with the request and an
+// excerpt of the response body. It consumes the body.
+func (p *Plex) responseError(resp *http.Response, err error) error {
+	reqErr := &RequestError{StatusCode: resp.StatusCode, Status: resp.Status, Err: err}
+
+	if resp.Request != nil {
+		reqErr.Method = resp.Request.Method
+		reqErr.URL = redactURL(resp.Request.URL)
+	}
+
+	if resp.Body != nil {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyExcerpt))
+		reqErr.Body = p.redact(resp.Request, strings.TrimSpace(strings.ToValidUTF8(string(body), "")))
+	}
+
+	return reqErr
+}
+
+// redact masks the token sent with req, as set by the TokenProvider, and the client token
+// wherever they appear in s.
+func (p *Plex) redact(req *http.Request, s string) string {
+	tokens := []string{p.Token}
+
+	if req != nil {
+		tokens = append(tokens, req.Header.Get("X-Plex-Token"))
+
+		if req.URL != nil {
+			tokens = append(tokens, req.URL.Query().Get("X-Plex-Token"))
+		}
+	}
+
+	for _, token := range tokens {
+		if token != "" {
+			s = strings.ReplaceAll(s, token, redacted)
+		}
+	}
+
+	return s
+}
+
+// setExtraHeaders copies the user supplied extra headers onto req, replacing any defaults with the same name.
+func (p *Plex) setExtraHeaders(req *http.Request) {
+	for key, values := range p.ExtraHeaders {
+		req.Header.Del(key)
+
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+}
+
+// getContainer requests query and decodes the json MediaContainer envelope of the response
+func getContainer[T any](p *Plex, query string) (Container[T], error) {
+	resp, err := p.get(query, p.Headers)
+
+	if err != nil {
+		return Container[T]{}, err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return Container[T]{}, p.responseError(resp, errors.New(ErrorNotAuthorized))
+	} else if resp.StatusCode != http.StatusOK {
+		return Container[T]{}, p.responseError(resp, fmt.Errorf(ErrorServer, resp.Status))
+	}
+
+	var results Container[T]
+
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return Container[T]{}, err
+	}
+
+	return results, nil
+}
+
 func boolToOneOrZero(input bool) string {
 	if input {
 		return "1"
@@ -1,12 +1,17 @@
 package plex
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
+
+	"go.uber.org/zap/zapcore"
 )
 
 // Test boolToOneOrZero function
@@ -344,3 +349,195 @@ func TestPost_Timeout(t *testing.T) {
 		t.Errorf("Expected timeout error, got: %v", err)
 	}
 }
+
+func TestRequestDebugLoggingRedactsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+
+	p, err := New(server.URL, "secret-token", WithLogger(NewLoggerWithLevel(&buf, zapcore.DebugLevel)))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	resp, err := p.get(server.URL+"/library/sections?X-Plex-Token=secret-token", p.Headers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	safeClose(resp.Body)
+
+	out := buf.String()
+
+	if strings.Contains(out, "secret-token") {
+		t.Fatalf("expected token to be redacted, got %s", out)
+	}
+
+	for _, want := range []string{`"method":"GET"`, `"status":204`, "X-Plex-Token=REDACTED", `"latency"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected log output to contain %s, got %s", want, out)
+		}
+	}
+
+	// unreachable host: the error is logged without the token
+	buf.Reset()
+	server.Close()
+
+	if _, err := p.get(server.URL+"/?X-Plex-Token=secret-token", p.Headers); err == nil {
+		t.Fatal("expected error for closed server")
+	}
+
+	if out := buf.String(); out == "" || strings.Contains(out, "secret-token") {
+		t.Fatalf("expected redacted failure log, got %q", out)
+	}
+}
+
+func TestGetContainer(t *testing.T) {
+	type hubs struct {
+		Size int `json:"size"`
+		Hub  []struct {
+			Title string `json:"title"`
+		} `json:"Hub"`
+	}
+
+	server, p := newJSONTestServer(http.StatusOK, map[string]interface{}{
+		"MediaContainer": map[string]interface{}{
+			"size": 1,
+			"Hub":  []map[string]string{{"title": "Recently Added"}},
+		},
+	})
+	defer server.Close()
+
+	result, err := getContainer[hubs](p, server.URL+"/hubs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.MediaContainer.Size != 1 || result.MediaContainer.Hub[0].Title != "Recently Added" {
+		t.Errorf("unexpected container: %+v", result.MediaContainer)
+	}
+
+	unauthorized, p := newJSONTestServer(http.StatusUnauthorized, nil)
+	defer unauthorized.Close()
+
+	_, err = getContainer[hubs](p, unauthorized.URL+"/hubs")
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Err.Error() != ErrorNotAuthorized || reqErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected %q, got %v", ErrorNotAuthorized, err)
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("database locked for token secret-token " + strings.Repeat("x", 1000)))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "secret-token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	_, err = getContainer[MediaContainer](p, server.URL+"/library/sections?X-Plex-Token=secret-token")
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("expected a RequestError, got %v", err)
+	}
+
+	if reqErr.Method != http.MethodGet || reqErr.StatusCode != http.StatusInternalServerError || len(reqErr.Body) > maxErrorBodyExcerpt {
+		t.Errorf("unexpected error %+v", reqErr)
+	}
+
+	if !strings.Contains(err.Error(), "/library/sections") || !strings.Contains(err.Error(), "database locked") || strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	// transport errors carry the request too, without the token
+	server.Close()
+
+	_, err = getContainer[MediaContainer](p, server.URL+"/library/sections?X-Plex-Token=secret-token")
+
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != 0 || strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("unexpected transport error %v", err)
+	}
+}
+
+func TestRequestError_ProviderToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("invalid token " + r.Header.Get("X-Plex-Token")))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+
+	p, err := New(server.URL, "", WithLogger(NewLoggerWithLevel(&buf, zapcore.DebugLevel)), WithTokenProvider(TokenProviderFunc(func(ctx context.Context) (string, error) {
+		return "rotated-token", nil
+	})))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	_, err = getContainer[MediaContainer](p, server.URL+"/library/sections")
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Body != "invalid token "+redacted {
+		t.Errorf("expected the provider token to be redacted, got %v", err)
+	}
+
+	if strings.Contains(buf.String(), "rotated-token") {
+		t.Errorf("expected no token in the logs, got %s", buf.String())
+	}
+}
+
+func TestTokenProvider(t *testing.T) {
+	var tokens []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("X-Plex-Token"))
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	defer server.Close()
+
+	current := "first"
+	provider := TokenProviderFunc(func(ctx context.Context) (string, error) {
+		if current == "" {
+			return "", errors.New("vault sealed")
+		}
+
+		return current, nil
+	})
+
+	p, err := New(server.URL, "static", WithTokenProvider(provider), WithCache(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("GetLibraries() error = %v", err)
+	}
+
+	// a new token must not be answered from the cache of the previous one
+	current = "second"
+
+	if _, err := p.GetLibraries(); err != nil {
+		t.Fatalf("GetLibraries() error = %v", err)
+	}
+
+	if strings.Join(tokens, ",") != "first,second" {
+		t.Errorf("expected the provided tokens, got %v", tokens)
+	}
+
+	current = ""
+
+	if _, err := p.GetLibraries(); err == nil || !strings.Contains(err.Error(), "vault sealed") {
+		t.Errorf("expected the provider error, got %v", err)
+	}
+}
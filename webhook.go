@@ -1,91 +1,395 @@
 package plex
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
 )
 
 // Webhook contains a webhooks information
 type Webhook struct {
-	Event   string `json:"event"`
-	User    bool   `json:"user"`
-	Owner   bool   `json:"owner"`
-	Account struct {
-		ID    int    `json:"id"`
-		Thumb string `json:"thumb"`
-		Title string `json:"title"`
-	} `json:"Account"`
-	Server struct {
-		Title string `json:"title"`
-		UUID  string `json:"uuid"`
-	} `json:"Server"`
-	Player struct {
-		Local         bool   `json:"local"`
-		PublicAddress string `json:"PublicAddress"`
-		Title         string `json:"title"`
-		UUID          string `json:"uuid"`
-	} `json:"Player"`
-	Metadata struct {
-		LibrarySectionType   string `json:"librarySectionType"`
-		RatingKey            string `json:"ratingKey"`
-		Key                  string `json:"key"`
-		ParentRatingKey      string `json:"parentRatingKey"`
-		GrandparentRatingKey string `json:"grandparentRatingKey"`
-		GUID                 string `json:"guid"`
-		LibrarySectionID     int    `json:"librarySectionID"`
-		MediaType            string `json:"type"`
-		Title                string `json:"title"`
-		GrandparentKey       string `json:"grandparentKey"`
-		ParentKey            string `json:"parentKey"`
-		GrandparentTitle     string `json:"grandparentTitle"`
-		ParentTitle          string `json:"parentTitle"`
-		Summary              string `json:"summary"`
-		Index                int    `json:"index"`
-		ParentIndex          int    `json:"parentIndex"`
-		RatingCount          int    `json:"ratingCount"`
-		Thumb                string `json:"thumb"`
-		Art                  string `json:"art"`
-		ParentThumb          string `json:"parentThumb"`
-		GrandparentThumb     string `json:"grandparentThumb"`
-		GrandparentArt       string `json:"grandparentArt"`
-		AddedAt              int    `json:"addedAt"`
-		UpdatedAt            int    `json:"updatedAt"`
-	} `json:"Metadata"`
+	Event    string          `json:"event"`
+	User     bool            `json:"user"`
+	Owner    bool            `json:"owner"`
+	Account  WebhookAccount  `json:"Account"`
+	Server   WebhookServer   `json:"Server"`
+	Player   WebhookPlayer   `json:"Player"`
+	Metadata WebhookMetadata `json:"Metadata"`
+	// Thumbnail is the jpeg artwork plex attaches to some events as the "thumb" part of the multipart body.
+	// It is empty when plex did not send one.
+	Thumbnail []byte `json:"-"`
+}
+
+// WebhookAccount is the plex account that triggered the webhook
+type WebhookAccount struct {
+	ID    int    `json:"id"`
+	Thumb string `json:"thumb"`
+	Title string `json:"title"`
+}
+
+// WebhookServer is the plex media server that sent the webhook
+type WebhookServer struct {
+	Title string `json:"title"`
+	UUID  string `json:"uuid"`
+}
+
+// WebhookPlayer is the client the media is being consumed on
+type WebhookPlayer struct {
+	Local         bool   `json:"local"`
+	PublicAddress string `json:"PublicAddress"`
+	Title         string `json:"title"`
+	UUID          string `json:"uuid"`
+}
+
+// WebhookMetadata is the media the webhook event relates to
+type WebhookMetadata struct {
+	LibrarySectionType   string    `json:"librarySectionType"`
+	RatingKey            RatingKey `json:"ratingKey"`
+	Key                  string    `json:"key"`
+	ParentRatingKey      RatingKey `json:"parentRatingKey"`
+	GrandparentRatingKey RatingKey `json:"grandparentRatingKey"`
+	GUID                 string    `json:"guid"`
+	LibrarySectionID     int       `json:"librarySectionID"`
+	MediaType            string    `json:"type"`
+	Title                string    `json:"title"`
+	GrandparentKey       string    `json:"grandparentKey"`
+	ParentKey            string    `json:"parentKey"`
+	GrandparentTitle     string    `json:"grandparentTitle"`
+	ParentTitle          string    `json:"parentTitle"`
+	Summary              string    `json:"summary"`
+	Index                int       `json:"index"`
+	ParentIndex          int       `json:"parentIndex"`
+	RatingCount          int       `json:"ratingCount"`
+	Thumb                string    `json:"thumb"`
+	Art                  string    `json:"art"`
+	ParentThumb          string    `json:"parentThumb"`
+	GrandparentThumb     string    `json:"grandparentThumb"`
+	GrandparentArt       string    `json:"grandparentArt"`
+	AddedAt              int       `json:"addedAt"`
+	UpdatedAt            int       `json:"updatedAt"`
+}
+
+// WebhookPayloadError is returned when a webhook payload is malformed or misses a required field
+type WebhookPayloadError struct {
+	// Field is the json field at fault, empty when the payload as a whole could not be parsed
+	Field  string
+	Reason string
+	Err    error
+}
+
+func (e *WebhookPayloadError) Error() string {
+	msg := "invalid webhook payload"
+
+	if e.Field != "" {
+		msg += ": " + e.Field
+	}
+
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *WebhookPayloadError) Unwrap() error {
+	return e.Err
+}
+
+// ParseWebhook decodes and validates a webhook json payload.
+// A malformed payload returns a *WebhookPayloadError.
+func ParseWebhook(payload []byte) (Webhook, error) {
+	var hookEvent Webhook
+
+	if err := json.Unmarshal(payload, &hookEvent); err != nil {
+		var typeErr *json.UnmarshalTypeError
+
+		if errors.As(err, &typeErr) {
+			return Webhook{}, &WebhookPayloadError{Field: typeErr.Field, Reason: "wrong type", Err: err}
+		}
+
+		return Webhook{}, &WebhookPayloadError{Reason: "can not parse json", Err: err}
+	}
+
+	if err := hookEvent.Validate(); err != nil {
+		return Webhook{}, err
+	}
+
+	return hookEvent, nil
+}
+
+// Validate checks that the fields required to dispatch the webhook are present and well formed
+func (w Webhook) Validate() error {
+	if w.Event == "" {
+		return &WebhookPayloadError{Field: "event", Reason: "is required"}
+	}
+
+	keys := []struct {
+		field string
+		key   RatingKey
+	}{
+		{"Metadata.ratingKey", w.Metadata.RatingKey},
+		{"Metadata.parentRatingKey", w.Metadata.ParentRatingKey},
+		{"Metadata.grandparentRatingKey", w.Metadata.GrandparentRatingKey},
+	}
+
+	for _, k := range keys {
+		if k.key == "" {
+			continue
+		}
+
+		if _, err := k.key.Int64(); err != nil {
+			return &WebhookPayloadError{Field: k.field, Reason: "must be numeric", Err: err}
+		}
+	}
+
+	return nil
 }
 
 // WebhookEvents holds the actions for each webhook events
 type WebhookEvents struct {
 	events map[string]func(w Webhook)
+	// onAny is called for every webhook before the event specific callback
+	onAny func(w Webhook)
+	// onUnknown is called with the raw payload when the event name is not recognized
+	onUnknown func(w Webhook, payload []byte)
+	// onError is called when a webhook request can not be processed
+	onError func(r *http.Request, err error)
+
+	// mu guards queue which is only set while async dispatch is enabled
+	mu      sync.RWMutex
+	queue   chan func()
+	workers sync.WaitGroup
 }
 
-// Handler listens for plex webhooks and executes the corresponding function
+// WebhookMiddleware wraps the webhook http.Handler, e.g. to add authentication or request logging
+type WebhookMiddleware func(http.Handler) http.Handler
+
+// Handler listens for plex webhooks and executes the corresponding function.
+// Malformed requests are answered with a 400 status code and reported to the OnError callback.
 func (wh *WebhookEvents) Handler(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseMultipartForm(0); err != nil {
-		fmt.Printf("can not read form: %v", err)
+	status, err := wh.handle(r)
+
+	if err != nil {
+		wh.reportError(r, err)
+	}
+
+	w.WriteHeader(status)
+}
+
+// HTTPHandler returns Handler as an http.Handler wrapped by the given middleware.
+// The first middleware is the outermost one, so it sees the request first.
+func (wh *WebhookEvents) HTTPHandler(middleware ...WebhookMiddleware) http.Handler {
+	var h http.Handler = http.HandlerFunc(wh.Handler)
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			h = middleware[i](h)
+		}
+	}
+
+	return h
+}
+
+// OnError executes when a webhook request can not be processed, e.g. a malformed form or payload.
+// If no callback is set the error is logged with the package logger.
+func (wh *WebhookEvents) OnError(fn func(r *http.Request, err error)) {
+	wh.onError = fn
+}
+
+func (wh *WebhookEvents) reportError(r *http.Request, err error) {
+	if wh.onError != nil {
+		wh.onError(r, err)
 		return
 	}
 
-	var hookEvent Webhook
+	logger.Warn("webhook error", zap.String("error", err.Error()))
+}
+
+// handle parses the webhook request and dispatches it, returning the status code to respond with
+func (wh *WebhookEvents) handle(r *http.Request) (int, error) {
+	if err := r.ParseMultipartForm(0); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("can not read form: %w", err)
+	}
 
 	payload, hasPayload := r.MultipartForm.Value["payload"]
 
-	if hasPayload {
-		if err := json.Unmarshal([]byte(payload[0]), &hookEvent); err != nil {
-			fmt.Printf("can not parse json: %v", err)
-			return
+	if !hasPayload || len(payload) == 0 {
+		return http.StatusBadRequest, errors.New("missing payload")
+	}
+
+	hookEvent, err := ParseWebhook([]byte(payload[0]))
+
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	thumb, err := readWebhookThumbnail(r.MultipartForm)
+
+	if err != nil {
+		// the payload is still usable without artwork
+		wh.reportError(r, fmt.Errorf("can not read thumbnail: %w", err))
+	}
+
+	hookEvent.Thumbnail = thumb
+
+	fn, ok := wh.events[hookEvent.Event]
+
+	var unknownErr error
+
+	if !ok && wh.onUnknown == nil {
+		unknownErr = fmt.Errorf("unknown event name: %s", hookEvent.Event)
+	}
+
+	dispatch := func() {
+		if wh.onAny != nil {
+			wh.onAny(hookEvent)
 		}
 
-		fn, ok := wh.events[hookEvent.Event]
+		if ok {
+			fn(hookEvent)
+		} else if wh.onUnknown != nil {
+			wh.onUnknown(hookEvent, []byte(payload[0]))
+		}
+	}
+
+	if !wh.enqueue(r, dispatch) {
+		return http.StatusServiceUnavailable, errors.New("webhook queue is full")
+	}
 
-		if !ok {
-			fmt.Printf("unknown event name: %v\n", hookEvent.Event)
-			return
+	return http.StatusOK, unknownErr
+}
+
+// enqueue hands the dispatch to the worker pool if async dispatch is enabled, otherwise it runs it
+// on the request goroutine. It returns false when the queue is full.
+func (wh *WebhookEvents) enqueue(r *http.Request, dispatch func()) bool {
+	wh.mu.RLock()
+
+	if wh.queue == nil {
+		// the callbacks may enable or stop async dispatch, which takes the lock
+		wh.mu.RUnlock()
+		wh.safeDispatch(r, dispatch)
+
+		return true
+	}
+
+	// the queue is closed under the write lock, so hold the read lock while sending
+	defer wh.mu.RUnlock()
+
+	select {
+	case wh.queue <- func() { wh.safeDispatch(r, dispatch) }:
+		return true
+	default:
+		return false
+	}
+}
+
+// safeDispatch runs the user callbacks and reports a panic as an error instead of crashing the server
+func (wh *WebhookEvents) safeDispatch(r *http.Request, dispatch func()) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			wh.reportError(r, fmt.Errorf("webhook callback panicked: %v", rec))
 		}
+	}()
+
+	dispatch()
+}
+
+// EnableAsync dispatches webhook callbacks on a pool of workers instead of the request goroutine, so a
+// slow callback can not hold up the http server. queueSize bounds how many webhooks may wait for a worker;
+// once it is full the handler responds with 503. Call Close to stop the workers.
+func (wh *WebhookEvents) EnableAsync(workers, queueSize int) {
+	if workers < 1 {
+		workers = 1
+	}
 
-		fn(hookEvent)
+	if queueSize < 0 {
+		queueSize = 0
 	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	if wh.queue != nil {
+		return
+	}
+
+	queue := make(chan func(), queueSize)
+	wh.queue = queue
+
+	for i := 0; i < workers; i++ {
+		wh.workers.Add(1)
+
+		go func() {
+			defer wh.workers.Done()
+
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+}
+
+// Close stops the async workers once the queued webhooks have been dispatched.
+// Webhooks received afterwards are dispatched on the request goroutine.
+func (wh *WebhookEvents) Close() error {
+	return wh.Shutdown(context.Background())
+}
+
+// Shutdown is like Close but stops waiting for the queued webhooks when ctx is done,
+// returning ctx.Err(). The workers still dispatch them in the background.
+func (wh *WebhookEvents) Shutdown(ctx context.Context) error {
+	wh.mu.Lock()
+
+	if wh.queue != nil {
+		close(wh.queue)
+		wh.queue = nil
+	}
+
+	wh.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		wh.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readWebhookThumbnail returns the contents of the "thumb" file part, if any
+func readWebhookThumbnail(form *multipart.Form) ([]byte, error) {
+	files, ok := form.File["thumb"]
+
+	if !ok || len(files) == 0 {
+		return nil, nil
+	}
+
+	f, err := files[0].Open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer safeClose(f)
+
+	return io.ReadAll(f)
 }
 
 // newWebhookEvent attaches a function to each webhook event
@@ -150,3 +454,14 @@ func (wh *WebhookEvents) OnScrobble(fn func(w Webhook)) error {
 func (wh *WebhookEvents) OnRate(fn func(w Webhook)) error {
 	return wh.newWebhookEvent("media.rate", fn)
 }
+
+// OnAny executes for every webhook received, before the event specific callback
+func (wh *WebhookEvents) OnAny(fn func(w Webhook)) {
+	wh.onAny = fn
+}
+
+// OnUnknown executes when the webhook receives an event name that is not recognized.
+// The raw json payload is passed along so callers can decode fields this package does not know about.
+func (wh *WebhookEvents) OnUnknown(fn func(w Webhook, payload []byte)) {
+	wh.onUnknown = fn
+}
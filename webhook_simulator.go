@@ -0,0 +1,88 @@
+package plex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+// NewWebhookRequest builds the request plex sends to a webhook url for hook: a multipart
+// form with the json payload and, when hook.Thumbnail is set, the artwork as a jpeg.
+// Serving it to a handler tests it without playing anything on a real server.
+func NewWebhookRequest(targetURL string, hook Webhook) (*http.Request, error) {
+	payload, err := json.Marshal(hook)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+
+	form := multipart.NewWriter(&body)
+
+	if err := form.WriteField("payload", string(payload)); err != nil {
+		return nil, err
+	}
+
+	if len(hook.Thumbnail) > 0 {
+		partHeader := textproto.MIMEHeader{}
+		partHeader.Set("Content-Disposition", `form-data; name="thumb"; filename="image.jpg"`)
+		partHeader.Set("Content-Type", "image/jpeg")
+
+		part, err := form.CreatePart(partHeader)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := part.Write(hook.Thumbnail); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, targetURL, &body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("User-Agent", "PlexMediaServer")
+
+	return req, nil
+}
+
+// SendWebhook posts hook to targetURL the way plex does, see NewWebhookRequest.
+// A nil client uses http.DefaultClient. Statuses other than 2xx are returned as errors.
+func SendWebhook(client *http.Client, targetURL string, hook Webhook) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := NewWebhookRequest(targetURL, hook)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf(ErrorServer, resp.Status)
+	}
+
+	return nil
+}
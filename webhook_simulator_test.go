@@ -0,0 +1,49 @@
+package plex
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendWebhook(t *testing.T) {
+	var got Webhook
+	var errs []error
+
+	wh := NewWebhook()
+	wh.OnError(func(r *http.Request, err error) { errs = append(errs, err) })
+	_ = wh.OnScrobble(func(w Webhook) { got = w })
+
+	server := httptest.NewServer(wh.HTTPHandler())
+	defer server.Close()
+
+	hook := Webhook{
+		Event:     "media.scrobble",
+		Owner:     true,
+		Account:   WebhookAccount{ID: 1, Title: "alice"},
+		Player:    WebhookPlayer{Title: "Living Room", Local: true},
+		Metadata:  WebhookMetadata{RatingKey: "42", MediaType: "movie", Title: "Heat"},
+		Thumbnail: []byte("jpeg data"),
+	}
+
+	if err := SendWebhook(nil, server.URL, hook); err != nil {
+		t.Fatalf("SendWebhook() error = %v", err)
+	}
+
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+
+	if got.Event != "media.scrobble" || got.Account.Title != "alice" || got.Metadata.RatingKey != "42" || !bytes.Equal(got.Thumbnail, hook.Thumbnail) {
+		t.Errorf("unexpected webhook %+v", got)
+	}
+
+	if err := SendWebhook(nil, server.URL, Webhook{}); err == nil {
+		t.Errorf("expected an error for a webhook without an event")
+	}
+
+	if len(errs) != 1 {
+		t.Errorf("expected the handler to report the invalid webhook, got %v", errs)
+	}
+}
@@ -2,12 +2,15 @@ package plex
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Test Handler function
@@ -16,59 +19,22 @@ func TestWebhookEvents_Handler(t *testing.T) {
 		Event: "media.play",
 		User:  true,
 		Owner: false,
-		Account: struct {
-			ID    int    `json:"id"`
-			Thumb string `json:"thumb"`
-			Title string `json:"title"`
-		}{
+		Account: WebhookAccount{
 			ID:    123,
 			Thumb: "thumb.jpg",
 			Title: "Test User",
 		},
-		Server: struct {
-			Title string `json:"title"`
-			UUID  string `json:"uuid"`
-		}{
+		Server: WebhookServer{
 			Title: "Test Server",
 			UUID:  "server-uuid",
 		},
-		Player: struct {
-			Local         bool   `json:"local"`
-			PublicAddress string `json:"PublicAddress"`
-			Title         string `json:"title"`
-			UUID          string `json:"uuid"`
-		}{
+		Player: WebhookPlayer{
 			Local:         true,
 			PublicAddress: "192.168.1.100",
 			Title:         "Test Player",
 			UUID:          "player-uuid",
 		},
-		Metadata: struct {
-			LibrarySectionType   string `json:"librarySectionType"`
-			RatingKey            string `json:"ratingKey"`
-			Key                  string `json:"key"`
-			ParentRatingKey      string `json:"parentRatingKey"`
-			GrandparentRatingKey string `json:"grandparentRatingKey"`
-			GUID                 string `json:"guid"`
-			LibrarySectionID     int    `json:"librarySectionID"`
-			MediaType            string `json:"type"`
-			Title                string `json:"title"`
-			GrandparentKey       string `json:"grandparentKey"`
-			ParentKey            string `json:"parentKey"`
-			GrandparentTitle     string `json:"grandparentTitle"`
-			ParentTitle          string `json:"parentTitle"`
-			Summary              string `json:"summary"`
-			Index                int    `json:"index"`
-			ParentIndex          int    `json:"parentIndex"`
-			RatingCount          int    `json:"ratingCount"`
-			Thumb                string `json:"thumb"`
-			Art                  string `json:"art"`
-			ParentThumb          string `json:"parentThumb"`
-			GrandparentThumb     string `json:"grandparentThumb"`
-			GrandparentArt       string `json:"grandparentArt"`
-			AddedAt              int    `json:"addedAt"`
-			UpdatedAt            int    `json:"updatedAt"`
-		}{
+		Metadata: WebhookMetadata{
 			LibrarySectionType: "movie",
 			RatingKey:          "123",
 			Key:                "/library/metadata/123",
@@ -494,40 +460,11 @@ func TestWebhookEvents_CompleteFlow(t *testing.T) {
 	playWebhook := Webhook{
 		Event: "media.play",
 		User:  true,
-		Account: struct {
-			ID    int    `json:"id"`
-			Thumb string `json:"thumb"`
-			Title string `json:"title"`
-		}{
+		Account: WebhookAccount{
 			ID:    123,
 			Title: "Test User",
 		},
-		Metadata: struct {
-			LibrarySectionType   string `json:"librarySectionType"`
-			RatingKey            string `json:"ratingKey"`
-			Key                  string `json:"key"`
-			ParentRatingKey      string `json:"parentRatingKey"`
-			GrandparentRatingKey string `json:"grandparentRatingKey"`
-			GUID                 string `json:"guid"`
-			LibrarySectionID     int    `json:"librarySectionID"`
-			MediaType            string `json:"type"`
-			Title                string `json:"title"`
-			GrandparentKey       string `json:"grandparentKey"`
-			ParentKey            string `json:"parentKey"`
-			GrandparentTitle     string `json:"grandparentTitle"`
-			ParentTitle          string `json:"parentTitle"`
-			Summary              string `json:"summary"`
-			Index                int    `json:"index"`
-			ParentIndex          int    `json:"parentIndex"`
-			RatingCount          int    `json:"ratingCount"`
-			Thumb                string `json:"thumb"`
-			Art                  string `json:"art"`
-			ParentThumb          string `json:"parentThumb"`
-			GrandparentThumb     string `json:"grandparentThumb"`
-			GrandparentArt       string `json:"grandparentArt"`
-			AddedAt              int    `json:"addedAt"`
-			UpdatedAt            int    `json:"updatedAt"`
-		}{
+		Metadata: WebhookMetadata{
 			Title:     "Test Movie",
 			MediaType: "movie",
 		},
@@ -536,11 +473,7 @@ func TestWebhookEvents_CompleteFlow(t *testing.T) {
 	pauseWebhook := Webhook{
 		Event: "media.pause",
 		User:  true,
-		Account: struct {
-			ID    int    `json:"id"`
-			Thumb string `json:"thumb"`
-			Title string `json:"title"`
-		}{
+		Account: WebhookAccount{
 			ID:    456,
 			Title: "Another User",
 		},
@@ -586,3 +519,355 @@ func TestWebhookEvents_CompleteFlow(t *testing.T) {
 		t.Errorf("Expected account ID 456, got %d", pauseEventReceived.Account.ID)
 	}
 }
+
+// newWebhookRequest builds a multipart webhook request with the given json payload
+func newWebhookRequest(t *testing.T, payload string) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("payload", payload)
+	_ = writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return req
+}
+
+// Test OnAny and OnUnknown callbacks
+func TestWebhookEvents_OnAnyAndOnUnknown(t *testing.T) {
+	wh := NewWebhook()
+
+	var anyEvents []string
+	var unknownEvent string
+	var unknownPayload []byte
+	playCalled := false
+
+	wh.OnAny(func(w Webhook) {
+		anyEvents = append(anyEvents, w.Event)
+	})
+	wh.OnUnknown(func(w Webhook, payload []byte) {
+		unknownEvent = w.Event
+		unknownPayload = payload
+	})
+	_ = wh.OnPlay(func(w Webhook) {
+		playCalled = true
+	})
+
+	wh.Handler(httptest.NewRecorder(), newWebhookRequest(t, `{"event":"media.play"}`))
+
+	rawUnknown := `{"event":"library.new","extra":"value"}`
+	wh.Handler(httptest.NewRecorder(), newWebhookRequest(t, rawUnknown))
+
+	if !playCalled {
+		t.Errorf("Expected OnPlay to be called")
+	}
+
+	if len(anyEvents) != 2 || anyEvents[0] != "media.play" || anyEvents[1] != "library.new" {
+		t.Errorf("Expected OnAny to receive both events, got %v", anyEvents)
+	}
+
+	if unknownEvent != "library.new" {
+		t.Errorf("Expected unknown event 'library.new', got %q", unknownEvent)
+	}
+
+	if string(unknownPayload) != rawUnknown {
+		t.Errorf("Expected raw payload %s, got %s", rawUnknown, unknownPayload)
+	}
+}
+
+// Test the thumb part is exposed on the Webhook
+func TestWebhookEvents_Handler_Thumbnail(t *testing.T) {
+	wh := NewWebhook()
+
+	var received Webhook
+	_ = wh.OnPlay(func(w Webhook) {
+		received = w
+	})
+
+	jpeg := []byte{0xff, 0xd8, 0xff, 0xe0, 0x00, 0x10}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("payload", `{"event":"media.play"}`)
+	part, err := writer.CreateFormFile("thumb", "thumb.jpg")
+	if err != nil {
+		t.Fatalf("Failed to create thumb part: %v", err)
+	}
+	_, _ = part.Write(jpeg)
+	_ = writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	wh.Handler(httptest.NewRecorder(), req)
+
+	if !bytes.Equal(received.Thumbnail, jpeg) {
+		t.Errorf("Expected thumbnail %v, got %v", jpeg, received.Thumbnail)
+	}
+
+	// no thumb part leaves Thumbnail empty
+	received = Webhook{}
+	wh.Handler(httptest.NewRecorder(), newWebhookRequest(t, `{"event":"media.play"}`))
+
+	if received.Thumbnail != nil {
+		t.Errorf("Expected no thumbnail, got %v", received.Thumbnail)
+	}
+}
+
+// Test HTTPHandler status codes, middleware order and error reporting
+func TestWebhookEvents_HTTPHandler(t *testing.T) {
+	wh := NewWebhook()
+
+	var reported []error
+	wh.OnError(func(r *http.Request, err error) {
+		reported = append(reported, err)
+	})
+
+	var order []string
+	middleware := func(name string) WebhookMiddleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	handler := wh.HTTPHandler(middleware("outer"), middleware("inner"))
+
+	tests := []struct {
+		name         string
+		req          *http.Request
+		expectStatus int
+		expectError  bool
+	}{
+		{
+			name:         "valid event",
+			req:          newWebhookRequest(t, `{"event":"media.play"}`),
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "invalid json",
+			req:          newWebhookRequest(t, `{"invalid": json}`),
+			expectStatus: http.StatusBadRequest,
+			expectError:  true,
+		},
+		{
+			name: "not a multipart form",
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("invalid form data"))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return req
+			}(),
+			expectStatus: http.StatusBadRequest,
+			expectError:  true,
+		},
+		{
+			name:         "unknown event",
+			req:          newWebhookRequest(t, `{"event":"unknown.event"}`),
+			expectStatus: http.StatusOK,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported = nil
+			order = nil
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, tt.req)
+
+			if w.Code != tt.expectStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectStatus, w.Code)
+			}
+
+			if tt.expectError && len(reported) == 0 {
+				t.Errorf("Expected an error to be reported")
+			} else if !tt.expectError && len(reported) > 0 {
+				t.Errorf("Unexpected errors reported: %v", reported)
+			}
+
+			if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+				t.Errorf("Expected middleware order [outer inner], got %v", order)
+			}
+		})
+	}
+}
+
+// Test a panicking callback is recovered and reported
+func TestWebhookEvents_Handler_PanicRecovery(t *testing.T) {
+	wh := NewWebhook()
+
+	var reported error
+	wh.OnError(func(r *http.Request, err error) {
+		reported = err
+	})
+	_ = wh.OnPlay(func(w Webhook) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	wh.Handler(w, newWebhookRequest(t, `{"event":"media.play"}`))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	if reported == nil || !strings.Contains(reported.Error(), "boom") {
+		t.Errorf("Expected panic to be reported, got %v", reported)
+	}
+}
+
+// Test async dispatch runs callbacks on the worker pool and rejects webhooks once the queue is full
+func TestWebhookEvents_EnableAsync(t *testing.T) {
+	wh := NewWebhook()
+
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	received := make(chan string, 3)
+
+	_ = wh.OnPlay(func(w Webhook) {
+		started <- struct{}{}
+		<-release
+		received <- w.Event
+	})
+
+	wh.EnableAsync(1, 1)
+
+	// occupies the only worker
+	w := httptest.NewRecorder()
+	wh.Handler(w, newWebhookRequest(t, `{"event":"media.play"}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	<-started
+
+	// waits in the queue
+	w = httptest.NewRecorder()
+	wh.Handler(w, newWebhookRequest(t, `{"event":"media.play"}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	// queue is full
+	w = httptest.NewRecorder()
+	wh.Handler(w, newWebhookRequest(t, `{"event":"media.play"}`))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+
+	// a blocked callback outlasts the shutdown deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := ShutdownAll(ctx, wh); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	close(release)
+
+	if err := wh.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Errorf("Expected 2 dispatched webhooks, got %d", len(received))
+	}
+}
+
+// Test a synchronous callback can switch to async dispatch, which takes the lock enqueue used to hold
+func TestWebhookEvents_EnableAsyncFromCallback(t *testing.T) {
+	wh := NewWebhook()
+	defer func() { _ = wh.Close() }()
+
+	_ = wh.OnPlay(func(w Webhook) {
+		wh.EnableAsync(1, 1)
+	})
+
+	done := make(chan struct{})
+
+	go func() {
+		wh.Handler(httptest.NewRecorder(), newWebhookRequest(t, `{"event":"media.play"}`))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the callback to enable async dispatch without deadlocking")
+	}
+}
+
+// Test ParseWebhook decodes typed rating keys and reports malformed payloads
+func TestParseWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		expectField string
+		expectError bool
+		expectKey   int64
+	}{
+		{
+			name:      "string rating key",
+			payload:   `{"event":"media.play","Metadata":{"ratingKey":"123"}}`,
+			expectKey: 123,
+		},
+		{
+			name:      "numeric rating key",
+			payload:   `{"event":"media.play","Metadata":{"ratingKey":456}}`,
+			expectKey: 456,
+		},
+		{
+			name:        "missing event",
+			payload:     `{"Metadata":{"ratingKey":"123"}}`,
+			expectError: true,
+			expectField: "event",
+		},
+		{
+			name:        "non numeric rating key",
+			payload:     `{"event":"media.play","Metadata":{"ratingKey":"abc"}}`,
+			expectError: true,
+			expectField: "Metadata.ratingKey",
+		},
+		{
+			name:        "wrong type",
+			payload:     `{"event":"media.play","Account":{"id":"not-a-number"}}`,
+			expectError: true,
+			expectField: "Account.id",
+		},
+		{
+			name:        "invalid json",
+			payload:     `{"invalid": json}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := ParseWebhook([]byte(tt.payload))
+
+			if tt.expectError {
+				var payloadErr *WebhookPayloadError
+				if !errors.As(err, &payloadErr) {
+					t.Fatalf("Expected *WebhookPayloadError, got %v", err)
+				}
+				if payloadErr.Field != tt.expectField {
+					t.Errorf("Expected field %q, got %q", tt.expectField, payloadErr.Field)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			key, err := hook.Metadata.RatingKey.Int64()
+			if err != nil || key != tt.expectKey {
+				t.Errorf("Expected rating key %d, got %d (%v)", tt.expectKey, key, err)
+			}
+		})
+	}
+}
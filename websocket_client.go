@@ -6,6 +6,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"context"
@@ -14,25 +18,56 @@ import (
 	"go.uber.org/zap"
 )
 
-// TimelineEntry ...
+var (
+	// websocketPongWait is how long the connection may stay silent, pongs included, before it is considered dead.
+	// It is a variable so tests can shorten it.
+	websocketPongWait = 60 * time.Second
+	// websocketPingPeriod is how often pings are sent; it must be shorter than websocketPongWait.
+	websocketPingPeriod = (websocketPongWait * 9) / 10
+	// websocketWriteWait is the time allowed to write a control message to the server.
+	websocketWriteWait = 10 * time.Second
+)
+
+// TimelineEntry is sent when library items are added, updated, analyzed or removed
 type TimelineEntry struct {
 	Identifier    string `json:"identifier"`
 	ItemID        int64  `json:"itemID"`
+	ParentItemID  int64  `json:"parentItemID"`
+	RootItemID    int64  `json:"rootItemID"`
 	MetadataState string `json:"metadataState"`
+	MediaState    string `json:"mediaState"`
 	SectionID     int64  `json:"sectionID"`
 	State         int64  `json:"state"`
 	Title         string `json:"title"`
 	Type          int64  `json:"type"`
 	UpdatedAt     int64  `json:"updatedAt"`
+	QueueSize     int64  `json:"queueSize"`
 }
 
-// UnmarshalJSON for TimelineEntry accepts both numeric and string-encoded sectionID values.
+// States of a TimelineEntry
+const (
+	TimelineStateCreated    int64 = 0
+	TimelineStateProcessing int64 = 1
+	TimelineStateMatching   int64 = 2
+	TimelineStateLoading    int64 = 4
+	TimelineStateFinished   int64 = 5
+	TimelineStateAnalyzing  int64 = 6
+	TimelineStateDeleted    int64 = 9
+)
+
+// UnmarshalJSON for TimelineEntry accepts both numeric and string-encoded integer values.
 func (t *TimelineEntry) UnmarshalJSON(b []byte) error {
 	// Create an alias to avoid recursion
 	type alias TimelineEntry
 	var aux struct {
-		ItemID    json.RawMessage `json:"itemID"`
-		SectionID json.RawMessage `json:"sectionID"`
+		ItemID       json.RawMessage `json:"itemID"`
+		ParentItemID json.RawMessage `json:"parentItemID"`
+		RootItemID   json.RawMessage `json:"rootItemID"`
+		SectionID    json.RawMessage `json:"sectionID"`
+		State        json.RawMessage `json:"state"`
+		Type         json.RawMessage `json:"type"`
+		UpdatedAt    json.RawMessage `json:"updatedAt"`
+		QueueSize    json.RawMessage `json:"queueSize"`
 		alias
 	}
 
@@ -43,18 +78,29 @@ func (t *TimelineEntry) UnmarshalJSON(b []byte) error {
 	// Default assign other fields
 	*t = TimelineEntry(aux.alias)
 
-	// Parse itemID
-	if v, err := parseFlexibleInt64(aux.ItemID); err == nil {
-		t.ItemID = v
-	} else {
-		return fmt.Errorf("invalid itemID: %w", err)
+	fields := []struct {
+		name string
+		raw  json.RawMessage
+		dst  *int64
+	}{
+		{"itemID", aux.ItemID, &t.ItemID},
+		{"parentItemID", aux.ParentItemID, &t.ParentItemID},
+		{"rootItemID", aux.RootItemID, &t.RootItemID},
+		{"sectionID", aux.SectionID, &t.SectionID},
+		{"state", aux.State, &t.State},
+		{"type", aux.Type, &t.Type},
+		{"updatedAt", aux.UpdatedAt, &t.UpdatedAt},
+		{"queueSize", aux.QueueSize, &t.QueueSize},
 	}
 
-	// Parse sectionID
-	if v, err := parseFlexibleInt64(aux.SectionID); err == nil {
-		t.SectionID = v
-	} else {
-		return fmt.Errorf("invalid sectionID: %w", err)
+	for _, f := range fields {
+		v, err := parseFlexibleInt64(f.raw)
+
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", f.name, err)
+		}
+
+		*f.dst = v
 	}
 
 	return nil
@@ -62,26 +108,33 @@ func (t *TimelineEntry) UnmarshalJSON(b []byte) error {
 
 // ActivityNotification ...
 type ActivityNotification struct {
-	Activity struct {
-		Cancellable bool   `json:"cancellable"`
-		Progress    int64  `json:"progress"`
-		Subtitle    string `json:"subtitle"`
-		Title       string `json:"title"`
-		Type        string `json:"type"`
-		UserID      int64  `json:"userID"`
-		UUID        string `json:"uuid"`
-	} `json:"Activity"`
-	Event string `json:"event"`
-	UUID  string `json:"uuid"`
-}
-
-// UnmarshalJSON for ActivityNotification parses numeric-or-string userID.
-func (a *ActivityNotification) UnmarshalJSON(b []byte) error {
-	type alias ActivityNotification
+	Activity Activity `json:"Activity"`
+	Event    string   `json:"event"`
+	UUID     string   `json:"uuid"`
+}
+
+// Activity is a long running server task, e.g. a library scan
+type Activity struct {
+	Cancellable bool            `json:"cancellable"`
+	Progress    int64           `json:"progress"`
+	Subtitle    string          `json:"subtitle"`
+	Title       string          `json:"title"`
+	Type        string          `json:"type"`
+	UserID      int64           `json:"userID"`
+	UUID        string          `json:"uuid"`
+	Context     ActivityContext `json:"Context"`
+}
+
+// ActivityContext is what an Activity works on
+type ActivityContext struct {
+	LibrarySectionID FlexibleInt64 `json:"librarySectionID"`
+}
+
+// UnmarshalJSON for Activity parses numeric-or-string userID.
+func (a *Activity) UnmarshalJSON(b []byte) error {
+	type alias Activity
 	var aux struct {
-		Activity struct {
-			UserID json.RawMessage `json:"userID"`
-		} `json:"Activity"`
+		UserID json.RawMessage `json:"userID"`
 		alias
 	}
 
@@ -89,10 +142,10 @@ func (a *ActivityNotification) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	*a = ActivityNotification(aux.alias)
+	*a = Activity(aux.alias)
 
-	if v, err := parseFlexibleInt64(aux.Activity.UserID); err == nil {
-		a.Activity.UserID = v
+	if v, err := parseFlexibleInt64(aux.UserID); err == nil {
+		a.UserID = v
 	} else {
 		return fmt.Errorf("invalid Activity.userID: %w", err)
 	}
@@ -100,13 +153,23 @@ func (a *ActivityNotification) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// StatusNotification ...
+// StatusNotification is a human readable server status message, e.g. a finished library scan
 type StatusNotification struct {
 	Description      string `json:"description"`
 	NotificationName string `json:"notificationName"`
 	Title            string `json:"title"`
 }
 
+// ProgressNotification is sent while the server is working on a long running task, e.g. a library scan
+type ProgressNotification struct {
+	Message string `json:"message"`
+}
+
+// ProviderContentChangeNotification is sent when the content of a media provider changes
+type ProviderContentChangeNotification struct {
+	Identifier string `json:"identifier"`
+}
+
 // PlaySessionStateNotification ...
 type PlaySessionStateNotification struct {
 	GUID             string `json:"guid"`
@@ -150,11 +213,26 @@ func (p *PlaySessionStateNotification) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// ReachabilityNotification ...
+// ReachabilityNotification tells whether the server is reachable from outside the local network
 type ReachabilityNotification struct {
 	Reachability bool `json:"reachability"`
 }
 
+// UnmarshalJSON accepts reachability as a boolean or as 0/1.
+func (r *ReachabilityNotification) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		Reachability boolOrInt `json:"reachability"`
+	}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	r.Reachability = aux.Reachability.bool
+
+	return nil
+}
+
 // BackgroundProcessingQueueEventNotification ...
 type BackgroundProcessingQueueEventNotification struct {
 	Event   string `json:"event"`
@@ -184,42 +262,93 @@ func (bq *BackgroundProcessingQueueEventNotification) UnmarshalJSON(b []byte) er
 	return nil
 }
 
-// TranscodeSession ...
+// TranscodeSession is a transcode session, as listed by /transcode/sessions and sent with
+// transcode notifications
 type TranscodeSession struct {
-	AudioChannels        int64   `json:"audioChannels"`
-	AudioCodec           string  `json:"audioCodec"`
-	AudioDecision        string  `json:"audioDecision"`
-	Complete             bool    `json:"complete"`
-	Container            string  `json:"container"`
-	Context              string  `json:"context"`
-	Duration             int64   `json:"duration"`
-	Key                  string  `json:"key"`
-	Progress             float64 `json:"progress"`
-	Protocol             string  `json:"protocol"`
-	Remaining            int64   `json:"remaining"`
-	SourceAudioCodec     string  `json:"sourceAudioCodec"`
-	SourceVideoCodec     string  `json:"sourceVideoCodec"`
-	SubtitleDecision     string  `json:"subtitleDecision"`
-	Speed                float64 `json:"speed"`
-	Throttled            bool    `json:"throttled"`
-	TranscodeHwRequested bool    `json:"transcodeHwRequested"`
-	VideoCodec           string  `json:"videoCodec"`
-	VideoDecision        string  `json:"videoDecision"`
-}
-
-// Setting ...
+	AudioChannels            int64   `json:"audioChannels"`
+	AudioCodec               string  `json:"audioCodec"`
+	AudioDecision            string  `json:"audioDecision"`
+	Complete                 bool    `json:"complete"`
+	Container                string  `json:"container"`
+	Context                  string  `json:"context"`
+	Duration                 int64   `json:"duration"`
+	Height                   int64   `json:"height"`
+	Key                      string  `json:"key"`
+	MaxOffsetAvailable       float64 `json:"maxOffsetAvailable"`
+	MinOffsetAvailable       float64 `json:"minOffsetAvailable"`
+	Progress                 float64 `json:"progress"`
+	Protocol                 string  `json:"protocol"`
+	Remaining                int64   `json:"remaining"`
+	SourceAudioCodec         string  `json:"sourceAudioCodec"`
+	SourceVideoCodec         string  `json:"sourceVideoCodec"`
+	SubtitleDecision         string  `json:"subtitleDecision"`
+	Speed                    float64 `json:"speed"`
+	Throttled                bool    `json:"throttled"`
+	TimeStamp                float64 `json:"timeStamp"`
+	TranscodeHwRequested     bool    `json:"transcodeHwRequested"`
+	TranscodeHwDecoding      string  `json:"transcodeHwDecoding"`
+	TranscodeHwDecodingTitle string  `json:"transcodeHwDecodingTitle"`
+	TranscodeHwEncoding      string  `json:"transcodeHwEncoding"`
+	TranscodeHwEncodingTitle string  `json:"transcodeHwEncodingTitle"`
+	TranscodeHwFullPipeline  bool    `json:"transcodeHwFullPipeline"`
+	VideoCodec               string  `json:"videoCodec"`
+	VideoDecision            string  `json:"videoDecision"`
+	Width                    int64   `json:"width"`
+}
+
+// Setting is a server preference, sent with preference notifications when it changes
 type Setting struct {
-	Advanced bool   `json:"advanced"`
-	Default  string `json:"default"`
-	Group    string `json:"group"`
-	Hidden   bool   `json:"hidden"`
-	ID       string `json:"id"`
-	Label    string `json:"label"`
-	Summary  string `json:"summary"`
-	Type     string `json:"type"`
-	Value    int64  `json:"value"`
+	Advanced   bool         `json:"advanced"`
+	Default    SettingValue `json:"default"`
+	EnumValues string       `json:"enumValues"`
+	Group      string       `json:"group"`
+	Hidden     bool         `json:"hidden"`
+	ID         string       `json:"id"`
+	Label      string       `json:"label"`
+	Summary    string       `json:"summary"`
+	Type       string       `json:"type"`
+	Value      SettingValue `json:"value"`
+}
+
+// SettingValue is the value of a server setting. Depending on the setting type plex sends
+// a boolean, a number or a string, so the value is kept in its text form.
+type SettingValue string
+
+func (v *SettingValue) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*v = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*v = SettingValue(s)
+		return nil
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	switch raw.(type) {
+	case bool, float64:
+		*v = SettingValue(strings.TrimSpace(string(b)))
+		return nil
+	default:
+		return fmt.Errorf("invalid setting value: %s", string(b))
+	}
 }
 
+// String returns the value as sent by the server
+func (v SettingValue) String() string { return string(v) }
+
+// Bool returns the value of a "bool" setting
+func (v SettingValue) Bool() bool { return v == "true" || v == "1" }
+
+// Int64 returns the value of an "int" setting
+func (v SettingValue) Int64() (int64, error) { return strconv.ParseInt(string(v), 10, 64) }
+
 // NotificationContainer read pms notifications
 type NotificationContainer struct {
 	TimelineEntry []TimelineEntry `json:"TimelineEntry"`
@@ -228,6 +357,10 @@ type NotificationContainer struct {
 
 	StatusNotification []StatusNotification `json:"StatusNotification"`
 
+	ProgressNotification []ProgressNotification `json:"ProgressNotification"`
+
+	ProviderContentChangeNotification []ProviderContentChangeNotification `json:"ProviderContentChangeNotification"`
+
 	PlaySessionStateNotification []PlaySessionStateNotification `json:"PlaySessionStateNotification"`
 
 	ReachabilityNotification []ReachabilityNotification `json:"ReachabilityNotification"`
@@ -241,6 +374,10 @@ type NotificationContainer struct {
 	Size int64 `json:"size"`
 	// Type can be one of:
 	// playing,
+	// timeline,
+	// status,
+	// progress,
+	// provider.content.change,
 	// reachability,
 	// transcode.end,
 	// preference,
@@ -260,6 +397,19 @@ type WebsocketNotification struct {
 // NotificationEvents hold callbacks that correspond to notifications
 type NotificationEvents struct {
 	events map[string]func(n NotificationContainer)
+	// onRaw receives every message as sent by the server
+	onRaw func(message []byte)
+	// onUnknown receives messages whose type has no registered callback
+	onUnknown func(eventType string, message []byte)
+	// filters limits the notification types the server sends, empty means all
+	filters []string
+
+	onConnect    func()
+	onDisconnect func(err error)
+	onError      func(err error)
+
+	// sessions dispatches the OnPlayingSession callback, tied to the latest subscription
+	sessions *playingSessions
 }
 
 // NewNotificationEvents initializes the event callbacks
@@ -277,6 +427,8 @@ func NewNotificationEvents() *NotificationEvents {
 			"update.statechange":        func(n NotificationContainer) {},
 			"activity":                  func(n NotificationContainer) {},
 			"backgroundProcessingQueue": func(n NotificationContainer) {},
+			"status":                    func(n NotificationContainer) {},
+			"progress":                  func(n NotificationContainer) {},
 		},
 	}
 }
@@ -286,6 +438,166 @@ func (e *NotificationEvents) OnPlaying(fn func(n NotificationContainer)) {
 	e.events["playing"] = fn
 }
 
+// PlayingEvent is a playing notification joined with the session it belongs to
+type PlayingEvent struct {
+	PlaySessionStateNotification
+	// Session is the item being played with its player and user, nil once the session ended
+	Session *Metadata
+	// TranscodeSession is nil when the item is direct played
+	TranscodeSession *TranscodeSession
+}
+
+// playingSessionsTTL is how long OnPlayingSession reuses the sessions it fetched, as every
+// active session sends a playing notification every few seconds. It is a variable so tests
+// can change it.
+var playingSessionsTTL = 2 * time.Second
+
+// maxPendingPlaying is how many playing notifications OnPlayingSession queues while
+// fetching the sessions, the oldest are dropped beyond that
+const maxPendingPlaying = 256
+
+// OnPlayingSession is like OnPlaying but fetches the current sessions from p, so fn gets
+// the player, user and transcode details along with the state. The sessions are fetched
+// off the websocket reader and reused for playingSessionsTTL, fn is called from another
+// goroutine, in the order of the notifications. A callback registered with OnPlaying
+// before still gets every notification, one registered after replaces both.
+// Failing to fetch the sessions is reported to OnError, fn still gets the notification.
+// Closing the subscription waits for fn to return, notifications not dispatched by then
+// are dropped, as are the oldest ones when more than maxPendingPlaying are waiting.
+func (e *NotificationEvents) OnPlayingSession(p *Plex, fn func(event PlayingEvent)) {
+	previous := e.events["playing"]
+	fetcher := &playingSessions{p: p, events: e, fn: fn}
+	e.sessions = fetcher
+
+	e.events["playing"] = func(n NotificationContainer) {
+		if previous != nil {
+			previous(n)
+		}
+
+		if len(n.PlaySessionStateNotification) > 0 {
+			fetcher.add(n.PlaySessionStateNotification)
+		}
+	}
+}
+
+// playingSessions joins playing notifications with the sessions of the server
+type playingSessions struct {
+	p      *Plex
+	events *NotificationEvents
+	fn     func(event PlayingEvent)
+
+	mu        sync.Mutex
+	ctx       context.Context
+	wg        *sync.WaitGroup
+	pending   []PlaySessionStateNotification
+	running   bool
+	sessions  CurrentSessions
+	fetchedAt time.Time
+}
+
+// attach ties the dispatching goroutine to a subscription, it stops when ctx is done
+// and is tracked by wg
+func (s *playingSessions) attach(ctx context.Context, wg *sync.WaitGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx, s.wg = ctx, wg
+}
+
+// add queues notifications, starting a goroutine to dispatch them when none is running
+func (s *playingSessions) add(notifications []PlaySessionStateNotification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.pending = append(s.pending, notifications...)
+
+	if dropped := len(s.pending) - maxPendingPlaying; dropped > 0 {
+		s.pending = slices.Delete(s.pending, 0, dropped)
+	}
+
+	if !s.running {
+		s.running = true
+
+		if s.wg != nil {
+			s.wg.Add(1)
+		}
+
+		go s.run(ctx, s.wg)
+	}
+}
+
+// run dispatches the queued notifications until the queue is empty or ctx is done
+func (s *playingSessions) run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	for {
+		s.mu.Lock()
+		batch := s.pending
+		s.pending = nil
+
+		if len(batch) == 0 || ctx.Err() != nil {
+			s.running = false
+			s.mu.Unlock()
+
+			return
+		}
+
+		s.mu.Unlock()
+
+		sessions := s.current()
+
+		for _, notification := range batch {
+			if ctx.Err() != nil {
+				break
+			}
+
+			event := PlayingEvent{PlaySessionStateNotification: notification}
+
+			for _, session := range sessions.MediaContainer.Metadata {
+				if session.SessionKey == notification.SessionKey {
+					event.Session = &session
+					event.TranscodeSession = session.TranscodeSession
+					break
+				}
+			}
+
+			s.fn(event)
+		}
+	}
+}
+
+// current returns the sessions fetched within playingSessionsTTL, fetching them otherwise
+func (s *playingSessions) current() CurrentSessions {
+	if !s.fetchedAt.IsZero() && time.Since(s.fetchedAt) < playingSessionsTTL {
+		return s.sessions
+	}
+
+	sessions, err := s.p.GetSessions()
+
+	if err != nil {
+		if s.events.onError != nil {
+			s.events.onError(err)
+		}
+
+		return sessions
+	}
+
+	s.sessions, s.fetchedAt = sessions, time.Now()
+
+	return sessions
+}
+
 // OnTimeline registers a callback for timeline events emitted by the server.
 func (e *NotificationEvents) OnTimeline(fn func(n NotificationContainer)) {
 	e.events["timeline"] = fn
@@ -301,6 +613,98 @@ func (e *NotificationEvents) OnProviderContentChange(fn func(n NotificationConta
 	e.events["provider.content.change"] = fn
 }
 
+// OnStatus registers a callback for status events, e.g. a library scan starting or finishing
+func (e *NotificationEvents) OnStatus(fn func(n NotificationContainer)) {
+	e.events["status"] = fn
+}
+
+// OnProgress registers a callback for progress events of long running server tasks
+func (e *NotificationEvents) OnProgress(fn func(n NotificationContainer)) {
+	e.events["progress"] = fn
+}
+
+// OnPreference registers a callback for server setting changes. The changed settings are in n.Setting
+func (e *NotificationEvents) OnPreference(fn func(n NotificationContainer)) {
+	e.events["preference"] = fn
+}
+
+// Filter asks the server to only send the given notification types (e.g. "playing", "timeline")
+// which reduces traffic on busy servers. Notifications of other types are also dropped client side.
+// Calling Filter without arguments subscribes to all types again.
+func (e *NotificationEvents) Filter(eventTypes ...string) {
+	e.filters = eventTypes
+}
+
+// wants reports whether the notification type passes the filter
+func (e *NotificationEvents) wants(eventType string) bool {
+	if len(e.filters) == 0 {
+		return true
+	}
+
+	for _, f := range e.filters {
+		if f == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OnConnect registers a callback that runs once the websocket connection is established
+func (e *NotificationEvents) OnConnect(fn func()) {
+	e.onConnect = fn
+}
+
+// OnDisconnect registers a callback that runs when the websocket connection ends.
+// err is nil when the subscription was cancelled by the caller.
+func (e *NotificationEvents) OnDisconnect(fn func(err error)) {
+	e.onDisconnect = fn
+}
+
+// OnError registers a callback for connection errors. It receives the same errors as the
+// error function passed to SubscribeToNotifications.
+func (e *NotificationEvents) OnError(fn func(err error)) {
+	e.onError = fn
+}
+
+// OnRaw registers a callback that receives the raw json of every notification, before it is decoded
+func (e *NotificationEvents) OnRaw(fn func(message []byte)) {
+	e.onRaw = fn
+}
+
+// OnUnknownEvent registers a callback for notification types this package does not model yet.
+// It receives the notification type and the raw json so it can be decoded by the caller.
+func (e *NotificationEvents) OnUnknownEvent(fn func(eventType string, message []byte)) {
+	e.onUnknown = fn
+}
+
+// websocketDialer returns the dialer for notification subscriptions. Without an explicit
+// WebsocketDialer the TLS and proxy settings of the HTTP client are carried over, so wss
+// connections trust the same certificates as https requests.
+func (p *Plex) websocketDialer() *websocket.Dialer {
+	if p.WebsocketDialer != nil {
+		return p.WebsocketDialer
+	}
+
+	t := innerTransport(p.HTTPClient.Transport)
+
+	if t == nil || (t.TLSClientConfig == nil && t.Proxy == nil) {
+		return websocket.DefaultDialer
+	}
+
+	d := *websocket.DefaultDialer
+
+	if t.TLSClientConfig != nil {
+		d.TLSClientConfig = t.TLSClientConfig.Clone()
+	}
+
+	if t.Proxy != nil {
+		d.Proxy = t.Proxy
+	}
+
+	return &d
+}
+
 // SubscribeToNotifications connects to your server via websockets listening for events
 func (p *Plex) SubscribeToNotifications(events *NotificationEvents, interrupt <-chan os.Signal, fn func(error)) {
 	// If the caller provided an interrupt channel, create a cancellable context
@@ -322,11 +726,106 @@ func (p *Plex) SubscribeToNotifications(events *NotificationEvents, interrupt <-
 // SubscribeToNotificationsWithContext is a context-aware version that ensures
 // both reader and writer goroutines stop when ctx is cancelled.
 func (p *Plex) SubscribeToNotificationsWithContext(ctx context.Context, events *NotificationEvents, fn func(error)) {
-	plexURL, err := url.Parse(p.URL)
+	report := notificationReporter(events, fn)
+
+	if _, err := p.subscribe(ctx, events, report); err != nil {
+		report(err)
+	}
+}
+
+// Subscription is a websocket connection opened by Subscribe
+type Subscription struct {
+	conn   *websocket.Conn
+	cancel context.CancelFunc
+	reader chan struct{}
+	writer chan struct{}
+	// background tracks the goroutines dispatching OnPlayingSession
+	background sync.WaitGroup
+}
+
+// Subscribe connects to your server via websockets like SubscribeToNotificationsWithContext,
+// returning the connection errors instead of reporting them. The subscription ends when
+// ctx is cancelled or when it is closed.
+func (p *Plex) Subscribe(ctx context.Context, events *NotificationEvents, fn func(error)) (*Subscription, error) {
+	return p.subscribe(ctx, events, notificationReporter(events, fn))
+}
+
+// Close ends the subscription and waits for the server to acknowledge it, see Shutdown
+func (s *Subscription) Close() error {
+	return s.Shutdown(context.Background())
+}
+
+// Shutdown ends the subscription and waits for its goroutines, including a running
+// OnPlayingSession callback. When ctx is done first, the connection is dropped without
+// waiting for the server and ctx.Err() is returned.
+func (s *Subscription) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	for _, done := range []chan struct{}{s.reader, s.writer} {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			safeClose(s.conn)
+			<-s.reader
+
+			return ctx.Err()
+		}
+	}
+
+	// the reader has returned, so no callback is started after this
+	background := make(chan struct{})
+
+	go func() {
+		s.background.Wait()
+		close(background)
+	}()
+
+	select {
+	case <-background:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// notificationReporter reports errors to events and fn
+func notificationReporter(events *NotificationEvents, fn func(error)) func(error) {
+	return func(err error) {
+		if events.onError != nil {
+			events.onError(err)
+		}
+
+		if fn != nil {
+			fn(err)
+		}
+	}
+}
+
+// notificationFilters returns the notification types to ask the server for. A client with
+// a cache also needs the timeline and activity notifications that invalidate it, they are
+// not dispatched when events filters them out.
+func (p *Plex) notificationFilters(events *NotificationEvents) []string {
+	if len(events.filters) == 0 || p.cache == nil {
+		return events.filters
+	}
+
+	filters := slices.Clone(events.filters)
+
+	for _, eventType := range []string{"timeline", "activity"} {
+		if !slices.Contains(filters, eventType) {
+			filters = append(filters, eventType)
+		}
+	}
+
+	return filters
+}
+
+// subscribe connects to the server and starts the reader and writer goroutines
+func (p *Plex) subscribe(ctx context.Context, events *NotificationEvents, report func(error)) (*Subscription, error) {
+	plexURL, err := url.Parse(p.ServerURL())
 
 	if err != nil {
-		fn(err)
-		return
+		return nil, err
 	}
 
 	scheme := "ws"
@@ -336,28 +835,71 @@ func (p *Plex) SubscribeToNotificationsWithContext(ctx context.Context, events *
 
 	websocketURL := url.URL{Scheme: scheme, Host: plexURL.Host, Path: "/:/websockets/notifications"}
 
-	headers := http.Header{
-		"X-Plex-Token": []string{p.Token},
+	if filters := p.notificationFilters(events); len(filters) > 0 {
+		websocketURL.RawQuery = url.Values{"filters": []string{strings.Join(filters, ",")}}.Encode()
 	}
 
-	dialer := websocket.DefaultDialer
-	if p.WebsocketDialer != nil {
-		dialer = p.WebsocketDialer
+	token, err := p.currentToken(ctx)
+
+	if err != nil {
+		return nil, err
 	}
 
-	c, _, err := dialer.Dial(websocketURL.String(), headers)
+	headers := http.Header{
+		"X-Plex-Token": []string{token},
+	}
+
+	c, _, err := p.websocketDialer().Dial(websocketURL.String(), headers)
 
 	if err != nil {
-		fn(err)
-		return
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	sub := &Subscription{conn: c, cancel: cancel, reader: make(chan struct{}), writer: make(chan struct{})}
+	done := sub.reader
+
+	if events.sessions != nil {
+		events.sessions.attach(ctx, &sub.background)
 	}
 
-	done := make(chan struct{})
+	pongWait, pingPeriod, writeWait := websocketPongWait, websocketPingPeriod, websocketWriteWait
+
+	// A stale tcp connection never returns from ReadMessage, so require the server
+	// to answer our pings (or send anything else) within websocketPongWait.
+	extendDeadline := func() error {
+		return c.SetReadDeadline(time.Now().Add(pongWait))
+	}
+
+	if err := extendDeadline(); err != nil {
+		cancel()
+		safeClose(c)
+
+		return nil, err
+	}
+
+	c.SetPongHandler(func(string) error {
+		return extendDeadline()
+	})
+
+	if events.onConnect != nil {
+		events.onConnect()
+	}
 
 	// Reader goroutine
 	go func() {
-		defer safeClose(c)
-		defer close(done)
+		// readErr is why the connection ended, nil when the caller cancelled
+		var readErr error
+
+		defer func() {
+			safeClose(c)
+			close(done)
+
+			if events.onDisconnect != nil {
+				events.onDisconnect(readErr)
+			}
+		}()
 
 		for {
 			select {
@@ -369,11 +911,27 @@ func (p *Plex) SubscribeToNotificationsWithContext(ctx context.Context, events *
 			_, message, err := c.ReadMessage()
 
 			if err != nil {
+				// the server acknowledging our close is not an error
+				if ctx.Err() != nil && websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					return
+				}
+
 				logger.Error("websocket read error", zap.String("error", err.Error()))
-				fn(err)
+				readErr = err
+				report(err)
 				return
 			}
 
+			if err := extendDeadline(); err != nil {
+				readErr = err
+				report(err)
+				return
+			}
+
+			if events.onRaw != nil {
+				events.onRaw(message)
+			}
+
 			var notif WebsocketNotification
 
 			if err := json.Unmarshal(message, &notif); err != nil {
@@ -381,9 +939,24 @@ func (p *Plex) SubscribeToNotificationsWithContext(ctx context.Context, events *
 				continue
 			}
 
+			// the cache must not serve items the notification says have changed, even
+			// when the caller is not interested in this type of notification
+			if p.cache != nil {
+				p.cache.invalidateNotification(notif.NotificationContainer)
+			}
+
+			if !events.wants(notif.Type) {
+				continue
+			}
+
 			cb, ok := events.events[notif.Type]
 
 			if !ok {
+				if events.onUnknown != nil {
+					events.onUnknown(notif.Type, message)
+					continue
+				}
+
 				logger.Warn("unknown websocket event name", zap.String("event", notif.Type))
 				continue
 			}
@@ -394,23 +967,28 @@ func (p *Plex) SubscribeToNotificationsWithContext(ctx context.Context, events *
 
 	// Writer goroutine
 	go func() {
-		ticker := time.NewTicker(time.Second)
+		defer close(sub.writer)
+		defer cancel()
+
+		ticker := time.NewTicker(pingPeriod)
 		defer ticker.Stop()
 
 		for {
 			select {
-			case t := <-ticker.C:
-				err := c.WriteMessage(websocket.TextMessage, []byte(t.String()))
-
-				if err != nil {
-					fn(err)
+			case <-ticker.C:
+				// a failed ping is reported by the reader once the read deadline passes
+				if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+					logger.Warn("websocket ping failed", zap.String("error", err.Error()))
+					return
 				}
+			case <-done:
+				return
 			case <-ctx.Done():
 				// attempt graceful close
 				err := c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 				if err != nil {
 					logger.Error("websocket write close failed", zap.String("error", err.Error()))
-					fn(err)
+					report(err)
 				}
 
 				select {
@@ -423,4 +1001,6 @@ func (p *Plex) SubscribeToNotificationsWithContext(ctx context.Context, events *
 			}
 		}
 	}()
+
+	return sub, nil
 }
@@ -1,12 +1,52 @@
 package plex
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/gorilla/websocket"
 )
 
+// newWebsocketTestServer starts a websocket server that hands each connection to serve
+func newWebsocketTestServer(t *testing.T, serve func(conn *websocket.Conn)) (*httptest.Server, *Plex) {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Logf("upgrade error: %v", err)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		serve(conn)
+	}))
+
+	plex := &Plex{URL: server.URL, Token: "test-token", ClientIdentifier: "test-client"}
+
+	return server, plex
+}
+
+// setWebsocketKeepalive shortens the keepalive timings for the duration of a test
+func setWebsocketKeepalive(t *testing.T, pingPeriod, pongWait time.Duration) {
+	t.Helper()
+
+	oldPing, oldPong := websocketPingPeriod, websocketPongWait
+	websocketPingPeriod, websocketPongWait = pingPeriod, pongWait
+
+	t.Cleanup(func() {
+		websocketPingPeriod, websocketPongWait = oldPing, oldPong
+	})
+}
+
 // Test parseFlexibleInt64 function - improve coverage to 80%+
 func TestParseFlexibleInt64_Extended(t *testing.T) {
 	tests := []struct {
@@ -147,7 +187,7 @@ func TestNewNotificationEvents(t *testing.T) {
 	}
 
 	// Test that default events are set up based on actual implementation
-	expectedEvents := []string{"timeline", "provider.content.change", "playing", "reachability", "transcode.end", "transcodeSession.end", "transcodeSession.update", "preference", "update.statechange", "activity", "backgroundProcessingQueue"}
+	expectedEvents := []string{"timeline", "provider.content.change", "playing", "reachability", "transcode.end", "transcodeSession.end", "transcodeSession.update", "preference", "update.statechange", "activity", "backgroundProcessingQueue", "status", "progress"}
 	for _, event := range expectedEvents {
 		if _, exists := events.events[event]; !exists {
 			t.Errorf("Expected event '%s' not found in events map", event)
@@ -177,6 +217,110 @@ func TestNotificationEvents_OnPlaying(t *testing.T) {
 	}
 }
 
+func TestNotificationEvents_OnPlayingSession(t *testing.T) {
+	p := newBrowseTestServer(t, map[string]string{
+		"/status/sessions": `{"MediaContainer":{"size":1,"Metadata":[{"sessionKey":"5","ratingKey":"42","title":"Heat",
+			"Player":{"title":"Living Room"},"TranscodeSession":{"key":"/transcode/sessions/abc","videoDecision":"transcode"}}]}}`,
+	})
+
+	events := NewNotificationEvents()
+
+	var recorded int
+	events.OnPlaying(func(n NotificationContainer) { recorded++ })
+
+	got := make(chan PlayingEvent, 2)
+	events.OnPlayingSession(p, func(event PlayingEvent) {
+		got <- event
+	})
+
+	events.events["playing"](NotificationContainer{
+		PlaySessionStateNotification: []PlaySessionStateNotification{
+			{SessionKey: "5", RatingKey: "42", State: "playing", TranscodeSession: "abc"},
+			{SessionKey: "6", RatingKey: "7", State: "stopped"},
+		},
+	})
+
+	if recorded != 1 {
+		t.Errorf("expected the OnPlaying callback to still be called, got %d calls", recorded)
+	}
+
+	var received []PlayingEvent
+
+	for len(received) < 2 {
+		select {
+		case event := <-got:
+			received = append(received, event)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for events, got %+v", received)
+		}
+	}
+
+	if received[0].Session == nil || received[0].Session.Player.Title != "Living Room" || received[0].TranscodeSession == nil || received[0].TranscodeSession.VideoDecision != "transcode" {
+		t.Errorf("unexpected enriched event %+v", received[0])
+	}
+
+	if received[1].Session != nil || received[1].TranscodeSession != nil || received[1].State != "stopped" {
+		t.Errorf("expected an ended session without details, got %+v", received[1])
+	}
+}
+
+// Test OnPlayingSession does not block the websocket reader and fetches the sessions once for a burst
+func TestNotificationEvents_OnPlayingSessionAsync(t *testing.T) {
+	release := make(chan struct{})
+	var fetches atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+
+		w.Header().Set("Content-Type", applicationJson)
+		_, _ = w.Write([]byte(`{"MediaContainer":{"size":0}}`))
+	}))
+	defer server.Close()
+
+	p, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	got := make(chan PlayingEvent, 3)
+
+	events := NewNotificationEvents()
+	events.OnPlayingSession(p, func(event PlayingEvent) { got <- event })
+
+	returned := make(chan struct{})
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			events.events["playing"](NotificationContainer{
+				PlaySessionStateNotification: []PlaySessionStateNotification{{SessionKey: "5", State: "playing"}},
+			})
+		}
+
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("expected the playing callback to return while the sessions are fetched")
+	}
+
+	close(release)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-got:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+
+	if fetches.Load() != 1 {
+		t.Errorf("expected the sessions to be fetched once, got %d fetches", fetches.Load())
+	}
+}
+
 func TestNotificationEvents_OnTimeline(t *testing.T) {
 	events := NewNotificationEvents()
 
@@ -570,3 +714,399 @@ func TestEnvVarEnablesWebsocketDialerSkipTLS(t *testing.T) {
 		t.Fatalf("expected WebsocketDialer.TLSClientConfig.InsecureSkipVerify to be true due to env var")
 	}
 }
+
+// Test pings keep a healthy connection open
+func TestSubscribeToNotifications_PingKeepsConnectionAlive(t *testing.T) {
+	setWebsocketKeepalive(t, 50*time.Millisecond, 200*time.Millisecond)
+
+	pings := make(chan struct{}, 100)
+
+	srv, p := newWebsocketTestServer(t, func(conn *websocket.Conn) {
+		conn.SetPingHandler(func(data string) error {
+			pings <- struct{}{}
+			return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer srv.Close()
+
+	errs := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p.SubscribeToNotificationsWithContext(ctx, NewNotificationEvents(), func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	select {
+	case err := <-errs:
+		t.Fatalf("unexpected error on healthy connection: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if len(pings) < 2 {
+		t.Errorf("expected server to receive pings, got %d", len(pings))
+	}
+}
+
+// Test a server that stops answering pings is detected as dead
+func TestSubscribeToNotifications_DetectsDeadConnection(t *testing.T) {
+	setWebsocketKeepalive(t, 50*time.Millisecond, 200*time.Millisecond)
+
+	release := make(chan struct{})
+
+	// never reads, so pings are never answered
+	srv, p := newWebsocketTestServer(t, func(conn *websocket.Conn) {
+		<-release
+	})
+	defer srv.Close()
+	defer close(release)
+
+	errs := make(chan error, 1)
+
+	p.SubscribeToNotificationsWithContext(context.Background(), NewNotificationEvents(), func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("expected a timeout error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("dead connection was not detected")
+	}
+}
+
+// Test OnRaw receives every message and OnUnknownEvent receives unmodelled types
+func TestNotificationEvents_OnRawAndOnUnknownEvent(t *testing.T) {
+	messages := []string{
+		`{"NotificationContainer":{"type":"playing","size":1}}`,
+		`{"NotificationContainer":{"type":"account.changed","size":1,"AccountUpdateNotification":[{"id":1}]}}`,
+	}
+
+	srv, p := newWebsocketTestServer(t, func(conn *websocket.Conn) {
+		for _, m := range messages {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(m))
+		}
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer srv.Close()
+
+	raw := make(chan string, len(messages))
+	unknown := make(chan string, 1)
+	playing := make(chan struct{}, 1)
+
+	events := NewNotificationEvents()
+	events.OnRaw(func(message []byte) {
+		raw <- string(message)
+	})
+	events.OnUnknownEvent(func(eventType string, message []byte) {
+		unknown <- eventType + " " + string(message)
+	})
+	events.OnPlaying(func(n NotificationContainer) {
+		playing <- struct{}{}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p.SubscribeToNotificationsWithContext(ctx, events, func(err error) {})
+
+	for i, want := range messages {
+		select {
+		case got := <-raw:
+			if got != want {
+				t.Errorf("raw message %d = %s, want %s", i, got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for raw message %d", i)
+		}
+	}
+
+	select {
+	case got := <-unknown:
+		if got != "account.changed "+messages[1] {
+			t.Errorf("unexpected unknown event: %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for unknown event")
+	}
+
+	select {
+	case <-playing:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for playing event")
+	}
+}
+
+// Test typed status, progress, provider and preference notifications are dispatched
+func TestNotificationEvents_AdditionalEventTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(e *NotificationEvents, fn func(n NotificationContainer))
+		message  string
+		check    func(n NotificationContainer) bool
+	}{
+		{
+			name:     "status",
+			register: (*NotificationEvents).OnStatus,
+			message:  `{"NotificationContainer":{"type":"status","size":1,"StatusNotification":[{"title":"Library scan complete","notificationName":"LIBRARY_UPDATE"}]}}`,
+			check: func(n NotificationContainer) bool {
+				return len(n.StatusNotification) == 1 && n.StatusNotification[0].NotificationName == "LIBRARY_UPDATE"
+			},
+		},
+		{
+			name:     "progress",
+			register: (*NotificationEvents).OnProgress,
+			message:  `{"NotificationContainer":{"type":"progress","size":1,"ProgressNotification":[{"message":"Scanning Movies"}]}}`,
+			check: func(n NotificationContainer) bool {
+				return len(n.ProgressNotification) == 1 && n.ProgressNotification[0].Message == "Scanning Movies"
+			},
+		},
+		{
+			name:     "provider content change",
+			register: (*NotificationEvents).OnProviderContentChange,
+			message:  `{"NotificationContainer":{"type":"provider.content.change","size":1,"ProviderContentChangeNotification":[{"identifier":"tv.plex.provider.epg"}]}}`,
+			check: func(n NotificationContainer) bool {
+				return len(n.ProviderContentChangeNotification) == 1 && n.ProviderContentChangeNotification[0].Identifier == "tv.plex.provider.epg"
+			},
+		},
+		{
+			name:     "preference",
+			register: (*NotificationEvents).OnPreference,
+			message:  `{"NotificationContainer":{"type":"preference","size":1,"Setting":[{"id":"FriendlyName","type":"text"}]}}`,
+			check: func(n NotificationContainer) bool {
+				return len(n.Setting) == 1 && n.Setting[0].ID == "FriendlyName"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notif WebsocketNotification
+			if err := json.Unmarshal([]byte(tt.message), &notif); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+
+			events := NewNotificationEvents()
+
+			var received *NotificationContainer
+			tt.register(events, func(n NotificationContainer) {
+				received = &n
+			})
+
+			events.events[notif.Type](notif.NotificationContainer)
+
+			if received == nil {
+				t.Fatal("callback was not called")
+			}
+
+			if !tt.check(*received) {
+				t.Errorf("unexpected notification: %+v", *received)
+			}
+		})
+	}
+}
+
+// Test Filter is sent to the server and applied to received notifications
+func TestNotificationEvents_Filter(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	gotFilters := make(chan string, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotFilters <- r.URL.Query().Get("filters")
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		// a server ignoring the filter still sends everything
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"NotificationContainer":{"type":"activity","size":1}}`))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"NotificationContainer":{"type":"playing","size":1}}`))
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	p := &Plex{URL: srv.URL, Token: "test-token"}
+
+	received := make(chan string, 2)
+
+	events := NewNotificationEvents()
+	events.Filter("playing", "timeline")
+	events.events["activity"] = func(n NotificationContainer) { received <- n.Type }
+	events.OnPlaying(func(n NotificationContainer) { received <- n.Type })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p.SubscribeToNotificationsWithContext(ctx, events, func(err error) {})
+
+	if got := <-gotFilters; got != "playing,timeline" {
+		t.Errorf("filters query = %q, want %q", got, "playing,timeline")
+	}
+
+	select {
+	case got := <-received:
+		if got != "playing" {
+			t.Errorf("expected only playing to be dispatched, got %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for playing event")
+	}
+
+	// a client with a cache still receives the notifications that invalidate it
+	cached, err := New(srv.URL, "test-token", WithCache(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error from New: %v", err)
+	}
+
+	cached.SubscribeToNotificationsWithContext(ctx, events, func(err error) {})
+
+	if got := <-gotFilters; got != "playing,timeline,activity" {
+		t.Errorf("filters query = %q, want %q", got, "playing,timeline,activity")
+	}
+
+	select {
+	case got := <-received:
+		if got != "playing" {
+			t.Errorf("expected only playing to be dispatched, got %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for playing event")
+	}
+}
+
+// Test OnConnect, OnDisconnect and OnError lifecycle callbacks
+func TestNotificationEvents_LifecycleCallbacks(t *testing.T) {
+	closeConn := make(chan struct{})
+
+	srv, p := newWebsocketTestServer(t, func(conn *websocket.Conn) {
+		<-closeConn
+		// drop the connection without a close handshake
+	})
+	defer srv.Close()
+
+	connected := make(chan struct{}, 1)
+	disconnected := make(chan error, 1)
+	onError := make(chan error, 1)
+	fnError := make(chan error, 1)
+
+	events := NewNotificationEvents()
+	events.OnConnect(func() { connected <- struct{}{} })
+	events.OnDisconnect(func(err error) { disconnected <- err })
+	events.OnError(func(err error) { onError <- err })
+
+	p.SubscribeToNotificationsWithContext(context.Background(), events, func(err error) { fnError <- err })
+
+	select {
+	case <-connected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for OnConnect")
+	}
+
+	close(closeConn)
+
+	for name, ch := range map[string]chan error{"OnDisconnect": disconnected, "OnError": onError, "error func": fnError} {
+		select {
+		case err := <-ch:
+			if err == nil {
+				t.Errorf("%s: expected an error for a dropped connection", name)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", name)
+		}
+	}
+}
+
+// Test cancelling the subscription disconnects without an error
+func TestNotificationEvents_OnDisconnectAfterCancel(t *testing.T) {
+	srv, p := newWebsocketTestServer(t, func(conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer srv.Close()
+
+	connected := make(chan struct{}, 1)
+	disconnected := make(chan error, 1)
+
+	events := NewNotificationEvents()
+	events.OnConnect(func() { connected <- struct{}{} })
+	events.OnDisconnect(func(err error) { disconnected <- err })
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p.SubscribeToNotificationsWithContext(ctx, events, nil)
+
+	<-connected
+	cancel()
+
+	select {
+	case err := <-disconnected:
+		if err != nil {
+			t.Errorf("expected no error after cancel, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for OnDisconnect")
+	}
+}
+
+// Test complete timeline, setting and reachability payloads decode with flexible values
+func TestNotificationContainer_FullPayloads(t *testing.T) {
+	message := `{"NotificationContainer":{"type":"timeline","size":4,
+		"TimelineEntry":[{"identifier":"com.plexapp.plugins.library","sectionID":"2","itemID":"1234","parentItemID":12,"rootItemID":"11","type":4,"title":"Pilot","state":"5","metadataState":"created","mediaState":"analyzing","updatedAt":1700000000,"queueSize":"3"}],
+		"Setting":[{"id":"FriendlyName","type":"text","default":"","value":"My Server"},{"id":"LogVerbose","type":"bool","default":false,"value":true},{"id":"TranscoderQuality","type":"int","default":0,"value":2,"enumValues":"0:Automatic|1:Prefer higher speed|2:Prefer higher quality"}],
+		"ReachabilityNotification":[{"reachability":1}]}}`
+
+	var notif WebsocketNotification
+	if err := json.Unmarshal([]byte(message), &notif); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	entry := notif.TimelineEntry[0]
+	if entry.ItemID != 1234 || entry.ParentItemID != 12 || entry.RootItemID != 11 || entry.SectionID != 2 ||
+		entry.State != 5 || entry.Type != 4 || entry.QueueSize != 3 || entry.MediaState != "analyzing" {
+		t.Errorf("unexpected timeline entry: %+v", entry)
+	}
+
+	settings := notif.Setting
+	if settings[0].Value.String() != "My Server" {
+		t.Errorf("text setting = %q, want %q", settings[0].Value, "My Server")
+	}
+	if !settings[1].Value.Bool() || settings[1].Default.Bool() {
+		t.Errorf("bool setting = %q (default %q)", settings[1].Value, settings[1].Default)
+	}
+	if v, err := settings[2].Value.Int64(); err != nil || v != 2 {
+		t.Errorf("int setting = %d (%v), want 2", v, err)
+	}
+
+	if !notif.ReachabilityNotification[0].Reachability {
+		t.Error("expected reachability to be true")
+	}
+}